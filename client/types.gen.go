@@ -0,0 +1,1248 @@
+// Package client provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version (devel) DO NOT EDIT.
+package client
+
+import (
+	"time"
+
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+)
+
+const (
+	AccessQueryScopes = "accessQuery.Scopes"
+	AccessTokenScopes = "accessToken.Scopes"
+	BotTokenScopes    = "botToken.Scopes"
+	UniqueIdScopes    = "uniqueId.Scopes"
+)
+
+// Defines values for AccountDeletionStatus.
+const (
+	AccountDeletionStatusCancelled AccountDeletionStatus = "cancelled"
+	AccountDeletionStatusDone      AccountDeletionStatus = "done"
+	AccountDeletionStatusPending   AccountDeletionStatus = "pending"
+	AccountDeletionStatusRunning   AccountDeletionStatus = "running"
+)
+
+// Defines values for ChatExportFormat.
+const (
+	ChatExportFormatHtml ChatExportFormat = "html"
+	ChatExportFormatJson ChatExportFormat = "json"
+	ChatExportFormatText ChatExportFormat = "text"
+)
+
+// Defines values for ChatExportStatus.
+const (
+	ChatExportStatusExpired ChatExportStatus = "expired"
+	ChatExportStatusFailed  ChatExportStatus = "failed"
+	ChatExportStatusPending ChatExportStatus = "pending"
+	ChatExportStatusReady   ChatExportStatus = "ready"
+)
+
+// Defines values for ChatExportRequestFormat.
+const (
+	ChatExportRequestFormatHtml ChatExportRequestFormat = "html"
+	ChatExportRequestFormatJson ChatExportRequestFormat = "json"
+	ChatExportRequestFormatText ChatExportRequestFormat = "text"
+)
+
+// Defines values for ChatImportRequestSource.
+const (
+	Telegram ChatImportRequestSource = "telegram"
+	Whatsapp ChatImportRequestSource = "whatsapp"
+)
+
+// Defines values for ChatMessageType.
+const (
+	ChatMessageTypePoll  ChatMessageType = "poll"
+	ChatMessageTypeText  ChatMessageType = "text"
+	ChatMessageTypeVoice ChatMessageType = "voice"
+)
+
+// Defines values for DeletedMessageDeletedFor.
+const (
+	Everyone DeletedMessageDeletedFor = "everyone"
+	Me       DeletedMessageDeletedFor = "me"
+)
+
+// Defines values for MemberRole.
+const (
+	MemberRoleAdmin     MemberRole = "admin"
+	MemberRoleBot       MemberRole = "bot"
+	MemberRoleMember    MemberRole = "member"
+	MemberRoleModerator MemberRole = "moderator"
+	MemberRoleSupport   MemberRole = "support"
+)
+
+// Defines values for MemberRoleRequestRole.
+const (
+	MemberRoleRequestRoleAdmin     MemberRoleRequestRole = "admin"
+	MemberRoleRequestRoleMember    MemberRoleRequestRole = "member"
+	MemberRoleRequestRoleModerator MemberRoleRequestRole = "moderator"
+	MemberRoleRequestRoleSupport   MemberRoleRequestRole = "support"
+)
+
+// Defines values for ModerationActionAction.
+const (
+	Delete  ModerationActionAction = "delete"
+	Dismiss ModerationActionAction = "dismiss"
+	Suspend ModerationActionAction = "suspend"
+)
+
+// Defines values for ModerationReviewStatus.
+const (
+	ModerationReviewStatusDismissed ModerationReviewStatus = "dismissed"
+	ModerationReviewStatusPending   ModerationReviewStatus = "pending"
+	ModerationReviewStatusResolved  ModerationReviewStatus = "resolved"
+)
+
+// Defines values for Permission.
+const (
+	AuditLogRead    Permission = "audit_log.read"
+	ChatImport      Permission = "chat.import"
+	Integrations    Permission = "integrations"
+	MembersActive   Permission = "members.active"
+	MembersPassword Permission = "members.password"
+	MembersRead     Permission = "members.read"
+	MembersRoles    Permission = "members.roles"
+	MembersSessions Permission = "members.sessions"
+	MetricsRead     Permission = "metrics.read"
+	Moderate        Permission = "moderate"
+)
+
+// Defines values for ReportKind.
+const (
+	ReportKindMessage ReportKind = "message"
+	ReportKindProfile ReportKind = "profile"
+)
+
+// Defines values for ReportStatus.
+const (
+	ReportStatusDismissed ReportStatus = "dismissed"
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusTriaged   ReportStatus = "triaged"
+)
+
+// Defines values for ReportRequestKind.
+const (
+	ReportRequestKindMessage ReportRequestKind = "message"
+	ReportRequestKindProfile ReportRequestKind = "profile"
+)
+
+// Defines values for VerificationCodeRequestCodeType.
+const (
+	N1 VerificationCodeRequestCodeType = 1
+	N2 VerificationCodeRequestCodeType = 2
+	N3 VerificationCodeRequestCodeType = 3
+)
+
+// Defines values for WebhookDeliveryStatus.
+const (
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+)
+
+// Defines values for WebhookRequestEvents.
+const (
+	MemberDeleted  WebhookRequestEvents = "member.deleted"
+	MemberSignedUp WebhookRequestEvents = "member.signed_up"
+	MessageCreated WebhookRequestEvents = "message.created"
+	MessageRead    WebhookRequestEvents = "message.read"
+	ProfileUpdated WebhookRequestEvents = "profile.updated"
+)
+
+// Defines values for SearchMembersParamsRole.
+const (
+	SearchMembersParamsRoleAdmin     SearchMembersParamsRole = "admin"
+	SearchMembersParamsRoleBot       SearchMembersParamsRole = "bot"
+	SearchMembersParamsRoleMember    SearchMembersParamsRole = "member"
+	SearchMembersParamsRoleModerator SearchMembersParamsRole = "moderator"
+	SearchMembersParamsRoleSupport   SearchMembersParamsRole = "support"
+)
+
+// Defines values for GetReportsParamsStatus.
+const (
+	Dismissed GetReportsParamsStatus = "dismissed"
+	Open      GetReportsParamsStatus = "open"
+	Resolved  GetReportsParamsStatus = "resolved"
+	Triaged   GetReportsParamsStatus = "triaged"
+)
+
+// Defines values for GetReviewsParamsStatus.
+const (
+	GetReviewsParamsStatusDismissed GetReviewsParamsStatus = "dismissed"
+	GetReviewsParamsStatusPending   GetReviewsParamsStatus = "pending"
+	GetReviewsParamsStatusResolved  GetReviewsParamsStatus = "resolved"
+)
+
+// Defines values for GetDeliveriesParamsStatus.
+const (
+	GetDeliveriesParamsStatusDead      GetDeliveriesParamsStatus = "dead"
+	GetDeliveriesParamsStatusDelivered GetDeliveriesParamsStatus = "delivered"
+	GetDeliveriesParamsStatusPending   GetDeliveriesParamsStatus = "pending"
+)
+
+// Defines values for GetWebhookDeliveriesParamsStatus.
+const (
+	GetWebhookDeliveriesParamsStatusDead      GetWebhookDeliveriesParamsStatus = "dead"
+	GetWebhookDeliveriesParamsStatusDelivered GetWebhookDeliveriesParamsStatus = "delivered"
+	GetWebhookDeliveriesParamsStatusPending   GetWebhookDeliveriesParamsStatus = "pending"
+)
+
+// Defines values for GetProfileByNickNameParamsSource.
+const (
+	QrCode GetProfileByNickNameParamsSource = "qr_code"
+	Search GetProfileByNickNameParamsSource = "search"
+)
+
+// AccountDeletion defines model for AccountDeletion.
+type AccountDeletion struct {
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	DeleteAt    *time.Time             `json:"delete_at,omitempty"`
+	Id          *string                `json:"id,omitempty"`
+	Member      *string                `json:"member,omitempty"`
+	Profile     *string                `json:"profile,omitempty"`
+	RequestedAt *time.Time             `json:"requested_at,omitempty"`
+	Status      *AccountDeletionStatus `json:"status,omitempty"`
+}
+
+// AccountDeletionStatus defines model for AccountDeletion.Status.
+type AccountDeletionStatus string
+
+// AccountDeletionRequest defines model for AccountDeletionRequest.
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+// Attachment defines model for Attachment.
+type Attachment struct {
+	// Duration Milliseconds.
+	Duration *int64  `json:"duration,omitempty"`
+	MimeType *string `json:"mime_type,omitempty"`
+	Size     *int64  `json:"size,omitempty"`
+	Url      *string `json:"url,omitempty"`
+	Waveform *[]int  `json:"waveform,omitempty"`
+}
+
+// AuditLog defines model for AuditLog.
+type AuditLog struct {
+	Action    *string    `json:"action,omitempty"`
+	Actor     *string    `json:"actor,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Id        *string    `json:"id,omitempty"`
+	Note      *string    `json:"note,omitempty"`
+	Target    *string    `json:"target,omitempty"`
+}
+
+// AutoTranslate defines model for AutoTranslate.
+type AutoTranslate struct {
+	Receiver    *string `json:"receiver,omitempty"`
+	TranslateTo *string `json:"translate_to,omitempty"`
+}
+
+// AutoTranslateRequest defines model for AutoTranslateRequest.
+type AutoTranslateRequest struct {
+	// Lang An empty lang turns auto-translate off.
+	Lang string `json:"lang"`
+}
+
+// Bot defines model for Bot.
+type Bot struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	CreatedBy *string    `json:"created_by,omitempty"`
+	Id        *string    `json:"id,omitempty"`
+	Member    *string    `json:"member,omitempty"`
+	Profile   *string    `json:"profile,omitempty"`
+	TokenHint *string    `json:"token_hint,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Webhook   *string    `json:"webhook,omitempty"`
+}
+
+// BotDetail defines model for BotDetail.
+type BotDetail struct {
+	Bot     Bot      `json:"bot"`
+	Profile *Profile `json:"profile,omitempty"`
+	Token   *string  `json:"token,omitempty"`
+	Webhook *Webhook `json:"webhook,omitempty"`
+}
+
+// BotMessage defines model for BotMessage.
+type BotMessage struct {
+	Message  string `json:"message"`
+	Receiver string `json:"receiver"`
+}
+
+// BotRequest defines model for BotRequest.
+type BotRequest struct {
+	DisplayName string  `json:"display_name"`
+	NickName    *string `json:"nick_name,omitempty"`
+	WebhookUrl  *string `json:"webhook_url,omitempty"`
+}
+
+// ChatCounter defines model for ChatCounter.
+type ChatCounter struct {
+	UnreadChats    *int64 `json:"unread_chats,omitempty"`
+	UnreadMessages *int64 `json:"unread_messages,omitempty"`
+}
+
+// ChatExport defines model for ChatExport.
+type ChatExport struct {
+	ChatId      *string    `json:"chat_id,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+
+	// ExpiresAt When the file of a ready export is removed.
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Format    *ChatExportFormat `json:"format,omitempty"`
+	Id        *string           `json:"id,omitempty"`
+	Messages  *int64            `json:"messages,omitempty"`
+	Profile   *string           `json:"profile,omitempty"`
+	Receiver  *string           `json:"receiver,omitempty"`
+	Status    *ChatExportStatus `json:"status,omitempty"`
+	Url       *string           `json:"url,omitempty"`
+}
+
+// ChatExportFormat defines model for ChatExport.Format.
+type ChatExportFormat string
+
+// ChatExportStatus defines model for ChatExport.Status.
+type ChatExportStatus string
+
+// ChatExportRequest defines model for ChatExportRequest.
+type ChatExportRequest struct {
+	Format ChatExportRequestFormat `json:"format"`
+}
+
+// ChatExportRequestFormat defines model for ChatExportRequest.Format.
+type ChatExportRequestFormat string
+
+// ChatImportRequest defines model for ChatImportRequest.
+type ChatImportRequest struct {
+	// File At most 20MB.
+	File openapi_types.File `json:"file"`
+
+	// OwnerName The member's name in the export.
+	OwnerName *string `json:"owner_name,omitempty"`
+
+	// ReceiverName The other participant's name in the export.
+	ReceiverName *string                 `json:"receiver_name,omitempty"`
+	Source       ChatImportRequestSource `json:"source"`
+
+	// TimeZone Time zone of the exported times. Defaults to TIME_ZONE.
+	TimeZone *string `json:"time_zone,omitempty"`
+}
+
+// ChatImportRequestSource defines model for ChatImportRequest.Source.
+type ChatImportRequestSource string
+
+// ChatListSummary defines model for ChatListSummary.
+type ChatListSummary struct {
+	Chats   *[]ChatRoom  `json:"chats,omitempty"`
+	Counter *ChatCounter `json:"counter,omitempty"`
+}
+
+// ChatMessage defines model for ChatMessage.
+type ChatMessage struct {
+	Attachment *Attachment `json:"attachment,omitempty"`
+
+	// ChatId The reader's profile id and the other participant's, joined with a dash.
+	ChatId      *string    `json:"chat_id,omitempty"`
+	ClientMsgId *string    `json:"client_msg_id,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	Id          *string    `json:"id,omitempty"`
+	Message     *string    `json:"message,omitempty"`
+	Pinned      *bool      `json:"pinned,omitempty"`
+	PlayedAt    *time.Time `json:"played_at,omitempty"`
+	Poll        *Poll      `json:"poll,omitempty"`
+	Preview     *Preview   `json:"preview,omitempty"`
+
+	// Reactions Each participant's reaction, keyed by profile id.
+	Reactions *map[string]string `json:"reactions,omitempty"`
+	Receiver  *string            `json:"receiver,omitempty"`
+
+	// Ref The id of the message, shared by both sides.
+	Ref         *string             `json:"ref,omitempty"`
+	Sender      *string             `json:"sender,omitempty"`
+	Seq         *int64              `json:"seq,omitempty"`
+	Starred     *bool               `json:"starred,omitempty"`
+	Translation *MessageTranslation `json:"translation,omitempty"`
+	Type        *ChatMessageType    `json:"type,omitempty"`
+}
+
+// ChatMessageType defines model for ChatMessage.Type.
+type ChatMessageType string
+
+// ChatMessageRead defines model for ChatMessageRead.
+type ChatMessageRead struct {
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	Reader      *string    `json:"reader,omitempty"`
+	Ref         *string    `json:"ref,omitempty"`
+	Seq         *int64     `json:"seq,omitempty"`
+	UnreadCount *int64     `json:"unread_count,omitempty"`
+}
+
+// ChatPin defines model for ChatPin.
+type ChatPin struct {
+	Chat      *string    `json:"chat,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Id        *string    `json:"id,omitempty"`
+	PinnedBy  *string    `json:"pinned_by,omitempty"`
+	Ref       *string    `json:"ref,omitempty"`
+}
+
+// ChatRoom defines model for ChatRoom.
+type ChatRoom struct {
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Id          *string    `json:"id,omitempty"`
+	IsRead      *bool      `json:"is_read,omitempty"`
+	LastReadId  *string    `json:"last_read_id,omitempty"`
+	LastReadSeq *int64     `json:"last_read_seq,omitempty"`
+	Message     *string    `json:"message,omitempty"`
+	Muted       *bool      `json:"muted,omitempty"`
+	MutedUntil  *time.Time `json:"muted_until,omitempty"`
+	Receiver    *[]Profile `json:"receiver,omitempty"`
+	Sender      *string    `json:"sender,omitempty"`
+	Seq         *int64     `json:"seq,omitempty"`
+	TranslateTo *string    `json:"translate_to,omitempty"`
+	UnreadCount *int64     `json:"unread_count,omitempty"`
+}
+
+// ChatStar defines model for ChatStar.
+type ChatStar struct {
+	ChatId    *string    `json:"chat_id,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Id        *string    `json:"id,omitempty"`
+	Profile   *string    `json:"profile,omitempty"`
+	Ref       *string    `json:"ref,omitempty"`
+}
+
+// ChatSync defines model for ChatSync.
+type ChatSync struct {
+	HasMore  *bool          `json:"has_more,omitempty"`
+	Messages *[]ChatMessage `json:"messages,omitempty"`
+	Seq      *int64         `json:"seq,omitempty"`
+}
+
+// ConversationMessageRequest defines model for ConversationMessageRequest.
+type ConversationMessageRequest struct {
+	ClientMsgId *string `json:"client_msg_id,omitempty"`
+	Message     string  `json:"message"`
+}
+
+// ConversationParticipant defines model for ConversationParticipant.
+type ConversationParticipant struct {
+	LastReadSeq *int64   `json:"last_read_seq,omitempty"`
+	Profile     *Profile `json:"profile,omitempty"`
+}
+
+// ConversationRequest defines model for ConversationRequest.
+type ConversationRequest struct {
+	// Participant The profile id to start the conversation with.
+	Participant string `json:"participant"`
+}
+
+// ConversationResource defines model for ConversationResource.
+type ConversationResource struct {
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	Id            *string    `json:"id,omitempty"`
+	LastMessage   *string    `json:"last_message,omitempty"`
+	LastMessageId *string    `json:"last_message_id,omitempty"`
+	LastReadSeq   *int64     `json:"last_read_seq,omitempty"`
+	LastSender    *string    `json:"last_sender,omitempty"`
+	Muted         *bool      `json:"muted,omitempty"`
+	MutedUntil    *time.Time `json:"muted_until,omitempty"`
+	Participants  *[]string  `json:"participants,omitempty"`
+	Seq           *int64     `json:"seq,omitempty"`
+	TranslateTo   *string    `json:"translate_to,omitempty"`
+	UnreadCount   *int64     `json:"unread_count,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// DeletedMessage defines model for DeletedMessage.
+type DeletedMessage struct {
+	DeletedFor *DeletedMessageDeletedFor `json:"deleted_for,omitempty"`
+	Id         *string                   `json:"id,omitempty"`
+}
+
+// DeletedMessageDeletedFor defines model for DeletedMessage.DeletedFor.
+type DeletedMessageDeletedFor string
+
+// ErrorCode defines model for ErrorCode.
+type ErrorCode struct {
+	Error struct {
+		// Code The locale key of the error, e.g. chat_not_found.
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GraphQLRequest defines model for GraphQLRequest.
+type GraphQLRequest struct {
+	OperationName *string                 `json:"operationName,omitempty"`
+	Query         string                  `json:"query"`
+	Variables     *map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse defines model for GraphQLResponse.
+type GraphQLResponse struct {
+	Data   *map[string]interface{}   `json:"data,omitempty"`
+	Errors *[]map[string]interface{} `json:"errors,omitempty"`
+}
+
+// HiddenChat defines model for HiddenChat.
+type HiddenChat struct {
+	Hidden   *bool   `json:"hidden,omitempty"`
+	Receiver *string `json:"receiver,omitempty"`
+}
+
+// HiddenMessage defines model for HiddenMessage.
+type HiddenMessage struct {
+	Hidden *bool   `json:"hidden,omitempty"`
+	Id     *string `json:"id,omitempty"`
+}
+
+// ImportResult defines model for ImportResult.
+type ImportResult struct {
+	Imported *int `json:"imported,omitempty"`
+	Parsed   *int `json:"parsed,omitempty"`
+
+	// Rejected Messages moderation refused to store.
+	Rejected *int `json:"rejected,omitempty"`
+	Skipped  *int `json:"skipped,omitempty"`
+}
+
+// LeftConversation defines model for LeftConversation.
+type LeftConversation struct {
+	Id   *string `json:"id,omitempty"`
+	Left *bool   `json:"left,omitempty"`
+}
+
+// MarkReadRequest defines model for MarkReadRequest.
+type MarkReadRequest struct {
+	MessageId *string `json:"message_id,omitempty"`
+	Seq       *int64  `json:"seq,omitempty"`
+}
+
+// Member defines model for Member.
+type Member struct {
+	Active     *bool   `json:"active,omitempty"`
+	CreatedAt  *string `json:"created_at,omitempty"`
+	Email      *string `json:"email,omitempty"`
+	Id         *string `json:"id,omitempty"`
+	MemberType *int    `json:"member_type,omitempty"`
+
+	// PasswordReset Set when staff forced a password reset. Sign in fails until the password is reset with a code.
+	PasswordReset *bool `json:"password_reset,omitempty"`
+
+	// Permissions What the member may do in the admin API, from its role and its own grants.
+	Permissions *[]Permission `json:"permissions,omitempty"`
+	Role        *MemberRole   `json:"role,omitempty"`
+	Verified    *bool         `json:"verified,omitempty"`
+}
+
+// MemberRole defines model for Member.Role.
+type MemberRole string
+
+// MemberDetail defines model for MemberDetail.
+type MemberDetail struct {
+	Member  *Member  `json:"member,omitempty"`
+	Profile *Profile `json:"profile,omitempty"`
+
+	// Sessions Devices the member is signed in on.
+	Sessions *int `json:"sessions,omitempty"`
+}
+
+// MemberRoleRequest defines model for MemberRoleRequest.
+type MemberRoleRequest struct {
+	Note *string `json:"note,omitempty"`
+
+	// Permissions Granted on top of the role.
+	Permissions *[]Permission         `json:"permissions,omitempty"`
+	Role        MemberRoleRequestRole `json:"role"`
+}
+
+// MemberRoleRequestRole defines model for MemberRoleRequest.Role.
+type MemberRoleRequestRole string
+
+// MessageTranslation defines model for MessageTranslation.
+type MessageTranslation struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Lang      *string    `json:"lang,omitempty"`
+	Ref       *string    `json:"ref,omitempty"`
+	Source    *string    `json:"source,omitempty"`
+	Text      *string    `json:"text,omitempty"`
+}
+
+// ModerationAction defines model for ModerationAction.
+type ModerationAction struct {
+	Action ModerationActionAction `json:"action"`
+	Note   *string                `json:"note,omitempty"`
+}
+
+// ModerationActionAction defines model for ModerationAction.Action.
+type ModerationActionAction string
+
+// ModerationNote defines model for ModerationNote.
+type ModerationNote struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// ModerationReason defines model for ModerationReason.
+type ModerationReason struct {
+	Filter  *string `json:"filter,omitempty"`
+	Reason  *string `json:"reason,omitempty"`
+	Verdict *string `json:"verdict,omitempty"`
+}
+
+// ModerationReview defines model for ModerationReview.
+type ModerationReview struct {
+	CreatedAt  *time.Time              `json:"created_at,omitempty"`
+	Id         *string                 `json:"id,omitempty"`
+	Message    *string                 `json:"message,omitempty"`
+	Original   *string                 `json:"original,omitempty"`
+	Reasons    *[]ModerationReason     `json:"reasons,omitempty"`
+	Receiver   *string                 `json:"receiver,omitempty"`
+	Ref        *string                 `json:"ref,omitempty"`
+	Resolution *Resolution             `json:"resolution,omitempty"`
+	Sender     *string                 `json:"sender,omitempty"`
+	Status     *ModerationReviewStatus `json:"status,omitempty"`
+}
+
+// ModerationReviewStatus defines model for ModerationReview.Status.
+type ModerationReviewStatus string
+
+// Page defines model for Page.
+type Page struct {
+	HasMore    bool    `json:"has_more"`
+	Limit      int64   `json:"limit"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// Permission defines model for Permission.
+type Permission string
+
+// PinnedMessage defines model for PinnedMessage.
+type PinnedMessage struct {
+	Message *ChatMessage `json:"message,omitempty"`
+	Pin     *ChatPin     `json:"pin,omitempty"`
+}
+
+// Poll defines model for Poll.
+type Poll struct {
+	Anonymous *bool         `json:"anonymous,omitempty"`
+	ClosedAt  *time.Time    `json:"closed_at,omitempty"`
+	ClosesAt  *time.Time    `json:"closes_at,omitempty"`
+	CreatedAt *time.Time    `json:"created_at,omitempty"`
+	CreatedBy *string       `json:"created_by,omitempty"`
+	Multiple  *bool         `json:"multiple,omitempty"`
+	Options   *[]PollOption `json:"options,omitempty"`
+	Question  *string       `json:"question,omitempty"`
+	Ref       *string       `json:"ref,omitempty"`
+	Voters    *int64        `json:"voters,omitempty"`
+}
+
+// PollOption defines model for PollOption.
+type PollOption struct {
+	Text   *string   `json:"text,omitempty"`
+	Voters *[]string `json:"voters,omitempty"`
+	Votes  *int64    `json:"votes,omitempty"`
+}
+
+// PollRequest defines model for PollRequest.
+type PollRequest struct {
+	Anonymous *bool      `json:"anonymous,omitempty"`
+	ClosesAt  *time.Time `json:"closes_at,omitempty"`
+	Multiple  *bool      `json:"multiple,omitempty"`
+	Options   []string   `json:"options"`
+	Question  string     `json:"question"`
+}
+
+// PollVoteRequest defines model for PollVoteRequest.
+type PollVoteRequest struct {
+	Options []int `json:"options"`
+}
+
+// PollWithVote defines model for PollWithVote.
+type PollWithVote struct {
+	Poll *Poll  `json:"poll,omitempty"`
+	Vote *[]int `json:"vote,omitempty"`
+}
+
+// Preview defines model for Preview.
+type Preview struct {
+	Description *string `json:"description,omitempty"`
+	Image       *string `json:"image,omitempty"`
+	SiteName    *string `json:"site_name,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	Url         *string `json:"url,omitempty"`
+}
+
+// Profile defines model for Profile.
+type Profile struct {
+	Authorized  *int       `json:"authorized,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	DisplayName *string    `json:"display_name,omitempty"`
+	Id          *string    `json:"id,omitempty"`
+	IsBot       *bool      `json:"is_bot,omitempty"`
+
+	// IsDeleted The member deleted its account. The profile only keeps its id.
+	IsDeleted    *bool   `json:"is_deleted,omitempty"`
+	IsPrivate    *bool   `json:"is_private,omitempty"`
+	Member       *string `json:"member,omitempty"`
+	NickName     *string `json:"nick_name,omitempty"`
+	ProfileImage *string `json:"profile_image,omitempty"`
+}
+
+// ReactionRequest defines model for ReactionRequest.
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// RefreshRequest defines model for RefreshRequest.
+type RefreshRequest struct {
+	Refresh string `json:"refresh"`
+}
+
+// Report defines model for Report.
+type Report struct {
+	Assignee   *string        `json:"assignee,omitempty"`
+	CreatedAt  *time.Time     `json:"created_at,omitempty"`
+	Details    *string        `json:"details,omitempty"`
+	Id         *string        `json:"id,omitempty"`
+	Kind       *ReportKind    `json:"kind,omitempty"`
+	MessageRef *string        `json:"message_ref,omitempty"`
+	Priority   *int           `json:"priority,omitempty"`
+	Reason     *string        `json:"reason,omitempty"`
+	Reported   *string        `json:"reported,omitempty"`
+	Reporter   *string        `json:"reporter,omitempty"`
+	Resolution *Resolution    `json:"resolution,omitempty"`
+	Snapshot   *[]ChatMessage `json:"snapshot,omitempty"`
+	Status     *ReportStatus  `json:"status,omitempty"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+}
+
+// ReportKind defines model for Report.Kind.
+type ReportKind string
+
+// ReportStatus defines model for Report.Status.
+type ReportStatus string
+
+// ReportRequest defines model for ReportRequest.
+type ReportRequest struct {
+	Details *string           `json:"details,omitempty"`
+	Kind    ReportRequestKind `json:"kind"`
+
+	// MessageId Required for message reports.
+	MessageId *string `json:"message_id,omitempty"`
+
+	// ProfileId Required for profile reports.
+	ProfileId *string `json:"profile_id,omitempty"`
+	Reason    string  `json:"reason"`
+}
+
+// ReportRequestKind defines model for ReportRequest.Kind.
+type ReportRequestKind string
+
+// Resolution defines model for Resolution.
+type Resolution struct {
+	Action     *string    `json:"action,omitempty"`
+	Moderator  *string    `json:"moderator,omitempty"`
+	Note       *string    `json:"note,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ServiceAccount defines model for ServiceAccount.
+type ServiceAccount struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	CreatedBy *string    `json:"created_by,omitempty"`
+	Id        *string    `json:"id,omitempty"`
+	Name      *string    `json:"name,omitempty"`
+	TokenHint *string    `json:"token_hint,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ServiceAccountRequest defines model for ServiceAccountRequest.
+type ServiceAccountRequest struct {
+	Name string `json:"name"`
+}
+
+// ServiceAccountToken defines model for ServiceAccountToken.
+type ServiceAccountToken struct {
+	ServiceAccount ServiceAccount `json:"service_account"`
+	Token          string         `json:"token"`
+}
+
+// Session defines model for Session.
+type Session struct {
+	Member  Member      `json:"member"`
+	Profile Profile     `json:"profile"`
+	Token   TokenDetail `json:"token"`
+}
+
+// SignIn defines model for SignIn.
+type SignIn struct {
+	Email    openapi_types.Email `json:"email"`
+	Password string              `json:"password"`
+	UniqueId string              `json:"unique_id"`
+}
+
+// SignUp defines model for SignUp.
+type SignUp struct {
+	DisplayName  string              `json:"display_name"`
+	Email        openapi_types.Email `json:"email"`
+	Password     string              `json:"password"`
+	ProfileImage *string             `json:"profile_image,omitempty"`
+
+	// UniqueId The device id the tokens are issued to.
+	UniqueId string `json:"unique_id"`
+}
+
+// StarredMessage defines model for StarredMessage.
+type StarredMessage struct {
+	Message *ChatMessage `json:"message,omitempty"`
+	Star    *ChatStar    `json:"star,omitempty"`
+}
+
+// TokenDetail defines model for TokenDetail.
+type TokenDetail struct {
+	Access        *string `json:"access,omitempty"`
+	Refresh       *string `json:"refresh,omitempty"`
+	RefreshExpire *int64  `json:"refresh_expire,omitempty"`
+	RefreshUuid   *string `json:"refresh_uuid,omitempty"`
+	TokenExpire   *int64  `json:"token_expire,omitempty"`
+	TokenUuid     *string `json:"token_uuid,omitempty"`
+}
+
+// TriageRequest defines model for TriageRequest.
+type TriageRequest struct {
+	Assignee *string `json:"assignee,omitempty"`
+	Note     *string `json:"note,omitempty"`
+	Priority *int    `json:"priority,omitempty"`
+}
+
+// UpdateConversationRequest defines model for UpdateConversationRequest.
+type UpdateConversationRequest struct {
+	Muted *bool `json:"muted,omitempty"`
+
+	// MutedUntil Mutes until then. Must be in the future.
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+
+	// TranslateTo An empty value turns auto-translate off.
+	TranslateTo *string `json:"translate_to,omitempty"`
+}
+
+// UpdatePasswordRequest defines model for UpdatePasswordRequest.
+type UpdatePasswordRequest struct {
+	ConfirmPassword string `json:"confirm_password"`
+	NewPassword     string `json:"new_password"`
+	Password        string `json:"password"`
+}
+
+// VerificationCodeRequest Each route reads the fields it needs; reset routes use email, code and password.
+type VerificationCodeRequest struct {
+	Code *string `json:"code,omitempty"`
+
+	// CodeType 1 new user, 2 renew password, 3 reset password.
+	CodeType *VerificationCodeRequestCodeType `json:"code_type,omitempty"`
+	Email    *openapi_types.Email             `json:"email,omitempty"`
+	Member   *string                          `json:"member,omitempty"`
+	Password *string                          `json:"password,omitempty"`
+}
+
+// VerificationCodeRequestCodeType 1 new user, 2 renew password, 3 reset password.
+type VerificationCodeRequestCodeType int
+
+// VoiceMessageRequest defines model for VoiceMessageRequest.
+type VoiceMessageRequest struct {
+	Audio openapi_types.File `json:"audio"`
+}
+
+// Webhook defines model for Webhook.
+type Webhook struct {
+	Active      *bool      `json:"active,omitempty"`
+	Bot         *string    `json:"bot,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	CreatedBy   *string    `json:"created_by,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Events      *[]string  `json:"events,omitempty"`
+	Id          *string    `json:"id,omitempty"`
+	Secret      *string    `json:"secret,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Url         *string    `json:"url,omitempty"`
+}
+
+// WebhookAttempt defines model for WebhookAttempt.
+type WebhookAttempt struct {
+	AttemptAt *time.Time `json:"attempt_at,omitempty"`
+
+	// Duration Milliseconds.
+	Duration   *int64  `json:"duration,omitempty"`
+	Error      *string `json:"error,omitempty"`
+	StatusCode *int    `json:"status_code,omitempty"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempts      *int                   `json:"attempts,omitempty"`
+	CreatedAt     *time.Time             `json:"created_at,omitempty"`
+	Event         *string                `json:"event,omitempty"`
+	EventId       *string                `json:"event_id,omitempty"`
+	Id            *string                `json:"id,omitempty"`
+	Log           *[]WebhookAttempt      `json:"log,omitempty"`
+	NextAttemptAt *time.Time             `json:"next_attempt_at,omitempty"`
+	Payload       *string                `json:"payload,omitempty"`
+	Status        *WebhookDeliveryStatus `json:"status,omitempty"`
+	UpdatedAt     *time.Time             `json:"updated_at,omitempty"`
+	Webhook       *string                `json:"webhook,omitempty"`
+}
+
+// WebhookDeliveryStatus defines model for WebhookDelivery.Status.
+type WebhookDeliveryStatus string
+
+// WebhookRequest defines model for WebhookRequest.
+type WebhookRequest struct {
+	Active      *bool                  `json:"active,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Events      []WebhookRequestEvents `json:"events"`
+
+	// Url Must be https.
+	Url string `json:"url"`
+}
+
+// WebhookRequestEvents defines model for WebhookRequest.Events.
+type WebhookRequestEvents string
+
+// ConversationID defines model for ConversationID.
+type ConversationID = string
+
+// ConversationMessageID defines model for ConversationMessageID.
+type ConversationMessageID = string
+
+// ID defines model for ID.
+type ID = string
+
+// Limit defines model for Limit.
+type Limit = int64
+
+// MessageID defines model for MessageID.
+type MessageID = string
+
+// PageNumber defines model for PageNumber.
+type PageNumber = int64
+
+// Receiver defines model for Receiver.
+type Receiver = string
+
+// Ref defines model for Ref.
+type Ref = string
+
+// AccountDeletionResponse defines model for AccountDeletionResponse.
+type AccountDeletionResponse struct {
+	Data AccountDeletion `json:"data"`
+}
+
+// BotResponse defines model for BotResponse.
+type BotResponse struct {
+	Data BotDetail `json:"data"`
+}
+
+// ChatExportResponse defines model for ChatExportResponse.
+type ChatExportResponse struct {
+	Data ChatExport `json:"data"`
+}
+
+// ChatMessageResponse defines model for ChatMessageResponse.
+type ChatMessageResponse struct {
+	Data ChatMessage `json:"data"`
+}
+
+// ConversationMessageResponse defines model for ConversationMessageResponse.
+type ConversationMessageResponse struct {
+	Data ChatMessage `json:"data"`
+}
+
+// ConversationResponse defines model for ConversationResponse.
+type ConversationResponse struct {
+	Data ConversationResource `json:"data"`
+}
+
+// EmptyResponse defines model for EmptyResponse.
+type EmptyResponse struct {
+	Data *interface{} `json:"data"`
+}
+
+// ErrorCodeResponse defines model for ErrorCodeResponse.
+type ErrorCodeResponse = ErrorCode
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse = string
+
+// MemberResponse defines model for MemberResponse.
+type MemberResponse struct {
+	Data Member `json:"data"`
+}
+
+// PollVoteResponse defines model for PollVoteResponse.
+type PollVoteResponse struct {
+	Data PollWithVote `json:"data"`
+}
+
+// ProfileResponse defines model for ProfileResponse.
+type ProfileResponse struct {
+	Data Profile `json:"data"`
+}
+
+// ReportResponse defines model for ReportResponse.
+type ReportResponse struct {
+	Data Report `json:"data"`
+}
+
+// ServiceAccountTokenResponse defines model for ServiceAccountTokenResponse.
+type ServiceAccountTokenResponse struct {
+	Data ServiceAccountToken `json:"data"`
+}
+
+// SessionResponse defines model for SessionResponse.
+type SessionResponse struct {
+	Data Session `json:"data"`
+}
+
+// WebhookDeliveriesResponse defines model for WebhookDeliveriesResponse.
+type WebhookDeliveriesResponse struct {
+	Data []WebhookDelivery `json:"data"`
+}
+
+// WebhookDeliveryResponse defines model for WebhookDeliveryResponse.
+type WebhookDeliveryResponse struct {
+	Data WebhookDelivery `json:"data"`
+}
+
+// WebhookResponse defines model for WebhookResponse.
+type WebhookResponse struct {
+	Data Webhook `json:"data"`
+}
+
+// GetAuditLogParams defines parameters for GetAuditLog.
+type GetAuditLogParams struct {
+	// Target Only entries about this member, report, review, webhook, bot or service account.
+	Target *string     `form:"target,omitempty" json:"target,omitempty"`
+	Page   *PageNumber `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// SearchMembersParams defines parameters for SearchMembers.
+type SearchMembersParams struct {
+	// Q Part of the email, the whole member id or the exact nick name.
+	Q      *string                  `form:"q,omitempty" json:"q,omitempty"`
+	Role   *SearchMembersParamsRole `form:"role,omitempty" json:"role,omitempty"`
+	Active *bool                    `form:"active,omitempty" json:"active,omitempty"`
+	Page   *PageNumber              `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// SearchMembersParamsRole defines parameters for SearchMembers.
+type SearchMembersParamsRole string
+
+// GetReportsParams defines parameters for GetReports.
+type GetReportsParams struct {
+	Status *GetReportsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Page   *PageNumber             `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// GetReportsParamsStatus defines parameters for GetReports.
+type GetReportsParamsStatus string
+
+// GetReviewsParams defines parameters for GetReviews.
+type GetReviewsParams struct {
+	Status *GetReviewsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Page   *PageNumber             `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// GetReviewsParamsStatus defines parameters for GetReviews.
+type GetReviewsParamsStatus string
+
+// GetDeliveriesParams defines parameters for GetDeliveries.
+type GetDeliveriesParams struct {
+	Webhook *string                    `form:"webhook,omitempty" json:"webhook,omitempty"`
+	Status  *GetDeliveriesParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Page    *PageNumber                `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// GetDeliveriesParamsStatus defines parameters for GetDeliveries.
+type GetDeliveriesParamsStatus string
+
+// GetWebhookDeliveriesParams defines parameters for GetWebhookDeliveries.
+type GetWebhookDeliveriesParams struct {
+	Status *GetWebhookDeliveriesParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Page   *PageNumber                       `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// GetWebhookDeliveriesParamsStatus defines parameters for GetWebhookDeliveries.
+type GetWebhookDeliveriesParamsStatus string
+
+// GetMessageTranslationParams defines parameters for GetMessageTranslation.
+type GetMessageTranslationParams struct {
+	// Lang Defaults to the Accept-Language header.
+	Lang *string `form:"lang,omitempty" json:"lang,omitempty"`
+}
+
+// GetStarredParams defines parameters for GetStarred.
+type GetStarredParams struct {
+	Page *PageNumber `form:"page,omitempty" json:"page,omitempty"`
+}
+
+// SyncMessagesParams defines parameters for SyncMessages.
+type SyncMessagesParams struct {
+	AfterSeq  *int64 `form:"after_seq,omitempty" json:"after_seq,omitempty"`
+	BeforeSeq *int64 `form:"before_seq,omitempty" json:"before_seq,omitempty"`
+	Limit     *int64 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetProfileByNickNameParams defines parameters for GetProfileByNickName.
+type GetProfileByNickNameParams struct {
+	// Source Where the nick name was found.
+	Source GetProfileByNickNameParamsSource `form:"source" json:"source"`
+}
+
+// GetProfileByNickNameParamsSource defines parameters for GetProfileByNickName.
+type GetProfileByNickNameParamsSource string
+
+// ListConversationsParams defines parameters for ListConversations.
+type ListConversationsParams struct {
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The next_cursor of the previous page.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListMessagesParams defines parameters for ListMessages.
+type ListMessagesParams struct {
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The seq to continue before, from next_cursor.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// CreateBotJSONRequestBody defines body for CreateBot for application/json ContentType.
+type CreateBotJSONRequestBody = BotRequest
+
+// UpdateBotJSONRequestBody defines body for UpdateBot for application/json ContentType.
+type UpdateBotJSONRequestBody = BotRequest
+
+// ImportChatMultipartRequestBody defines body for ImportChat for multipart/form-data ContentType.
+type ImportChatMultipartRequestBody = ChatImportRequest
+
+// ActivateMemberJSONRequestBody defines body for ActivateMember for application/json ContentType.
+type ActivateMemberJSONRequestBody = ModerationNote
+
+// DeactivateMemberJSONRequestBody defines body for DeactivateMember for application/json ContentType.
+type DeactivateMemberJSONRequestBody = ModerationNote
+
+// ResetMemberPasswordJSONRequestBody defines body for ResetMemberPassword for application/json ContentType.
+type ResetMemberPasswordJSONRequestBody = ModerationNote
+
+// ReinstateMemberJSONRequestBody defines body for ReinstateMember for application/json ContentType.
+type ReinstateMemberJSONRequestBody = ModerationNote
+
+// UpdateMemberRoleJSONRequestBody defines body for UpdateMemberRole for application/json ContentType.
+type UpdateMemberRoleJSONRequestBody = MemberRoleRequest
+
+// RevokeMemberSessionsJSONRequestBody defines body for RevokeMemberSessions for application/json ContentType.
+type RevokeMemberSessionsJSONRequestBody = ModerationNote
+
+// SuspendMemberJSONRequestBody defines body for SuspendMember for application/json ContentType.
+type SuspendMemberJSONRequestBody = ModerationNote
+
+// ResolveReportJSONRequestBody defines body for ResolveReport for application/json ContentType.
+type ResolveReportJSONRequestBody = ModerationAction
+
+// TriageReportJSONRequestBody defines body for TriageReport for application/json ContentType.
+type TriageReportJSONRequestBody = TriageRequest
+
+// ResolveReviewJSONRequestBody defines body for ResolveReview for application/json ContentType.
+type ResolveReviewJSONRequestBody = ModerationAction
+
+// CreateServiceAccountJSONRequestBody defines body for CreateServiceAccount for application/json ContentType.
+type CreateServiceAccountJSONRequestBody = ServiceAccountRequest
+
+// CreateWebhookJSONRequestBody defines body for CreateWebhook for application/json ContentType.
+type CreateWebhookJSONRequestBody = WebhookRequest
+
+// UpdateWebhookJSONRequestBody defines body for UpdateWebhook for application/json ContentType.
+type UpdateWebhookJSONRequestBody = WebhookRequest
+
+// SendBotMessageJSONRequestBody defines body for SendBotMessage for application/json ContentType.
+type SendBotMessageJSONRequestBody = BotMessage
+
+// CreateChatExportJSONRequestBody defines body for CreateChatExport for application/json ContentType.
+type CreateChatExportJSONRequestBody = ChatExportRequest
+
+// ReactToMessageJSONRequestBody defines body for ReactToMessage for application/json ContentType.
+type ReactToMessageJSONRequestBody = ReactionRequest
+
+// VotePollJSONRequestBody defines body for VotePoll for application/json ContentType.
+type VotePollJSONRequestBody = PollVoteRequest
+
+// CreatePollJSONRequestBody defines body for CreatePoll for application/json ContentType.
+type CreatePollJSONRequestBody = PollRequest
+
+// MarkChatReadJSONRequestBody defines body for MarkChatRead for application/json ContentType.
+type MarkChatReadJSONRequestBody = MarkReadRequest
+
+// SetAutoTranslateJSONRequestBody defines body for SetAutoTranslate for application/json ContentType.
+type SetAutoTranslateJSONRequestBody = AutoTranslateRequest
+
+// SendVoiceMessageMultipartRequestBody defines body for SendVoiceMessage for multipart/form-data ContentType.
+type SendVoiceMessageMultipartRequestBody = VoiceMessageRequest
+
+// GraphqlJSONRequestBody defines body for Graphql for application/json ContentType.
+type GraphqlJSONRequestBody = GraphQLRequest
+
+// CreateReportJSONRequestBody defines body for CreateReport for application/json ContentType.
+type CreateReportJSONRequestBody = ReportRequest
+
+// RequestAccountDeletionJSONRequestBody defines body for RequestAccountDeletion for application/json ContentType.
+type RequestAccountDeletionJSONRequestBody = AccountDeletionRequest
+
+// UpdatePasswordJSONRequestBody defines body for UpdatePassword for application/json ContentType.
+type UpdatePasswordJSONRequestBody = UpdatePasswordRequest
+
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody = RefreshRequest
+
+// SendResetCodeJSONRequestBody defines body for SendResetCode for application/json ContentType.
+type SendResetCodeJSONRequestBody = VerificationCodeRequest
+
+// ResetPasswordJSONRequestBody defines body for ResetPassword for application/json ContentType.
+type ResetPasswordJSONRequestBody = VerificationCodeRequest
+
+// SignInJSONRequestBody defines body for SignIn for application/json ContentType.
+type SignInJSONRequestBody = SignIn
+
+// SignUpJSONRequestBody defines body for SignUp for application/json ContentType.
+type SignUpJSONRequestBody = SignUp
+
+// CheckVerifyCodeJSONRequestBody defines body for CheckVerifyCode for application/json ContentType.
+type CheckVerifyCodeJSONRequestBody = VerificationCodeRequest
+
+// NewVerifyCodeJSONRequestBody defines body for NewVerifyCode for application/json ContentType.
+type NewVerifyCodeJSONRequestBody = VerificationCodeRequest
+
+// RenewVerifyCodeJSONRequestBody defines body for RenewVerifyCode for application/json ContentType.
+type RenewVerifyCodeJSONRequestBody = VerificationCodeRequest
+
+// VerifyResetPasswordJSONRequestBody defines body for VerifyResetPassword for application/json ContentType.
+type VerifyResetPasswordJSONRequestBody = VerificationCodeRequest
+
+// CreateConversationJSONRequestBody defines body for CreateConversation for application/json ContentType.
+type CreateConversationJSONRequestBody = ConversationRequest
+
+// UpdateConversationJSONRequestBody defines body for UpdateConversation for application/json ContentType.
+type UpdateConversationJSONRequestBody = UpdateConversationRequest
+
+// SendMessageJSONRequestBody defines body for SendMessage for application/json ContentType.
+type SendMessageJSONRequestBody = ConversationMessageRequest
+
+// EditMessageJSONRequestBody defines body for EditMessage for application/json ContentType.
+type EditMessageJSONRequestBody = ConversationMessageRequest
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/persistence"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/chatimport"
+	"github.com/majid-cj/go-chat-server/util/moderation"
+
+	"github.com/joho/godotenv"
+)
+
+// chat-import loads a WhatsApp .txt or Telegram result.json export into the
+// chat between two existing profiles:
+//
+//	go run ./cmd/chat-import -source whatsapp -file chat.txt -owner majid -receiver sara
+func main() {
+	source := flag.String("source", chatimport.SOURCE_WHATSAPP, "export format: whatsapp or telegram")
+	path := flag.String("file", "", "path to the exported chat")
+	owner := flag.String("owner", "", "profile id or nick name of the importing user")
+	receiver := flag.String("receiver", "", "profile id or nick name of the other participant")
+	ownerName := flag.String("owner-name", "", "author name of the owner inside the export")
+	receiverName := flag.String("receiver-name", "", "author name of the receiver inside the export")
+	timeZone := flag.String("tz", "", "time zone of the export timestamps (defaults to TIME_ZONE)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Fatal(err.Error())
+	}
+	if *path == "" || *owner == "" || *receiver == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *timeZone == "" {
+		*timeZone = os.Getenv("TIME_ZONE")
+	}
+	location, err := time.LoadLocation(*timeZone)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	repository, err := persistence.NewRepository()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer repository.Client.Disconnect(repository.Ctx)
+
+	ownerProfile, err := findProfile(repository, *owner)
+	if err != nil {
+		log.Fatalf("owner %s: %s", *owner, err.Error())
+	}
+	receiverProfile, err := findProfile(repository, *receiver)
+	if err != nil {
+		log.Fatalf("receiver %s: %s", *receiver, err.Error())
+	}
+
+	pipeline, err := moderation.NewDefaultPipeline("./locales")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer file.Close()
+
+	result, err := chatimport.NewImporter(repository.Chat, pipeline, repository.Moderation).Import(*source, file, chatimport.Participants{
+		Owner:        ownerProfile.ID,
+		OwnerName:    *ownerName,
+		Receiver:     receiverProfile.ID,
+		ReceiverName: *receiverName,
+	}, location)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	fmt.Printf("parsed %d messages, imported %d documents, skipped %d, rejected %d\n", result.Parsed, result.Imported, result.Skipped, result.Rejected)
+}
+
+func findProfile(repository *persistence.Repository, key string) (*entity.MemberProfile, error) {
+	if len(util.GetURLIds(key)) == 1 {
+		return repository.Profile.GetMemberProfileByID(key)
+	}
+	return repository.Profile.GetMemberProfileByNickName(key)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/majid-cj/go-chat-server/infrastructure/persistence"
+
+	"github.com/joho/godotenv"
+)
+
+// chat-migrate moves chats stored with a copy of every message per side into
+// one message record per send and one conversation per pair:
+//
+//	go run ./cmd/chat-migrate
+//
+// The old chat and chat_room collections are left as they are, and the
+// migration can be run again. It has to complete before the server starts,
+// so that legacy messages take the first seqs of their conversations.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	repository, err := persistence.NewRepository()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer repository.Client.Disconnect(repository.Ctx)
+
+	result, err := persistence.NewChatMigration(repository.Client.Database(os.Getenv("DB_NAME"))).Run()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	fmt.Printf("read %d message copies and %d rooms, wrote %d messages and %d conversations, numbered %d messages\n",
+		result.Copies, result.Rooms, result.Messages, result.Conversations, result.Numbered)
+}
@@ -3,14 +3,25 @@ package config
 import (
 	"context"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ipinfo/go/v2/ipinfo"
 	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/domain/entity"
 	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/infrastructure/dedup"
 	"github.com/majid-cj/go-chat-server/infrastructure/persistence"
+	"github.com/majid-cj/go-chat-server/infrastructure/ratelimit"
+	"github.com/majid-cj/go-chat-server/util/command"
 	"github.com/majid-cj/go-chat-server/util/fileupload"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+	"github.com/majid-cj/go-chat-server/util/moderation"
+	"github.com/majid-cj/go-chat-server/util/pubsub"
+	"github.com/majid-cj/go-chat-server/util/translate"
+	"github.com/majid-cj/go-chat-server/util/webhook"
+	"github.com/majid-cj/go-chat-server/util/wsconn"
 	"github.com/olahol/melody"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -20,6 +31,7 @@ import (
 type AppConfig struct {
 	sync.RWMutex
 	Melody      *melody.Melody
+	Socket      *wsconn.Manager
 	IPInfo      *ipinfo.Client
 	Log         *zap.SugaredLogger
 	AppContext  context.Context
@@ -29,7 +41,15 @@ type AppConfig struct {
 	Persistence *persistence.Repository
 	Auth        *auth.DBAuth
 	Token       *auth.Token
+	Flood       ratelimit.FloodControlInterface
+	Dedup       dedup.MessageDedupInterface
 	Upload      *fileupload.UploadFile
+	Preview     *linkpreview.Fetcher
+	Moderation  *moderation.Pipeline
+	Webhooks    *webhook.Dispatcher
+	Commands    *command.Registry
+	Translator  translate.Translator
+	Events      *pubsub.Broker
 	Session     map[string]*melody.Session
 }
 
@@ -39,6 +59,13 @@ func NewAppConfig() (*AppConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	pending, err := persistence.NewChatMigration(Persistence.Client.Database(os.Getenv("DB_NAME"))).Pending()
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, persistence.ErrChatMigrationPending
+	}
 	Auth := auth.NewDBAuth()
 	IpInfo := ipinfo.NewClient(nil, nil, os.Getenv("IP_INFO"))
 
@@ -53,8 +80,24 @@ func NewAppConfig() (*AppConfig, error) {
 
 	sugar := logger.Sugar()
 
+	Moderation, err := moderation.NewDefaultPipeline("./locales")
+	if err != nil {
+		return nil, err
+	}
+
+	Commands := command.NewRegistry(
+		command.NewMute(Persistence.Chat),
+		command.NewUnmute(Persistence.Chat),
+		command.NewPoll(),
+	)
+
+	socketOptions := wsconn.NewOptions()
+	Melody := melody.New()
+	socketOptions.Apply(Melody.Config)
+
 	return &AppConfig{
-		Melody:      melody.New(),
+		Melody:      Melody,
+		Socket:      wsconn.NewManager(socketOptions, entity.NewChatBatch),
 		IPInfo:      IpInfo,
 		Log:         sugar,
 		AppContext:  context.Background(),
@@ -63,33 +106,94 @@ func NewAppConfig() (*AppConfig, error) {
 		Persistence: Persistence,
 		Auth:        Auth,
 		Token:       auth.NewToken(),
+		Flood:       ratelimit.NewFloodControl(Auth.DB),
+		Dedup:       dedup.NewMessageDedup(Auth.DB),
 		Upload:      fileupload.NewUploadFile(),
+		Preview:     linkpreview.NewFetcher(),
+		Moderation:  Moderation,
+		Webhooks:    webhook.NewDispatcher(Persistence.Webhook, sugar),
+		Commands:    Commands,
+		Translator:  translate.NewTranslator(),
+		Events:      pubsub.NewBroker(),
 		Session:     make(map[string]*melody.Session),
 	}, nil
 }
 
-// Set ...
+// Set registers value for key, closing the session it replaces.
 func (config *AppConfig) Set(key string, value *melody.Session) {
 	config.Lock()
-	defer config.Unlock()
+	previous := config.Session[key]
 	config.Session[key] = value
+	config.Unlock()
+
+	if previous != nil && previous != value && !previous.IsClosed() {
+		config.Socket.Replaced(previous)
+	}
 }
 
-// Get ...
+// Get returns the open session for key, if any.
 func (config *AppConfig) Get(key string) *melody.Session {
 	config.RLock()
-	defer config.RUnlock()
-	return config.Session[key]
+	session := config.Session[key]
+	config.RUnlock()
+
+	if session != nil && session.IsClosed() {
+		config.CloseSession(key, session)
+		return nil
+	}
+	return session
+}
+
+// Send writes value to the session for key when it is connected.
+func (config *AppConfig) Send(key string, value interface{}) bool {
+	session := config.Get(key)
+	if session == nil {
+		return false
+	}
+	return config.Socket.Write(session, value) == nil
+}
+
+// CloseSession removes session from key, unless a newer session has
+// already taken its place.
+func (config *AppConfig) CloseSession(key string, session *melody.Session) {
+	config.Lock()
+	defer config.Unlock()
+	if config.Session[key] == session {
+		delete(config.Session, key)
+	}
 }
 
-// CloseSession ...
-func (config *AppConfig) CloseSession(key string) {
+// CloseProfileSessions closes every chat socket opened by profile.
+func (config *AppConfig) CloseProfileSessions(profile string, code int, reason string) {
+	var sessions []*melody.Session
 	config.RLock()
-	defer config.RUnlock()
-	delete(config.Session, key)
+	for key, session := range config.Session {
+		if strings.HasPrefix(key, profile+"-") {
+			sessions = append(sessions, session)
+		}
+	}
+	config.RUnlock()
+
+	for _, session := range sessions {
+		config.Socket.Close(session, code, reason)
+	}
+}
+
+// Audit records an action once it was carried out, so the log only holds
+// what happened. The action stands when the entry cannot be written; the
+// failure is logged instead.
+func (config *AppConfig) Audit(actor, action, target, note string) {
+	err := config.Persistence.AuditLog.AddAuditLog(entity.NewAuditLog(actor, action, target, note))
+	if err != nil {
+		config.Log.Errorf("audit %s %s: %+v", action, target, err)
+	}
 }
 
-// SendNotifications ...
-func (config *AppConfig) SendNotifications(message interface{}) error {
-	return nil
+// SendNotifications queues event for every webhook subscribed to it.
+func (config *AppConfig) SendNotifications(event string, data interface{}) error {
+	err := config.Webhooks.Publish(event, data)
+	if err != nil {
+		config.Log.Errorf("webhook event %s: %+v", event, err)
+	}
+	return err
 }
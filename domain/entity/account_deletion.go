@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"os"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// DELETION_PENDING is a deletion waiting for its grace period to end. The
+	// member can still sign in and cancel it.
+	DELETION_PENDING = "pending"
+	// DELETION_RUNNING is a deletion one replica is carrying out, until its
+	// claim runs out.
+	DELETION_RUNNING = "running"
+	// DELETION_DONE ...
+	DELETION_DONE = "done"
+	// DELETION_CANCELLED ...
+	DELETION_CANCELLED = "cancelled"
+
+	// DELETION_LEASE is how long a replica holds a running deletion. A
+	// deletion still running after that, because its replica stopped, is
+	// picked up again.
+	DELETION_LEASE = 15 * time.Minute
+
+	// DEFAULT_DELETION_GRACE is how long a member has to change its mind when
+	// ACCOUNT_DELETION_GRACE is not set.
+	DEFAULT_DELETION_GRACE = 30 * 24 * time.Hour
+
+	// DELETED_DISPLAY_NAME is what other members see in place of a deleted
+	// profile.
+	DELETED_DISPLAY_NAME = "Deleted account"
+)
+
+// AccountDeletion is a member's request to delete its account. It is kept
+// once done, as the record that the account was deleted, without the email.
+type AccountDeletion struct {
+	ID           string     `bson:"id" json:"id"`
+	Member       string     `bson:"member" json:"member"`
+	Profile      string     `bson:"profile" json:"profile"`
+	Email        string     `bson:"email,omitempty" json:"-"`
+	Status       string     `bson:"status" json:"status"`
+	RequestedAt  time.Time  `bson:"requested_at" json:"requested_at"`
+	DeleteAt     time.Time  `bson:"delete_at" json:"delete_at"`
+	ClaimedUntil *time.Time `bson:"claimed_until,omitempty" json:"-"`
+	CompletedAt  *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// AccountDeletionRequest asks for the member's password again before its
+// account is scheduled for deletion.
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+// DeletionGrace reads the grace period from ACCOUNT_DELETION_GRACE.
+func DeletionGrace() time.Duration {
+	grace, err := time.ParseDuration(os.Getenv("ACCOUNT_DELETION_GRACE"))
+	if err != nil || grace < 0 {
+		return DEFAULT_DELETION_GRACE
+	}
+	return grace
+}
+
+// PrepareAccountDeletion schedules the deletion of member and its profile
+// once grace has passed.
+func (deletion *AccountDeletion) PrepareAccountDeletion(member *Member, profile string, grace time.Duration) {
+	deletion.ID = util.ULID()
+	deletion.Member = member.ID
+	deletion.Profile = profile
+	deletion.Email = member.Email
+	deletion.Status = DELETION_PENDING
+	deletion.RequestedAt = util.GetTimeNow()
+	deletion.DeleteAt = deletion.RequestedAt.Add(grace)
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// AUDIT_REPORT_TRIAGE ...
+	AUDIT_REPORT_TRIAGE = "report.triage"
+	// AUDIT_REPORT_RESOLVE ...
+	AUDIT_REPORT_RESOLVE = "report.resolve"
+	// AUDIT_REVIEW_RESOLVE ...
+	AUDIT_REVIEW_RESOLVE = "review.resolve"
+	// AUDIT_MEMBER_SUSPEND ...
+	AUDIT_MEMBER_SUSPEND = "member.suspend"
+	// AUDIT_MEMBER_REINSTATE ...
+	AUDIT_MEMBER_REINSTATE = "member.reinstate"
+	// AUDIT_MEMBER_ACTIVATE ...
+	AUDIT_MEMBER_ACTIVATE = "member.activate"
+	// AUDIT_MEMBER_DEACTIVATE ...
+	AUDIT_MEMBER_DEACTIVATE = "member.deactivate"
+	// AUDIT_MEMBER_PASSWORD_RESET ...
+	AUDIT_MEMBER_PASSWORD_RESET = "member.password_reset"
+	// AUDIT_MEMBER_SESSIONS_REVOKE ...
+	AUDIT_MEMBER_SESSIONS_REVOKE = "member.sessions_revoke"
+	// AUDIT_MEMBER_ROLE ...
+	AUDIT_MEMBER_ROLE = "member.role"
+	// AUDIT_CHAT_IMPORT ...
+	AUDIT_CHAT_IMPORT = "chat.import"
+	// AUDIT_MESSAGE_DELETE ...
+	AUDIT_MESSAGE_DELETE = "message.delete"
+	// AUDIT_WEBHOOK_CREATE ...
+	AUDIT_WEBHOOK_CREATE = "webhook.create"
+	// AUDIT_WEBHOOK_UPDATE ...
+	AUDIT_WEBHOOK_UPDATE = "webhook.update"
+	// AUDIT_WEBHOOK_ROTATE ...
+	AUDIT_WEBHOOK_ROTATE = "webhook.rotate"
+	// AUDIT_WEBHOOK_DELETE ...
+	AUDIT_WEBHOOK_DELETE = "webhook.delete"
+	// AUDIT_BOT_CREATE ...
+	AUDIT_BOT_CREATE = "bot.create"
+	// AUDIT_BOT_UPDATE ...
+	AUDIT_BOT_UPDATE = "bot.update"
+	// AUDIT_BOT_TOKEN ...
+	AUDIT_BOT_TOKEN = "bot.token"
+	// AUDIT_BOT_DELETE ...
+	AUDIT_BOT_DELETE = "bot.delete"
+	// AUDIT_SERVICE_ACCOUNT_CREATE ...
+	AUDIT_SERVICE_ACCOUNT_CREATE = "service_account.create"
+	// AUDIT_SERVICE_ACCOUNT_TOKEN ...
+	AUDIT_SERVICE_ACCOUNT_TOKEN = "service_account.token"
+	// AUDIT_SERVICE_ACCOUNT_DELETE ...
+	AUDIT_SERVICE_ACCOUNT_DELETE = "service_account.delete"
+)
+
+// AuditLog records one action taken by a moderator or an admin.
+type AuditLog struct {
+	ID        string    `bson:"id" json:"id"`
+	Actor     string    `bson:"actor" json:"actor"`
+	Action    string    `bson:"action" json:"action"`
+	Target    string    `bson:"target" json:"target"`
+	Note      string    `bson:"note" json:"note"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// NewAuditLog ...
+func NewAuditLog(actor, action, target, note string) *AuditLog {
+	return &AuditLog{
+		ID:        util.ULID(),
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		Note:      note,
+		CreatedAt: util.GetTimeNow(),
+	}
+}
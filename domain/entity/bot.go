@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// BOT_TOKEN_PREFIX ...
+	BOT_TOKEN_PREFIX = "bot_"
+	// BOT_SESSION_KEY is the melody session key a bot socket keeps its
+	// profile id under.
+	BOT_SESSION_KEY = "bot"
+)
+
+// Bot is a profile driven by another service through an API token instead
+// of a signed in member. Its token is only stored hashed.
+type Bot struct {
+	ID        string    `bson:"id" json:"id"`
+	Member    string    `bson:"member" json:"member"`
+	Profile   string    `bson:"profile" json:"profile"`
+	TokenHash string    `bson:"token_hash" json:"-"`
+	TokenHint string    `bson:"token_hint" json:"token_hint"`
+	Webhook   string    `bson:"webhook,omitempty" json:"webhook,omitempty"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// BotRequest is what an admin sends to create or update a bot.
+type BotRequest struct {
+	DisplayName string `json:"display_name"`
+	NickName    string `json:"nick_name"`
+	WebhookURL  string `json:"webhook_url"`
+}
+
+// BotMessage is what a bot sends over HTTP or its socket.
+type BotMessage struct {
+	Receiver string `json:"receiver"`
+	Message  string `json:"message"`
+}
+
+// ValidateBotRequest ...
+func (request *BotRequest) ValidateBotRequest() error {
+	if strings.TrimSpace(request.DisplayName) == "" {
+		return util.GetError("invalid_display_name")
+	}
+	if request.WebhookURL != "" {
+		link, err := url.Parse(request.WebhookURL)
+		if err != nil || link.Scheme != "https" || link.Host == "" {
+			return util.GetError("invalid_webhook_url")
+		}
+	}
+	return nil
+}
+
+// PrepareBot creates the member and profile the bot posts as, and returns
+// the plain API token. The token cannot be recovered later.
+func (bot *Bot) PrepareBot(admin string, request *BotRequest, member *Member, profile *MemberProfile) string {
+	bot.ID = util.ULID()
+	bot.CreatedBy = admin
+	bot.CreatedAt = util.GetTimeNow()
+	bot.UpdatedAt = bot.CreatedAt
+
+	member.ID = util.ULID()
+	member.Email = fmt.Sprintf("%s@bot.invalid", member.ID)
+	member.Active = true
+	member.Verified = true
+	member.Role = ROLE_BOT
+	member.CreatedAt = bot.CreatedAt
+	member.UpdateAt = bot.CreatedAt
+	bot.Member = member.ID
+
+	profile.ID = util.ULID()
+	profile.Member = member.ID
+	profile.DisplayName = strings.TrimSpace(request.DisplayName)
+	profile.NickName = strings.ToLower(request.NickName)
+	if profile.NickName == "" {
+		profile.NickName = "bot_" + strings.ToLower(profile.ID[len(profile.ID)-8:])
+	}
+	profile.ProfileImage = os.Getenv("DEFAULT_PROFILE_PIC")
+	profile.Bot = true
+	profile.CreatedAt = bot.CreatedAt
+	bot.Profile = profile.ID
+
+	return bot.NewToken()
+}
+
+// NewToken replaces the bot's token and returns the plain value.
+func (bot *Bot) NewToken() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	token := BOT_TOKEN_PREFIX + hex.EncodeToString(secret)
+	bot.TokenHash = HashBotToken(token)
+	bot.TokenHint = token[len(token)-4:]
+	bot.UpdatedAt = util.GetTimeNow()
+	return token
+}
+
+// HashBotToken is how bot tokens are stored and looked up.
+func HashBotToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// BotSessionKey is the AppConfig session key of the bot's socket. It starts
+// with the profile id so CloseProfileSessions reaches it.
+func BotSessionKey(profile string) string {
+	return fmt.Sprintf("%s-%s", profile, BOT_SESSION_KEY)
+}
@@ -4,35 +4,208 @@ import (
 	"time"
 
 	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
 )
 
-// ChatMessage ...
+const (
+	// SYNC_PAGE_SIZE is how many messages a sync returns without a limit.
+	SYNC_PAGE_SIZE = 50
+	// MAX_SYNC_PAGE_SIZE ...
+	MAX_SYNC_PAGE_SIZE = 500
+
+	// MESSAGE_TEXT ...
+	MESSAGE_TEXT = "text"
+	// MESSAGE_VOICE ...
+	MESSAGE_VOICE = "voice"
+	// MESSAGE_POLL ...
+	MESSAGE_POLL = "poll"
+
+	// EVENT_MESSAGE_PREVIEW ...
+	EVENT_MESSAGE_PREVIEW = "message.preview"
+	// EVENT_MESSAGE_PLAYED ...
+	EVENT_MESSAGE_PLAYED = "message.played"
+	// EVENT_MESSAGE_READ ...
+	EVENT_MESSAGE_READ = "message.read"
+	// EVENT_MESSAGE_TRANSLATED ...
+	EVENT_MESSAGE_TRANSLATED = "message.translated"
+	// EVENT_POLL_UPDATED carries a poll's new tallies, or its final results
+	// once it is closed.
+	EVENT_POLL_UPDATED = "poll.updated"
+	// EVENT_MESSAGE_PINNED ...
+	EVENT_MESSAGE_PINNED = "message.pinned"
+	// EVENT_MESSAGE_UNPINNED ...
+	EVENT_MESSAGE_UNPINNED = "message.unpinned"
+	// EVENT_MESSAGE_REACTION ...
+	EVENT_MESSAGE_REACTION = "message.reaction"
+	// EVENT_MESSAGE_EDITED ...
+	EVENT_MESSAGE_EDITED = "message.edited"
+	// EVENT_MESSAGE_DELETED ...
+	EVENT_MESSAGE_DELETED = "message.deleted"
+	// EVENT_BATCH carries frames held back while the client was slow to read.
+	EVENT_BATCH = "batch"
+	// EVENT_MESSAGE_ACK tells the sender which id its message was stored
+	// under.
+	EVENT_MESSAGE_ACK = "message.ack"
+	// EVENT_ERROR tells the client a frame it sent was rejected.
+	EVENT_ERROR = "error"
+	// EVENT_COMMAND_REPLY is a command's answer, shown to its sender only.
+	EVENT_COMMAND_REPLY = "command.reply"
+)
+
+// ChatMessage is stored once per send, in its conversation, where Seq
+// orders it: the server gives every message of a conversation the next
+// number, so a client that sees a gap knows it missed messages. ChatId and
+// Translation are not stored: they are filled in by ViewFor for whoever reads
+// the message, from Translations for the translation.
 type ChatMessage struct {
-	ID        string    `bson:"id" json:"id"`
-	ChatId    string    `bson:"chat_id" json:"chat_id"`
-	Sender    string    `bson:"sender" json:"sender"`
-	Receiver  string    `bson:"receiver" json:"receiver"`
-	Message   string    `bson:"message" json:"message"`
-	CreatedAt time.Time `bson:"created_at" json:"created_at,omitempty"`
+	ID           string                         `bson:"id" json:"id"`
+	Ref          string                         `bson:"ref" json:"ref"`
+	ClientMsgId  string                         `bson:"client_msg_id,omitempty" json:"client_msg_id,omitempty"`
+	Conversation string                         `bson:"conversation" json:"-"`
+	Seq          int64                          `bson:"seq" json:"seq"`
+	ChatId       string                         `bson:"-" json:"chat_id"`
+	Type         string                         `bson:"type" json:"type"`
+	Sender       string                         `bson:"sender" json:"sender"`
+	Receiver     string                         `bson:"receiver" json:"receiver"`
+	Message      string                         `bson:"message" json:"message"`
+	Attachment   *Attachment                    `bson:"attachment,omitempty" json:"attachment,omitempty"`
+	Preview      *linkpreview.Preview           `bson:"preview,omitempty" json:"preview,omitempty"`
+	Translation  *MessageTranslation            `bson:"-" json:"translation,omitempty"`
+	Translations map[string]*MessageTranslation `bson:"translations,omitempty" json:"-"`
+	Poll         *Poll                          `bson:"poll,omitempty" json:"poll,omitempty"`
+	Reactions    map[string]string              `bson:"reactions,omitempty" json:"reactions,omitempty"`
+	Pinned       bool                           `bson:"-" json:"pinned,omitempty"`
+	Starred      bool                           `bson:"-" json:"starred,omitempty"`
+	HiddenFor    []string                       `bson:"hidden_for,omitempty" json:"-"`
+	PlayedAt     *time.Time                     `bson:"played_at,omitempty" json:"played_at,omitempty"`
+	EditedAt     *time.Time                     `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	CreatedAt    time.Time                      `bson:"created_at" json:"created_at,omitempty"`
+}
+
+// Attachment ...
+type Attachment struct {
+	URL      string `bson:"url" json:"url"`
+	MimeType string `bson:"mime_type" json:"mime_type"`
+	Size     int64  `bson:"size" json:"size"`
+	Duration int64  `bson:"duration" json:"duration"`
+	Waveform []int  `bson:"waveform" json:"waveform"`
+}
+
+// ChatEvent is pushed over the websocket for anything that is not a plain
+// batch of messages, e.g. a preview attached to an already delivered message.
+type ChatEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// ChatMessagePreview ...
+type ChatMessagePreview struct {
+	Ref     string               `json:"ref"`
+	Preview *linkpreview.Preview `json:"preview"`
 }
 
-// ChatRoom ...
+// ChatRoom is a conversation as seen by one of its participants.
 type ChatRoom struct {
-	ID        string    `bson:"id" json:"id"`
-	Sender    string    `bson:"sender" json:"sender"`
-	Receiver  []string  `bson:"receiver" json:"receiver"`
-	Message   string    `bson:"message" json:"message"`
-	IsRead    bool      `bson:"is_read" json:"is_read"`
-	CreatedAt time.Time `bson:"created_at" json:"created_at,omitempty"`
+	ID          string     `bson:"id" json:"id"`
+	Sender      string     `bson:"sender" json:"sender"`
+	Receiver    []string   `bson:"receiver" json:"receiver"`
+	Message     string     `bson:"message" json:"message"`
+	Seq         int64      `bson:"seq" json:"seq"`
+	IsRead      bool       `bson:"is_read" json:"is_read"`
+	UnreadCount int64      `bson:"unread_count" json:"unread_count"`
+	LastReadId  string     `bson:"last_read_id" json:"last_read_id"`
+	LastReadSeq int64      `bson:"last_read_seq" json:"last_read_seq"`
+	Muted       bool       `bson:"muted" json:"muted"`
+	MutedUntil  *time.Time `bson:"muted_until,omitempty" json:"muted_until,omitempty"`
+	TranslateTo string     `bson:"translate_to,omitempty" json:"translate_to,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at,omitempty"`
 }
 
 type RetrieveChatRoom struct {
-	ID        string          `bson:"id" json:"id"`
-	Sender    string          `bson:"sender" json:"sender"`
-	Receiver  []MemberProfile `bson:"receiver" json:"receiver"`
-	Message   string          `bson:"message" json:"message"`
-	IsRead    bool            `bson:"is_read" json:"is_read"`
-	CreatedAt time.Time       `bson:"created_at" json:"created_at,omitempty"`
+	ID          string          `bson:"id" json:"id"`
+	Sender      string          `bson:"sender" json:"sender"`
+	Receiver    []MemberProfile `bson:"receiver" json:"receiver"`
+	Message     string          `bson:"message" json:"message"`
+	Seq         int64           `bson:"seq" json:"seq"`
+	IsRead      bool            `bson:"is_read" json:"is_read"`
+	UnreadCount int64           `bson:"unread_count" json:"unread_count"`
+	LastReadId  string          `bson:"last_read_id" json:"last_read_id"`
+	LastReadSeq int64           `bson:"last_read_seq" json:"last_read_seq"`
+	Muted       bool            `bson:"muted" json:"muted"`
+	MutedUntil  *time.Time      `bson:"muted_until,omitempty" json:"muted_until,omitempty"`
+	TranslateTo string          `bson:"translate_to,omitempty" json:"translate_to,omitempty"`
+	CreatedAt   time.Time       `bson:"created_at" json:"created_at,omitempty"`
+}
+
+// ChatCounter ...
+type ChatCounter struct {
+	UnreadMessages int64 `bson:"unread_messages" json:"unread_messages"`
+	UnreadChats    int64 `bson:"unread_chats" json:"unread_chats"`
+}
+
+// ChatListSummary is what the chat list stream sends on every tick.
+type ChatListSummary struct {
+	Chats   ChatList    `json:"chats"`
+	Counter ChatCounter `json:"counter"`
+}
+
+// ChatMessageRead tells that Reader read every message up to Seq.
+type ChatMessageRead struct {
+	Reader      string    `json:"reader"`
+	Ref         string    `json:"ref"`
+	Seq         int64     `json:"seq"`
+	UnreadCount int64     `json:"unread_count"`
+	ReadAt      time.Time `json:"read_at"`
+}
+
+// ChatMessageAck maps the id a client gave its message to the server's.
+// Duplicate is set when the message had already been received.
+type ChatMessageAck struct {
+	ClientMsgId string    `json:"client_msg_id"`
+	ID          string    `json:"id"`
+	Seq         int64     `json:"seq"`
+	CreatedAt   time.Time `json:"created_at"`
+	Duplicate   bool      `json:"duplicate"`
+}
+
+// ChatSync is a range of a conversation by seq. Seq is the latest seq of
+// the conversation and HasMore is set when the range was cut at the limit.
+type ChatSync struct {
+	Messages ChatMessageHistory `json:"messages"`
+	Seq      int64              `json:"seq"`
+	HasMore  bool               `json:"has_more"`
+}
+
+// ChatMessageEdited carries the new text of an edited message.
+type ChatMessageEdited struct {
+	Ref      string    `json:"ref"`
+	Seq      int64     `json:"seq"`
+	Message  string    `json:"message"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// ChatMessageDeleted ...
+type ChatMessageDeleted struct {
+	Ref string `json:"ref"`
+}
+
+// CommandReply ...
+type CommandReply struct {
+	Command string `json:"command"`
+	Text    string `json:"text"`
+}
+
+// ChatMessagePlayed ...
+type ChatMessagePlayed struct {
+	Ref      string    `json:"ref"`
+	PlayedAt time.Time `json:"played_at"`
+}
+
+// ChatError ...
+type ChatError struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	RetryAfter int64  `json:"retry_after"`
 }
 
 // ChatMessageHistory ...
@@ -41,14 +214,63 @@ type ChatMessageHistory []ChatMessage
 // ChatList ...
 type ChatList []RetrieveChatRoom
 
-// PrepareChatMessage ...
+// NewChatEvent ...
+func NewChatEvent(eventType string, data interface{}) ChatEvent {
+	return ChatEvent{
+		Type: eventType,
+		Data: data,
+	}
+}
+
+// NewChatBatch wraps frames held back for a slow client into one event.
+func NewChatBatch(frames []interface{}) interface{} {
+	return NewChatEvent(EVENT_BATCH, frames)
+}
+
+// PrepareChatMessage gives the message its id, which is also its ref, and
+// puts it in the conversation of its sender and receiver.
 func (chat *ChatMessage) PrepareChatMessage() {
 	chat.ID = util.ULID()
+	chat.Ref = chat.ID
+	chat.Conversation = ConversationKey(chat.Sender, chat.Receiver)
 	chat.CreatedAt = util.GetTimeNow()
 }
 
-// PrepareChatRoom ...
-func (room *ChatRoom) PrepareChatRoom() {
-	room.ID = util.ULID()
-	room.CreatedAt = util.GetTimeNow()
+// ValidateClientMsgId ...
+func (chat *ChatMessage) ValidateClientMsgId() error {
+	if len(chat.ClientMsgId) > 64 {
+		return util.GetError("invalid_client_msg_id")
+	}
+	return nil
+}
+
+// PrepareVoiceMessage ...
+func (chat *ChatMessage) PrepareVoiceMessage(sender, receiver string, attachment *Attachment) {
+	chat.Type = MESSAGE_VOICE
+	chat.Sender = sender
+	chat.Receiver = receiver
+	chat.Message = ""
+	chat.Attachment = attachment
+	chat.PlayedAt = nil
+}
+
+// PreparePollMessage ...
+func (chat *ChatMessage) PreparePollMessage(sender, receiver string, poll *Poll) {
+	chat.Type = MESSAGE_POLL
+	chat.Sender = sender
+	chat.Receiver = receiver
+	chat.Message = poll.Question
+	chat.Attachment = nil
+	chat.Poll = poll
+}
+
+// RoomMessage is the text shown for the message in the chat list.
+func (chat *ChatMessage) RoomMessage() string {
+	switch chat.Type {
+	case MESSAGE_VOICE:
+		return "🎤"
+	case MESSAGE_POLL:
+		return "📊 " + chat.Message
+	}
+	return chat.Message
 }
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// EXPORT_FORMAT_JSON ...
+	EXPORT_FORMAT_JSON = "json"
+	// EXPORT_FORMAT_TEXT ...
+	EXPORT_FORMAT_TEXT = "text"
+	// EXPORT_FORMAT_HTML ...
+	EXPORT_FORMAT_HTML = "html"
+
+	// EXPORT_PENDING ...
+	EXPORT_PENDING = "pending"
+	// EXPORT_READY ...
+	EXPORT_READY = "ready"
+	// EXPORT_FAILED ...
+	EXPORT_FAILED = "failed"
+	// EXPORT_EXPIRED is a ready export whose file was removed once it
+	// expired.
+	EXPORT_EXPIRED = "expired"
+
+	// EXPORT_LEASE is how long a replica holds a pending export without
+	// renewing it. An export still pending after that, because its replica
+	// stopped, is queued again.
+	EXPORT_LEASE = 5 * time.Minute
+
+	// DEFAULT_EXPORT_TTL is how long an export can be downloaded when
+	// CHAT_EXPORT_TTL is not set.
+	DEFAULT_EXPORT_TTL = 24 * time.Hour
+)
+
+// ExportFileExtension ...
+var ExportFileExtension = map[string]string{
+	EXPORT_FORMAT_JSON: ".json",
+	EXPORT_FORMAT_TEXT: ".txt",
+	EXPORT_FORMAT_HTML: ".html",
+}
+
+// ExportContentType ...
+var ExportContentType = map[string]string{
+	EXPORT_FORMAT_JSON: "application/json",
+	EXPORT_FORMAT_TEXT: "text/plain; charset=utf-8",
+	EXPORT_FORMAT_HTML: "text/html; charset=utf-8",
+}
+
+// ChatExport ...
+type ChatExport struct {
+	ID           string     `bson:"id" json:"id"`
+	Profile      string     `bson:"profile" json:"profile"`
+	Receiver     string     `bson:"receiver" json:"receiver"`
+	ChatId       string     `bson:"chat_id" json:"chat_id"`
+	Format       string     `bson:"format" json:"format"`
+	Status       string     `bson:"status" json:"status"`
+	File         string     `bson:"file" json:"-"`
+	Messages     int64      `bson:"messages" json:"messages"`
+	URL          string     `bson:"-" json:"url,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+	CompletedAt  *time.Time `bson:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	ClaimedUntil *time.Time `bson:"claimed_until,omitempty" json:"-"`
+}
+
+// ExportTTL reads how long an export can be downloaded from CHAT_EXPORT_TTL.
+func ExportTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("CHAT_EXPORT_TTL"))
+	if err != nil || ttl <= 0 {
+		return DEFAULT_EXPORT_TTL
+	}
+	return ttl
+}
+
+// PrepareChatExport ...
+func (export *ChatExport) PrepareChatExport(profile, receiver, format string) {
+	export.ID = util.ULID()
+	export.Profile = profile
+	export.Receiver = receiver
+	export.ChatId = fmt.Sprintf("%s-%s", profile, receiver)
+	export.Format = format
+	export.Status = EXPORT_PENDING
+	export.CreatedAt = util.GetTimeNow()
+	claimedUntil := export.CreatedAt.Add(EXPORT_LEASE)
+	export.ClaimedUntil = &claimedUntil
+}
+
+// FilePath is where the export is written, under EXPORTS.
+func (export *ChatExport) FilePath() string {
+	return filepath.Join(os.Getenv("EXPORTS"), export.ID+ExportFileExtension[export.Format])
+}
+
+// ValidateChatExport ...
+func (export *ChatExport) ValidateChatExport() error {
+	if _, ok := ExportFileExtension[export.Format]; !ok {
+		return util.GetError("invalid_export_format")
+	}
+	if len(util.GetURLIds(export.Receiver)) != 1 {
+		return util.GetError("profile_not_found")
+	}
+	return nil
+}
+
+// SetDownloadURL ...
+func (export *ChatExport) SetDownloadURL() {
+	if export.Status == EXPORT_READY {
+		export.URL = fmt.Sprintf("/api/v1/chat-export/%s/download", export.ID)
+	}
+}
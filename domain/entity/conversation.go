@@ -0,0 +1,256 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// Conversation is the one document shared by both participants of a chat.
+// Its id is the ConversationKey of the pair, and everything that differs
+// between the two sides lives in Members. Seq is the last sequence number
+// given to one of its messages.
+type Conversation struct {
+	ID             string               `bson:"id" json:"id"`
+	Participants   []string             `bson:"participants" json:"participants"`
+	Members        []ConversationMember `bson:"members" json:"members"`
+	Seq            int64                `bson:"seq" json:"seq"`
+	LastMessageId  string               `bson:"last_message_id" json:"last_message_id"`
+	LastMessageSeq int64                `bson:"last_message_seq" json:"last_message_seq"`
+	LastMessage    string               `bson:"last_message" json:"last_message"`
+	LastSender     string               `bson:"last_sender" json:"last_sender"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// ConversationMember is one participant's state in a conversation. The
+// member has read every message up to LastReadSeq. Hidden takes the
+// conversation off the member's chat list until the next message, and
+// messages up to ClearedSeq are left out of the member's history.
+type ConversationMember struct {
+	Profile     string     `bson:"profile" json:"profile"`
+	LastReadId  string     `bson:"last_read_id" json:"last_read_id"`
+	LastReadSeq int64      `bson:"last_read_seq" json:"last_read_seq"`
+	UnreadCount int64      `bson:"unread_count" json:"unread_count"`
+	Muted       bool       `bson:"muted" json:"muted"`
+	MutedUntil  *time.Time `bson:"muted_until,omitempty" json:"muted_until,omitempty"`
+	TranslateTo string     `bson:"translate_to,omitempty" json:"translate_to,omitempty"`
+	Hidden      bool       `bson:"hidden" json:"hidden"`
+	ClearedSeq  int64      `bson:"cleared_seq,omitempty" json:"cleared_seq,omitempty"`
+}
+
+// ChatParticipants splits a chat id into the profile it belongs to and the
+// other participant.
+func ChatParticipants(chatId string) (string, string) {
+	profile, other, _ := strings.Cut(chatId, "-")
+	return profile, other
+}
+
+// PrepareConversation ...
+func (conversation *Conversation) PrepareConversation(profile, other string) {
+	conversation.ID = ConversationKey(profile, other)
+	conversation.Participants = strings.Split(conversation.ID, "-")
+	conversation.Members = []ConversationMember{
+		{Profile: conversation.Participants[0]},
+		{Profile: conversation.Participants[1]},
+	}
+	conversation.CreatedAt = util.GetTimeNow()
+	conversation.UpdatedAt = conversation.CreatedAt
+}
+
+// Member returns profile's state in the conversation, or nil when profile
+// does not take part in it.
+func (conversation *Conversation) Member(profile string) *ConversationMember {
+	for index := range conversation.Members {
+		if conversation.Members[index].Profile == profile {
+			return &conversation.Members[index]
+		}
+	}
+	return nil
+}
+
+// Other returns the participant that is not profile.
+func (conversation *Conversation) Other(profile string) string {
+	for _, participant := range conversation.Participants {
+		if participant != profile {
+			return participant
+		}
+	}
+	return profile
+}
+
+// ChatRoom is the conversation as seen by profile.
+func (conversation *Conversation) ChatRoom(profile string) *ChatRoom {
+	room := &ChatRoom{
+		ID:        conversation.ID,
+		Sender:    profile,
+		Receiver:  []string{conversation.Other(profile)},
+		Message:   conversation.LastMessage,
+		Seq:       conversation.Seq,
+		IsRead:    true,
+		CreatedAt: conversation.UpdatedAt,
+	}
+	if member := conversation.Member(profile); member != nil {
+		room.IsRead = member.UnreadCount == 0
+		room.UnreadCount = member.UnreadCount
+		room.LastReadId = member.LastReadId
+		room.LastReadSeq = member.LastReadSeq
+		room.Muted = member.Muted
+		room.MutedUntil = member.MutedUntil
+		room.TranslateTo = member.TranslateTo
+	}
+	return room
+}
+
+// MessageTopic is the events topic of the messages profile sends and
+// receives, each as profile sees it.
+func MessageTopic(profile string) string {
+	return fmt.Sprintf("message:%s", profile)
+}
+
+// MESSAGES_TOPIC is the events topic of every new message, as its sender
+// sees it.
+const MESSAGES_TOPIC = "messages"
+
+// ChatListTopic is the events topic of changes to profile's chat list. Its
+// events are the ids of the conversations that changed.
+func ChatListTopic(profile string) string {
+	return fmt.Sprintf("chat-list:%s", profile)
+}
+
+// ViewFor fills in the fields of a stored message that depend on who reads
+// it: the reader's chat id and the reader's translation.
+func (chat *ChatMessage) ViewFor(profile string) {
+	other := chat.Receiver
+	if profile == chat.Receiver {
+		other = chat.Sender
+	}
+	chat.ChatId = fmt.Sprintf("%s-%s", profile, other)
+	chat.Translation = chat.Translations[profile]
+}
+
+const (
+	// CONVERSATION_PAGE_SIZE is how many items a v2 list returns without a
+	// limit.
+	CONVERSATION_PAGE_SIZE = 50
+	// MAX_CONVERSATION_PAGE_SIZE ...
+	MAX_CONVERSATION_PAGE_SIZE = 100
+)
+
+// ConversationResource is a conversation as the v2 API shows it to one of
+// its participants. Mute and auto-translate are that participant's own.
+type ConversationResource struct {
+	ID            string     `json:"id"`
+	Participants  []string   `json:"participants"`
+	Seq           int64      `json:"seq"`
+	LastMessageId string     `json:"last_message_id,omitempty"`
+	LastMessage   string     `json:"last_message"`
+	LastSender    string     `json:"last_sender,omitempty"`
+	UnreadCount   int64      `json:"unread_count"`
+	LastReadSeq   int64      `json:"last_read_seq"`
+	Muted         bool       `json:"muted"`
+	MutedUntil    *time.Time `json:"muted_until,omitempty"`
+	TranslateTo   string     `json:"translate_to,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ConversationParticipant is a participant as the other one sees it.
+type ConversationParticipant struct {
+	Profile     MemberProfile `json:"profile"`
+	LastReadSeq int64         `json:"last_read_seq"`
+}
+
+// ConversationRequest starts a conversation with Participant, a profile id.
+type ConversationRequest struct {
+	Participant string `json:"participant"`
+}
+
+// UpdateConversationRequest changes the member's own settings for a
+// conversation. Fields left out are not changed; a muted_until without muted
+// mutes until then.
+type UpdateConversationRequest struct {
+	Muted       *bool      `json:"muted"`
+	MutedUntil  *time.Time `json:"muted_until"`
+	TranslateTo *string    `json:"translate_to"`
+}
+
+// ConversationMessageRequest sends or edits a text message over REST.
+type ConversationMessageRequest struct {
+	Message     string `json:"message"`
+	ClientMsgId string `json:"client_msg_id"`
+}
+
+// ConversationCursor points after conversation in a list sorted by latest
+// activity.
+func ConversationCursor(conversation *Conversation) string {
+	value := fmt.Sprintf("%d|%s", conversation.UpdatedAt.UnixNano(), conversation.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// ParseConversationCursor reads a cursor made by ConversationCursor.
+func ParseConversationCursor(cursor string) (time.Time, string, error) {
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", util.GetError("invalid_cursor")
+	}
+	at, ID, found := strings.Cut(string(value), "|")
+	nanos, err := strconv.ParseInt(at, 10, 64)
+	if !found || err != nil || ID == "" {
+		return time.Time{}, "", util.GetError("invalid_cursor")
+	}
+	return time.Unix(0, nanos).UTC(), ID, nil
+}
+
+// ConversationOther checks that profile takes part in the conversation with
+// ID and returns the other participant.
+func ConversationOther(ID, profile string) (string, error) {
+	first, second := ChatParticipants(ID)
+	if first == "" || second == "" || ConversationKey(first, second) != ID {
+		return "", util.GetError("chat_not_found")
+	}
+	switch profile {
+	case first:
+		return second, nil
+	case second:
+		return first, nil
+	}
+	return "", util.GetError("chat_not_found")
+}
+
+// ValidateUpdateConversationRequest ...
+func (request *UpdateConversationRequest) ValidateUpdateConversationRequest() error {
+	if request.MutedUntil != nil && !request.MutedUntil.After(util.GetTimeNow()) {
+		return util.GetError("invalid_mute_until")
+	}
+	if request.MutedUntil != nil && request.Muted != nil && !*request.Muted {
+		return util.GetError("invalid_mute_until")
+	}
+	return nil
+}
+
+// Resource is the conversation as profile sees it through the v2 API.
+func (conversation *Conversation) Resource(profile string) *ConversationResource {
+	resource := &ConversationResource{
+		ID:            conversation.ID,
+		Participants:  conversation.Participants,
+		Seq:           conversation.Seq,
+		LastMessageId: conversation.LastMessageId,
+		LastMessage:   conversation.LastMessage,
+		LastSender:    conversation.LastSender,
+		CreatedAt:     conversation.CreatedAt,
+		UpdatedAt:     conversation.UpdatedAt,
+	}
+	if member := conversation.Member(profile); member != nil {
+		resource.UnreadCount = member.UnreadCount
+		resource.LastReadSeq = member.LastReadSeq
+		resource.Muted = member.Muted
+		resource.MutedUntil = member.MutedUntil
+		resource.TranslateTo = member.TranslateTo
+	}
+	return resource
+}
@@ -1,31 +1,61 @@
 package entity
 
 import (
+	"sort"
 	"time"
 
 	"github.com/majid-cj/go-chat-server/util"
 	"github.com/majid-cj/go-chat-server/util/security"
 )
 
+const (
+	// ROLE_MEMBER ...
+	ROLE_MEMBER = "member"
+	// ROLE_SUPPORT ...
+	ROLE_SUPPORT = "support"
+	// ROLE_MODERATOR ...
+	ROLE_MODERATOR = "moderator"
+	// ROLE_ADMIN ...
+	ROLE_ADMIN = "admin"
+	// ROLE_BOT ...
+	ROLE_BOT = "bot"
+)
+
 // Member ...
 type Member struct {
-	ID        string            `bson:"id" json:"id"`
-	Email     string            `bson:"email" json:"email"`
-	Password  security.PassHash `bson:"password" json:"password"`
-	Verified  bool              `bson:"verified" json:"verified"`
-	Active    bool              `bson:"active" json:"active"`
-	CreatedAt time.Time         `bson:"created_at"`
-	UpdateAt  time.Time         `bson:"update_at"`
+	ID            string            `bson:"id" json:"id"`
+	Email         string            `bson:"email" json:"email"`
+	Password      security.PassHash `bson:"password" json:"password"`
+	Verified      bool              `bson:"verified" json:"verified"`
+	Active        bool              `bson:"active" json:"active"`
+	Role          string            `bson:"role" json:"role"`
+	Permissions   []string          `bson:"permissions,omitempty" json:"permissions,omitempty"`
+	PasswordReset bool              `bson:"password_reset,omitempty" json:"password_reset,omitempty"`
+	CreatedAt     time.Time         `bson:"created_at"`
+	UpdateAt      time.Time         `bson:"update_at"`
 }
 
 // MemberSerializer ...
 type MemberSerializer struct {
-	ID        string `json:"id"`
-	Type      uint8  `json:"member_type"`
-	Email     string `json:"email"`
-	Verified  bool   `json:"verified"`
-	Active    bool   `json:"active"`
-	CreatedAt string `json:"created_at"`
+	ID            string   `json:"id"`
+	Type          uint8    `json:"member_type"`
+	Email         string   `json:"email"`
+	Verified      bool     `json:"verified"`
+	Active        bool     `json:"active"`
+	Role          string   `json:"role"`
+	Permissions   []string `json:"permissions,omitempty"`
+	PasswordReset bool     `json:"password_reset,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// MemberSearch ...
+type MemberSearch struct {
+	// Query matches part of the email or the whole id.
+	Query string
+	// Members are ids found some other way, e.g. by nick name.
+	Members []string
+	Role    string
+	Active  *bool
 }
 
 // Members ...
@@ -36,6 +66,7 @@ func (m *Member) PrepareMember() {
 	m.ID = util.ULID()
 	m.Email = util.EscapeString(m.Email)
 	m.Active = true
+	m.Role = ROLE_MEMBER
 	m.Verified = false
 	m.CreatedAt = util.GetTimeNow()
 	m.UpdateAt = util.GetTimeNow()
@@ -46,19 +77,100 @@ func (m *Member) PrepareSocialMember() {
 	m.ID = util.ULID()
 	m.Email = util.EscapeString(m.Email)
 	m.Active = true
+	m.Role = ROLE_MEMBER
 	m.Verified = true
 	m.CreatedAt = util.GetTimeNow()
 	m.UpdateAt = util.GetTimeNow()
 }
 
+// IsStaff is true for any member with a permission, whatever its role.
+func (m Member) IsStaff() bool {
+	return len(m.GetPermissions()) > 0
+}
+
+// GetPermissions returns the permissions of the member's role together with
+// the ones it was granted, sorted.
+func (m Member) GetPermissions() []string {
+	if m.IsAdmin() {
+		permissions := make([]string, 0, len(Permissions))
+		for permission := range Permissions {
+			permissions = append(permissions, permission)
+		}
+		sort.Strings(permissions)
+		return permissions
+	}
+	granted := map[string]bool{}
+	for _, permission := range RolePermissions[m.Role] {
+		granted[permission] = true
+	}
+	for _, permission := range m.Permissions {
+		granted[permission] = true
+	}
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// Can ...
+func (m Member) Can(permission string) bool {
+	for _, granted := range m.GetPermissions() {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage tells whether m may act on other from the admin API. Only admins
+// act on staff.
+func (m Member) CanManage(other Member) bool {
+	return m.IsAdmin() || !other.IsStaff()
+}
+
+// CanGrant tells whether m holds everything request would give, so staff
+// cannot hand out more than they have. Only admins make other admins.
+func (m Member) CanGrant(request MemberRoleRequest) bool {
+	granted := Member{Role: request.Role, Permissions: request.Permissions}
+	if granted.IsAdmin() {
+		return m.IsAdmin()
+	}
+	for _, permission := range granted.GetPermissions() {
+		if !m.Can(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsModerator ...
+func (m Member) IsModerator() bool {
+	return m.Role == ROLE_MODERATOR || m.Role == ROLE_ADMIN
+}
+
+// IsAdmin ...
+func (m Member) IsAdmin() bool {
+	return m.Role == ROLE_ADMIN
+}
+
+// IsBot ...
+func (m Member) IsBot() bool {
+	return m.Role == ROLE_BOT
+}
+
 // GetMemberSerializer ...
 func (m Member) GetMemberSerializer() MemberSerializer {
 	return MemberSerializer{
-		ID:        m.ID,
-		Email:     m.Email,
-		Verified:  m.Verified,
-		Active:    m.Active,
-		CreatedAt: m.CreatedAt.String(),
+		ID:            m.ID,
+		Email:         m.Email,
+		Verified:      m.Verified,
+		Active:        m.Active,
+		Role:          m.Role,
+		Permissions:   m.GetPermissions(),
+		PasswordReset: m.PasswordReset,
+		CreatedAt:     m.CreatedAt.String(),
 	}
 }
 
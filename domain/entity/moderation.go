@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// REVIEW_PENDING ...
+	REVIEW_PENDING = "pending"
+)
+
+// ModerationReason records which filter acted on a message and why.
+type ModerationReason struct {
+	Filter  string `bson:"filter" json:"filter"`
+	Verdict string `bson:"verdict" json:"verdict"`
+	Reason  string `bson:"reason" json:"reason"`
+}
+
+// ModerationReview is a flagged message waiting for a moderator.
+type ModerationReview struct {
+	ID         string             `bson:"id" json:"id"`
+	Ref        string             `bson:"ref" json:"ref"`
+	Sender     string             `bson:"sender" json:"sender"`
+	Receiver   string             `bson:"receiver" json:"receiver"`
+	Message    string             `bson:"message" json:"message"`
+	Original   string             `bson:"original" json:"original"`
+	Reasons    []ModerationReason `bson:"reasons" json:"reasons"`
+	Status     string             `bson:"status" json:"status"`
+	Resolution *Resolution        `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// PrepareModerationReview ...
+func (review *ModerationReview) PrepareModerationReview(message *ChatMessage, original string, reasons []ModerationReason) {
+	review.ID = util.ULID()
+	review.Ref = message.Ref
+	review.Sender = message.Sender
+	review.Receiver = message.Receiver
+	review.Message = message.Message
+	review.Original = original
+	review.Reasons = reasons
+	review.Status = REVIEW_PENDING
+	review.CreatedAt = util.GetTimeNow()
+}
+
+// Resolve ...
+func (review *ModerationReview) Resolve(moderator, action, note string) {
+	review.Status = REPORT_RESOLVED
+	if action == ACTION_DISMISS {
+		review.Status = REPORT_DISMISSED
+	}
+	review.Resolution = &Resolution{
+		Action:     action,
+		Note:       note,
+		Moderator:  moderator,
+		ResolvedAt: util.GetTimeNow(),
+	}
+}
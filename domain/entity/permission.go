@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"sort"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// PERMISSION_MODERATE covers reports, reviews and suspending members.
+	PERMISSION_MODERATE = "moderate"
+	// PERMISSION_AUDIT_LOG ...
+	PERMISSION_AUDIT_LOG = "audit_log.read"
+	// PERMISSION_MEMBERS_READ ...
+	PERMISSION_MEMBERS_READ = "members.read"
+	// PERMISSION_MEMBERS_ACTIVE covers activating and deactivating members.
+	PERMISSION_MEMBERS_ACTIVE = "members.active"
+	// PERMISSION_MEMBERS_PASSWORD ...
+	PERMISSION_MEMBERS_PASSWORD = "members.password"
+	// PERMISSION_MEMBERS_SESSIONS ...
+	PERMISSION_MEMBERS_SESSIONS = "members.sessions"
+	// PERMISSION_MEMBERS_ROLES covers changing the role and permissions of
+	// other members.
+	PERMISSION_MEMBERS_ROLES = "members.roles"
+	// PERMISSION_INTEGRATIONS covers webhooks, bots and service accounts.
+	PERMISSION_INTEGRATIONS = "integrations"
+	// PERMISSION_CHAT_IMPORT covers importing chat history on behalf of both
+	// participants.
+	PERMISSION_CHAT_IMPORT = "chat.import"
+	// PERMISSION_METRICS covers the runtime and websocket counters.
+	PERMISSION_METRICS = "metrics.read"
+)
+
+// Permissions ...
+var Permissions = map[string]bool{
+	PERMISSION_MODERATE:         true,
+	PERMISSION_AUDIT_LOG:        true,
+	PERMISSION_MEMBERS_READ:     true,
+	PERMISSION_MEMBERS_ACTIVE:   true,
+	PERMISSION_MEMBERS_PASSWORD: true,
+	PERMISSION_MEMBERS_SESSIONS: true,
+	PERMISSION_MEMBERS_ROLES:    true,
+	PERMISSION_INTEGRATIONS:     true,
+	PERMISSION_CHAT_IMPORT:      true,
+	PERMISSION_METRICS:          true,
+}
+
+// RolePermissions is what each role is allowed to do. Members can be granted
+// more on top of their role; admins hold every permission.
+var RolePermissions = map[string][]string{
+	ROLE_MEMBER: {},
+	ROLE_BOT:    {},
+	ROLE_SUPPORT: {
+		PERMISSION_AUDIT_LOG,
+		PERMISSION_MEMBERS_READ,
+		PERMISSION_MEMBERS_ACTIVE,
+		PERMISSION_MEMBERS_PASSWORD,
+		PERMISSION_MEMBERS_SESSIONS,
+	},
+	ROLE_MODERATOR: {
+		PERMISSION_MODERATE,
+		PERMISSION_AUDIT_LOG,
+		PERMISSION_MEMBERS_READ,
+	},
+}
+
+// MemberRoleRequest ...
+type MemberRoleRequest struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	Note        string   `json:"note"`
+}
+
+// ValidateMemberRoleRequest only takes the roles staff can be given. Bots
+// are managed under /admin/bots.
+func (request *MemberRoleRequest) ValidateMemberRoleRequest() error {
+	if request.Role != ROLE_ADMIN {
+		if _, ok := RolePermissions[request.Role]; !ok || request.Role == ROLE_BOT {
+			return util.GetError("invalid_role")
+		}
+	}
+	for _, permission := range request.Permissions {
+		if !Permissions[permission] {
+			return util.GetError("invalid_permission")
+		}
+	}
+	sort.Strings(request.Permissions)
+	return nil
+}
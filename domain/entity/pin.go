@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// MAX_CHAT_PINS is how many messages a conversation can have pinned at once.
+const MAX_CHAT_PINS = 10
+
+// ChatPin is a message pinned in a conversation. Both participants see it.
+type ChatPin struct {
+	ID        string    `bson:"id" json:"id"`
+	Ref       string    `bson:"ref" json:"ref"`
+	Chat      string    `bson:"chat" json:"chat"`
+	PinnedBy  string    `bson:"pinned_by" json:"pinned_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ChatStar is a message a profile starred for itself.
+type ChatStar struct {
+	ID        string    `bson:"id" json:"id"`
+	Profile   string    `bson:"profile" json:"profile"`
+	Ref       string    `bson:"ref" json:"ref"`
+	ChatId    string    `bson:"chat_id" json:"chat_id"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// PinnedMessage ...
+type PinnedMessage struct {
+	Pin     ChatPin     `json:"pin"`
+	Message ChatMessage `json:"message"`
+}
+
+// StarredMessage ...
+type StarredMessage struct {
+	Star    ChatStar    `json:"star"`
+	Message ChatMessage `json:"message"`
+}
+
+// ChatMessagePinned is pushed to both sides when a message is pinned or
+// unpinned.
+type ChatMessagePinned struct {
+	Ref      string    `json:"ref"`
+	PinnedBy string    `json:"pinned_by"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// ConversationKey is the same for both participants, unlike a chat id.
+func ConversationKey(profile, other string) string {
+	if other < profile {
+		profile, other = other, profile
+	}
+	return fmt.Sprintf("%s-%s", profile, other)
+}
+
+// MessageRef is the ref of message, which is also its id. Messages stored
+// before refs existed use their own id.
+func MessageRef(message *ChatMessage) string {
+	if message.Ref == "" {
+		return message.ID
+	}
+	return message.Ref
+}
+
+// PrepareChatPin ...
+func (pin *ChatPin) PrepareChatPin(profile string, message *ChatMessage) {
+	pin.ID = util.ULID()
+	pin.Ref = MessageRef(message)
+	pin.Chat = ConversationKey(message.Sender, message.Receiver)
+	pin.PinnedBy = profile
+	pin.CreatedAt = util.GetTimeNow()
+}
+
+// PrepareChatStar ...
+func (star *ChatStar) PrepareChatStar(profile string, message *ChatMessage) {
+	star.ID = util.ULID()
+	star.Profile = profile
+	star.Ref = MessageRef(message)
+	star.ChatId = message.ChatId
+	star.CreatedAt = util.GetTimeNow()
+}
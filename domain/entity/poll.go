@@ -0,0 +1,164 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// MIN_POLL_OPTIONS ...
+	MIN_POLL_OPTIONS = 2
+	// MAX_POLL_OPTIONS ...
+	MAX_POLL_OPTIONS = 10
+	// MAX_POLL_DURATION is how far ahead a poll's close time may be.
+	MAX_POLL_DURATION = 30 * 24 * time.Hour
+)
+
+// Poll is attached to a poll message. Its tallies are copied onto the
+// message whenever they change, so history shows the latest
+// results, and the final ones once the poll is closed.
+type Poll struct {
+	Ref          string       `bson:"ref" json:"ref"`
+	Question     string       `bson:"question" json:"question"`
+	Options      []PollOption `bson:"options" json:"options"`
+	Multiple     bool         `bson:"multiple" json:"multiple"`
+	Anonymous    bool         `bson:"anonymous" json:"anonymous"`
+	Voters       int64        `bson:"voters" json:"voters"`
+	Participants []string     `bson:"participants" json:"-"`
+	CreatedBy    string       `bson:"created_by" json:"created_by"`
+	ClosesAt     *time.Time   `bson:"closes_at,omitempty" json:"closes_at,omitempty"`
+	ClosedAt     *time.Time   `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	CreatedAt    time.Time    `bson:"created_at" json:"created_at"`
+}
+
+// PollOption holds the votes for one answer. Voters is left empty for
+// anonymous polls.
+type PollOption struct {
+	Text   string   `bson:"text" json:"text"`
+	Votes  int64    `bson:"votes" json:"votes"`
+	Voters []string `bson:"voters,omitempty" json:"voters,omitempty"`
+}
+
+// PollRequest is what a member sends to start a poll.
+type PollRequest struct {
+	Question  string     `json:"question"`
+	Options   []string   `json:"options"`
+	Multiple  bool       `json:"multiple"`
+	Anonymous bool       `json:"anonymous"`
+	ClosesAt  *time.Time `json:"closes_at"`
+}
+
+// PollVote is one profile's choice in a poll, by option index.
+type PollVote struct {
+	Poll      string    `bson:"poll" json:"poll"`
+	Profile   string    `bson:"profile" json:"profile"`
+	Options   []int     `bson:"options" json:"options"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// PollVoteRequest is what a member sends to vote. An empty list takes the
+// member's vote back.
+type PollVoteRequest struct {
+	Options []int `json:"options"`
+}
+
+// ValidatePollRequest ...
+func (request *PollRequest) ValidatePollRequest() error {
+	request.Question = strings.TrimSpace(request.Question)
+	if request.Question == "" || len([]rune(request.Question)) > 300 {
+		return util.GetError("invalid_poll_question")
+	}
+	if len(request.Options) < MIN_POLL_OPTIONS || len(request.Options) > MAX_POLL_OPTIONS {
+		return util.GetError("invalid_poll_options")
+	}
+	for index, option := range request.Options {
+		request.Options[index] = strings.TrimSpace(option)
+		if request.Options[index] == "" || len([]rune(request.Options[index])) > 100 {
+			return util.GetError("invalid_poll_options")
+		}
+	}
+	if request.ClosesAt != nil {
+		now := util.GetTimeNow()
+		if !request.ClosesAt.After(now) || request.ClosesAt.After(now.Add(MAX_POLL_DURATION)) {
+			return util.GetError("invalid_poll_close_time")
+		}
+	}
+	return nil
+}
+
+// PreparePoll ...
+func (poll *Poll) PreparePoll(sender, receiver string, request *PollRequest) {
+	poll.Question = request.Question
+	poll.Options = make([]PollOption, len(request.Options))
+	for index, option := range request.Options {
+		poll.Options[index] = PollOption{Text: option}
+	}
+	poll.Multiple = request.Multiple
+	poll.Anonymous = request.Anonymous
+	poll.Participants = []string{sender, receiver}
+	poll.CreatedBy = sender
+	poll.ClosesAt = request.ClosesAt
+	poll.CreatedAt = util.GetTimeNow()
+}
+
+// Closed tells whether the poll stopped taking votes at now.
+func (poll *Poll) Closed(now time.Time) bool {
+	return poll.ClosedAt != nil || (poll.ClosesAt != nil && !poll.ClosesAt.After(now))
+}
+
+// Participant ...
+func (poll *Poll) Participant(profile string) bool {
+	for _, participant := range poll.Participants {
+		if participant == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePollVote checks request against the poll's options and choice
+// mode, dropping repeated options.
+func (poll *Poll) ValidatePollVote(request *PollVoteRequest) error {
+	seen := make(map[int]bool)
+	options := make([]int, 0, len(request.Options))
+	for _, option := range request.Options {
+		if option < 0 || option >= len(poll.Options) {
+			return util.GetError("invalid_poll_vote")
+		}
+		if !seen[option] {
+			seen[option] = true
+			options = append(options, option)
+		}
+	}
+	if len(options) > 1 && !poll.Multiple {
+		return util.GetError("invalid_poll_vote")
+	}
+	request.Options = options
+	return nil
+}
+
+// Tally recounts the options from votes.
+func (poll *Poll) Tally(votes []PollVote) {
+	for index := range poll.Options {
+		poll.Options[index].Votes = 0
+		poll.Options[index].Voters = nil
+	}
+	poll.Voters = 0
+	for _, vote := range votes {
+		if len(vote.Options) == 0 {
+			continue
+		}
+		poll.Voters++
+		for _, option := range vote.Options {
+			if option < 0 || option >= len(poll.Options) {
+				continue
+			}
+			poll.Options[option].Votes++
+			if !poll.Anonymous {
+				poll.Options[option].Voters = append(poll.Options[option].Voters, vote.Profile)
+			}
+		}
+	}
+}
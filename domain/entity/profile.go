@@ -3,6 +3,7 @@ package entity
 import (
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/majid-cj/go-chat-server/util"
@@ -25,6 +26,8 @@ type MemberProfile struct {
 	ProfileImage string    `json:"profile_image" bson:"profile_image"`
 	Authorized   uint8     `json:"authorized" bson:"authorized"`
 	Private      bool      `json:"is_private" bson:"is_private"`
+	Bot          bool      `json:"is_bot" bson:"is_bot"`
+	Deleted      bool      `json:"is_deleted" bson:"is_deleted,omitempty"`
 	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
 }
 
@@ -61,3 +64,14 @@ func (profile *MemberProfile) PrepareDefaultProfile(member, name, email string)
 	profile.Private = false
 	profile.CreatedAt = util.GetTimeNow()
 }
+
+// Anonymize strips the profile of everything that identifies its member. The
+// profile itself stays so the conversations of other members still show who
+// they talked to.
+func (profile *MemberProfile) Anonymize() {
+	profile.DisplayName = DELETED_DISPLAY_NAME
+	profile.NickName = "deleted_" + strings.ToLower(profile.ID)
+	profile.ProfileImage = os.Getenv("DEFAULT_PROFILE_PIC")
+	profile.Private = true
+	profile.Deleted = true
+}
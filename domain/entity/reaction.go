@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// MAX_REACTION_LENGTH is the longest reaction in bytes, enough for emoji
+// sequences joined with zero width joiners.
+const MAX_REACTION_LENGTH = 32
+
+// ReactionRequest ...
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// Reaction is one profile's reaction to a message.
+type Reaction struct {
+	Profile string `json:"profile"`
+	Emoji   string `json:"emoji"`
+}
+
+// ChatMessageReaction is pushed to both sides when a profile reacts to a
+// message or takes its reaction back. Emoji is empty when it was removed.
+type ChatMessageReaction struct {
+	Ref     string `json:"ref"`
+	Profile string `json:"profile"`
+	Emoji   string `json:"emoji"`
+}
+
+// ValidateReactionRequest ...
+func (request *ReactionRequest) ValidateReactionRequest() error {
+	request.Emoji = strings.TrimSpace(request.Emoji)
+	if request.Emoji == "" || len(request.Emoji) > MAX_REACTION_LENGTH || strings.ContainsAny(request.Emoji, " \t\n") {
+		return util.GetError("invalid_reaction")
+	}
+	return nil
+}
+
+// ReactionList returns the reactions to message ordered by profile, so
+// exports list them the same way every time.
+func ReactionList(message *ChatMessage) []Reaction {
+	reactions := make([]Reaction, 0, len(message.Reactions))
+	for profile, emoji := range message.Reactions {
+		reactions = append(reactions, Reaction{Profile: profile, Emoji: emoji})
+	}
+	sort.Slice(reactions, func(i, j int) bool {
+		return reactions[i].Profile < reactions[j].Profile
+	})
+	return reactions
+}
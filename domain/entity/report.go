@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// REPORT_MESSAGE ...
+	REPORT_MESSAGE = "message"
+	// REPORT_PROFILE ...
+	REPORT_PROFILE = "profile"
+
+	// REPORT_OPEN ...
+	REPORT_OPEN = "open"
+	// REPORT_TRIAGED ...
+	REPORT_TRIAGED = "triaged"
+	// REPORT_RESOLVED ...
+	REPORT_RESOLVED = "resolved"
+	// REPORT_DISMISSED ...
+	REPORT_DISMISSED = "dismissed"
+
+	// ACTION_SUSPEND ...
+	ACTION_SUSPEND = "suspend"
+	// ACTION_DELETE ...
+	ACTION_DELETE = "delete"
+	// ACTION_DISMISS ...
+	ACTION_DISMISS = "dismiss"
+
+	// REPORT_SNAPSHOT_SIZE is how many messages are kept on each side of a
+	// reported message, or in total for a reported profile.
+	REPORT_SNAPSHOT_SIZE = 20
+)
+
+// ReportReasons ...
+var ReportReasons = map[string]bool{
+	"spam":       true,
+	"harassment": true,
+	"hate":       true,
+	"sexual":     true,
+	"violence":   true,
+	"scam":       true,
+	"other":      true,
+}
+
+// ModerationActions ...
+var ModerationActions = map[string]bool{
+	ACTION_SUSPEND: true,
+	ACTION_DELETE:  true,
+	ACTION_DISMISS: true,
+}
+
+// Report ...
+type Report struct {
+	ID         string             `bson:"id" json:"id"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Reporter   string             `bson:"reporter" json:"reporter"`
+	Reported   string             `bson:"reported" json:"reported"`
+	MessageRef string             `bson:"message_ref,omitempty" json:"message_ref,omitempty"`
+	Reason     string             `bson:"reason" json:"reason"`
+	Details    string             `bson:"details" json:"details"`
+	Snapshot   ChatMessageHistory `bson:"snapshot" json:"snapshot,omitempty"`
+	Status     string             `bson:"status" json:"status"`
+	Priority   int                `bson:"priority" json:"priority"`
+	Assignee   string             `bson:"assignee,omitempty" json:"assignee,omitempty"`
+	Resolution *Resolution        `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ReportRequest is what a member sends to report a message or a profile.
+type ReportRequest struct {
+	Kind      string `json:"kind"`
+	MessageId string `json:"message_id"`
+	ProfileId string `json:"profile_id"`
+	Reason    string `json:"reason"`
+	Details   string `json:"details"`
+}
+
+// Resolution ...
+type Resolution struct {
+	Action     string    `bson:"action" json:"action"`
+	Note       string    `bson:"note" json:"note"`
+	Moderator  string    `bson:"moderator" json:"moderator"`
+	ResolvedAt time.Time `bson:"resolved_at" json:"resolved_at"`
+}
+
+// ValidateReportRequest ...
+func (request *ReportRequest) ValidateReportRequest() error {
+	if !ReportReasons[request.Reason] {
+		return util.GetError("invalid_report_reason")
+	}
+	if len(request.Details) > 1000 {
+		return util.GetError("report_details_too_long")
+	}
+	switch request.Kind {
+	case REPORT_MESSAGE:
+		if request.MessageId == "" {
+			return util.GetError("message_not_found")
+		}
+	case REPORT_PROFILE:
+		if request.ProfileId == "" {
+			return util.GetError("profile_not_found")
+		}
+	default:
+		return util.GetError("invalid_report_kind")
+	}
+	return nil
+}
+
+// PrepareReport ...
+func (report *Report) PrepareReport(reporter, reported string, request *ReportRequest) {
+	report.ID = util.ULID()
+	report.Kind = request.Kind
+	report.Reporter = reporter
+	report.Reported = reported
+	report.Reason = request.Reason
+	report.Details = strings.TrimSpace(request.Details)
+	report.Status = REPORT_OPEN
+	report.CreatedAt = util.GetTimeNow()
+	report.UpdatedAt = report.CreatedAt
+}
+
+// Resolve ...
+func (report *Report) Resolve(moderator, action, note string) {
+	report.Status = REPORT_RESOLVED
+	if action == ACTION_DISMISS {
+		report.Status = REPORT_DISMISSED
+	}
+	report.Resolution = &Resolution{
+		Action:     action,
+		Note:       note,
+		Moderator:  moderator,
+		ResolvedAt: util.GetTimeNow(),
+	}
+	report.UpdatedAt = report.Resolution.ResolvedAt
+}
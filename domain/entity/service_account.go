@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// SERVICE_TOKEN_PREFIX ...
+const SERVICE_TOKEN_PREFIX = "svc_"
+
+// ServiceAccount lets one of our own backends call the gRPC API. Like a bot
+// token, its token is only stored hashed, but it does not act as a profile:
+// it can read any member, profile or conversation and send as any profile.
+type ServiceAccount struct {
+	ID        string    `bson:"id" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	TokenHash string    `bson:"token_hash" json:"-"`
+	TokenHint string    `bson:"token_hint" json:"token_hint"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ServiceAccountRequest is what an admin sends to create a service account.
+type ServiceAccountRequest struct {
+	Name string `json:"name"`
+}
+
+// ValidateServiceAccountRequest ...
+func (request *ServiceAccountRequest) ValidateServiceAccountRequest() error {
+	if strings.TrimSpace(request.Name) == "" {
+		return util.GetError("invalid_service_account_name")
+	}
+	return nil
+}
+
+// PrepareServiceAccount returns the plain token. The token cannot be
+// recovered later.
+func (account *ServiceAccount) PrepareServiceAccount(admin string, request *ServiceAccountRequest) string {
+	account.ID = util.ULID()
+	account.Name = strings.TrimSpace(request.Name)
+	account.CreatedBy = admin
+	account.CreatedAt = util.GetTimeNow()
+	account.UpdatedAt = account.CreatedAt
+	return account.NewToken()
+}
+
+// NewToken replaces the account's token and returns the plain value.
+func (account *ServiceAccount) NewToken() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	token := SERVICE_TOKEN_PREFIX + hex.EncodeToString(secret)
+	account.TokenHash = HashBotToken(token)
+	account.TokenHint = token[len(token)-4:]
+	account.UpdatedAt = util.GetTimeNow()
+	return token
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// MessageTranslation is a message's text in another language. It is cached
+// per message ref and language, so both sides of a chat share it.
+type MessageTranslation struct {
+	Ref       string    `bson:"ref" json:"ref"`
+	Lang      string    `bson:"lang" json:"lang"`
+	Source    string    `bson:"source,omitempty" json:"source,omitempty"`
+	Text      string    `bson:"text" json:"text"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// AutoTranslateRequest turns auto-translate on for a chat, or off when Lang
+// is empty.
+type AutoTranslateRequest struct {
+	Lang string `json:"lang"`
+}
+
+// PrepareMessageTranslation ...
+func (translation *MessageTranslation) PrepareMessageTranslation(ref, lang, source, text string) {
+	translation.Ref = ref
+	translation.Lang = lang
+	translation.Source = source
+	translation.Text = text
+	translation.CreatedAt = util.GetTimeNow()
+}
+
+// Unchanged tells whether the message was already written in the target
+// language.
+func (translation *MessageTranslation) Unchanged() bool {
+	return translation.Source == translation.Lang
+}
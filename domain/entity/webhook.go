@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// WEBHOOK_MESSAGE_CREATED ...
+	WEBHOOK_MESSAGE_CREATED = "message.created"
+	// WEBHOOK_MESSAGE_READ ...
+	WEBHOOK_MESSAGE_READ = "message.read"
+	// WEBHOOK_MEMBER_SIGNED_UP ...
+	WEBHOOK_MEMBER_SIGNED_UP = "member.signed_up"
+	// WEBHOOK_MEMBER_DELETED is sent once a member's account is deleted, so
+	// receivers can erase what they hold about it.
+	WEBHOOK_MEMBER_DELETED = "member.deleted"
+	// WEBHOOK_PROFILE_UPDATED ...
+	WEBHOOK_PROFILE_UPDATED = "profile.updated"
+	// WEBHOOK_BOT_MESSAGE is only sent to the webhook of the bot a message
+	// was sent to. Other webhooks cannot subscribe to it.
+	WEBHOOK_BOT_MESSAGE = "bot.message"
+
+	// DELIVERY_PENDING ...
+	DELIVERY_PENDING = "pending"
+	// DELIVERY_DELIVERED ...
+	DELIVERY_DELIVERED = "delivered"
+	// DELIVERY_DEAD is a delivery that ran out of attempts. It stays in the
+	// store until it is retried by hand.
+	DELIVERY_DEAD = "dead"
+)
+
+// WebhookEvents ...
+var WebhookEvents = map[string]bool{
+	WEBHOOK_MESSAGE_CREATED:  true,
+	WEBHOOK_MESSAGE_READ:     true,
+	WEBHOOK_MEMBER_SIGNED_UP: true,
+	WEBHOOK_MEMBER_DELETED:   true,
+	WEBHOOK_PROFILE_UPDATED:  true,
+}
+
+// Webhook is an HTTPS endpoint that receives the events it subscribed to.
+type Webhook struct {
+	ID          string    `bson:"id" json:"id"`
+	URL         string    `bson:"url" json:"url"`
+	Events      []string  `bson:"events" json:"events"`
+	Description string    `bson:"description" json:"description"`
+	Secret      string    `bson:"secret" json:"secret,omitempty"`
+	Active      bool      `bson:"active" json:"active"`
+	Bot         string    `bson:"bot,omitempty" json:"bot,omitempty"`
+	CreatedBy   string    `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// WebhookRequest is what an admin sends to register or update a webhook.
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookEvent is the body posted to a webhook.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is one event queued for one webhook, together with the log
+// of every attempt made to deliver it.
+type WebhookDelivery struct {
+	ID            string           `bson:"id" json:"id"`
+	Webhook       string           `bson:"webhook" json:"webhook"`
+	Event         string           `bson:"event" json:"event"`
+	EventId       string           `bson:"event_id" json:"event_id"`
+	Payload       string           `bson:"payload" json:"payload"`
+	Status        string           `bson:"status" json:"status"`
+	Attempts      int              `bson:"attempts" json:"attempts"`
+	Log           []WebhookAttempt `bson:"log" json:"log"`
+	NextAttemptAt time.Time        `bson:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+// WebhookAttempt ...
+type WebhookAttempt struct {
+	StatusCode int       `bson:"status_code" json:"status_code"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	Duration   int64     `bson:"duration" json:"duration"`
+	AttemptAt  time.Time `bson:"attempt_at" json:"attempt_at"`
+}
+
+// ValidateWebhookRequest ...
+func (request *WebhookRequest) ValidateWebhookRequest() error {
+	link, err := url.Parse(request.URL)
+	if err != nil || link.Scheme != "https" || link.Host == "" {
+		return util.GetError("invalid_webhook_url")
+	}
+	if len(request.Events) == 0 {
+		return util.GetError("invalid_webhook_event")
+	}
+	for _, event := range request.Events {
+		if !WebhookEvents[event] {
+			return util.GetError("invalid_webhook_event")
+		}
+	}
+	if len(request.Description) > 200 {
+		return util.GetError("webhook_description_too_long")
+	}
+	return nil
+}
+
+// PrepareWebhook ...
+func (webhook *Webhook) PrepareWebhook(admin string, request *WebhookRequest) {
+	webhook.ID = util.ULID()
+	webhook.Secret = NewWebhookSecret()
+	webhook.Active = true
+	webhook.CreatedBy = admin
+	webhook.CreatedAt = util.GetTimeNow()
+	webhook.Update(request)
+}
+
+// Update copies request onto webhook, keeping the active flag when the
+// request leaves it out.
+func (webhook *Webhook) Update(request *WebhookRequest) {
+	webhook.URL = request.URL
+	webhook.Events = request.Events
+	webhook.Description = request.Description
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+	webhook.UpdatedAt = util.GetTimeNow()
+}
+
+// PrepareBotWebhook sets webhook up to receive the messages sent to bot.
+func (webhook *Webhook) PrepareBotWebhook(admin string, bot *Bot, link string) {
+	webhook.PrepareWebhook(admin, &WebhookRequest{
+		URL:         link,
+		Events:      []string{WEBHOOK_BOT_MESSAGE},
+		Description: "bot " + bot.Profile,
+	})
+	webhook.Bot = bot.ID
+}
+
+// NewWebhookSecret returns a random 32 byte signing secret, hex encoded.
+func NewWebhookSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(secret)
+}
+
+// PrepareWebhookDelivery ...
+func (delivery *WebhookDelivery) PrepareWebhookDelivery(webhook, event, eventId, payload string) {
+	delivery.ID = util.ULID()
+	delivery.Webhook = webhook
+	delivery.Event = event
+	delivery.EventId = eventId
+	delivery.Payload = payload
+	delivery.Status = DELIVERY_PENDING
+	delivery.Log = []WebhookAttempt{}
+	delivery.CreatedAt = util.GetTimeNow()
+	delivery.NextAttemptAt = delivery.CreatedAt
+	delivery.UpdatedAt = delivery.CreatedAt
+}
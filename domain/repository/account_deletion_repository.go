@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// AccountDeletionRepository ...
+type AccountDeletionRepository interface {
+	CreateAccountDeletion(*entity.AccountDeletion) error
+	GetAccountDeletion(string) (*entity.AccountDeletion, error)
+	CancelAccountDeletion(string) error
+	GetDueAccountDeletions(time.Time) ([]entity.AccountDeletion, error)
+	ClaimAccountDeletion(string, time.Time) (*entity.AccountDeletion, error)
+	SetAccountDeletionStatus(string, string) error
+	CompleteAccountDeletion(string, time.Time) error
+}
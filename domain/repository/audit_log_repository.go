@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// AuditLogRepository ...
+type AuditLogRepository interface {
+	AddAuditLog(*entity.AuditLog) error
+	GetAuditLogs(string, int64) ([]entity.AuditLog, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// BotRepository ...
+type BotRepository interface {
+	CreateBot(*entity.Bot) (*entity.Bot, error)
+	GetBot(string) (*entity.Bot, error)
+	GetBots() ([]entity.Bot, error)
+	GetBotByToken(string) (*entity.Bot, error)
+	GetBotByProfile(string) (*entity.Bot, error)
+	UpdateBot(*entity.Bot) error
+	DeleteBot(string) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// ChatExportRepository ...
+type ChatExportRepository interface {
+	CreateChatExport(*entity.ChatExport) (*entity.ChatExport, error)
+	UpdateChatExport(*entity.ChatExport) error
+	GetChatExport(string, string) (*entity.ChatExport, error)
+	GetChatExports(string) ([]entity.ChatExport, error)
+	DeleteChatExports(string) error
+	RenewChatExports([]string, time.Time) error
+	GetAbandonedChatExports(time.Time) ([]entity.ChatExport, error)
+	ClaimChatExport(string, time.Time) (*entity.ChatExport, error)
+	GetExpiredChatExports(time.Time) ([]entity.ChatExport, error)
+	ExpireChatExport(string) error
+}
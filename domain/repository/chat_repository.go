@@ -1,15 +1,46 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
 )
 
 // MemberRepository ...
 type ChatRepository interface {
-	AddNewChatMessage(*entity.ChatMessage) error
+	AddChatMessage(*entity.ChatMessage, bool) error
+	SetChatMessagePreview(string, *linkpreview.Preview) error
+	SetChatMessagePoll(string, *entity.Poll) error
+	SetChatMessageTranslation(string, string, *entity.MessageTranslation) error
+	SetChatMessagePlayed(string, string, time.Time) (*entity.ChatMessage, error)
+	SetChatMessageReaction(string, string, string) (*entity.ChatMessage, error)
+	DeleteProfileReactions(string) error
+	EditChatMessage(string, string, string, time.Time) (*entity.ChatMessage, error)
 	ReadChatMessage(string, string) error
+	MarkChatMessagesRead(string, string, int64) (*entity.ChatMessage, int64, error)
 	GetChatHistory(string) (entity.ChatMessageHistory, error)
-	AddChatRoom(*entity.ChatRoom) error
+	GetChatMessagesBySeq(string, int64, int64, int64) (entity.ChatMessageHistory, error)
+	GetLatestChatMessages(string, int64, int64) (entity.ChatMessageHistory, error)
+	GetChatMessage(string, string) (*entity.ChatMessage, error)
+	GetChatMessagesByRefs(string, []string) (entity.ChatMessageHistory, error)
+	GetChatContext(string, string, int64) (entity.ChatMessageHistory, error)
+	HideChatMessage(string, string) error
+	DeleteChatMessage(string) error
+	IterateChatHistory(string, func(*entity.ChatMessage) error) (int64, error)
+	IterateSentMessages(string, func(*entity.ChatMessage) error) (int64, error)
+	DeleteSentMessages(string) error
+	LeaveConversations(string) error
+	CreateConversation(string, string) (*entity.Conversation, bool, error)
+	GetConversation(string, string) (*entity.Conversation, error)
+	GetConversations(string, time.Time, string, int64) ([]entity.Conversation, error)
+	HideChat(string, string) error
+	MuteChat(string, string, *time.Time) error
+	UnmuteChat(string, string) error
+	GetChatRoom(string, string) (*entity.ChatRoom, error)
+	SetChatAutoTranslate(string, string, string) error
+	ImportChatMessages(entity.ChatMessageHistory) ([]string, error)
+	ImportConversation(*entity.Conversation) error
 	GetChatList(string) (entity.ChatList, error)
-	GetChatCounter(string) (int64, error)
+	GetChatCounter(string) (*entity.ChatCounter, error)
 }
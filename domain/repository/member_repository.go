@@ -6,11 +6,13 @@ import "github.com/majid-cj/go-chat-server/domain/entity"
 type MemberRepository interface {
 	CreateMember(*entity.Member) (*entity.Member, error)
 	DeleteMember(string) error
-	GetMembers() ([]entity.Member, error)
+	SearchMembers(entity.MemberSearch, int64) ([]entity.Member, error)
 	GetMember(string) (*entity.Member, error)
-	GetMembersByType(uint8) ([]entity.Member, error)
 	GetMembersBySource(uint8) ([]entity.Member, error)
 	GetMemberByEmailAndPassword(*entity.SignUp) (*entity.Member, error)
 	GetMemberByEmailAndSource(*entity.Member) (*entity.Member, uint8, error)
 	UpdatePassword(*entity.Member) error
+	SetMemberActive(string, bool) error
+	SetMemberRole(string, string, []string) error
+	SetPasswordReset(string, bool) error
 }
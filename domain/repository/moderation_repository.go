@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// ModerationRepository ...
+type ModerationRepository interface {
+	AddModerationReview(*entity.ModerationReview) error
+	GetModerationReview(string) (*entity.ModerationReview, error)
+	GetModerationReviews(string, int64) ([]entity.ModerationReview, error)
+	UpdateModerationReview(*entity.ModerationReview) error
+}
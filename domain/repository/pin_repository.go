@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// PinRepository ...
+type PinRepository interface {
+	AddPin(*entity.ChatPin) error
+	DeletePin(string, string) error
+	GetPins(string) ([]entity.ChatPin, error)
+	DeleteMessagePins(string) error
+}
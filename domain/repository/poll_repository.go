@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// PollRepository ...
+type PollRepository interface {
+	CreatePoll(*entity.Poll) error
+	GetPoll(string) (*entity.Poll, error)
+	SetPollVote(*entity.PollVote) error
+	GetPollVotes(string) ([]entity.PollVote, error)
+	UpdatePollResults(*entity.Poll) error
+	ClosePoll(string, time.Time) (*entity.Poll, error)
+	GetDuePolls(time.Time) ([]entity.Poll, error)
+	DeletePoll(string) error
+}
@@ -7,6 +7,7 @@ type ProfileRepository interface {
 	CreateMemberProfile(*entity.MemberProfile) (*entity.MemberProfile, error)
 	UpdateMemberProfile(*entity.MemberProfile) (*entity.MemberProfile, error)
 	GetMemberProfileByID(string) (*entity.MemberProfile, error)
+	GetMemberProfilesByIDs([]string) (entity.MemberProfiles, error)
 	GetMemberProfileByMemberID(string) (*entity.MemberProfile, error)
 	GetMemberProfileByNickName(string) (*entity.MemberProfile, error)
 }
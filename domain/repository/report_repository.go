@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// ReportRepository ...
+type ReportRepository interface {
+	CreateReport(*entity.Report) (*entity.Report, error)
+	GetReport(string) (*entity.Report, error)
+	GetReports(string, int64) ([]entity.Report, error)
+	UpdateReport(*entity.Report) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// ServiceAccountRepository ...
+type ServiceAccountRepository interface {
+	CreateServiceAccount(*entity.ServiceAccount) (*entity.ServiceAccount, error)
+	GetServiceAccount(string) (*entity.ServiceAccount, error)
+	GetServiceAccounts() ([]entity.ServiceAccount, error)
+	GetServiceAccountByToken(string) (*entity.ServiceAccount, error)
+	UpdateServiceAccount(*entity.ServiceAccount) error
+	DeleteServiceAccount(string) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// StarRepository ...
+type StarRepository interface {
+	AddStar(*entity.ChatStar) error
+	DeleteStar(string, string) error
+	GetStars(string, int64) ([]entity.ChatStar, error)
+	GetChatStars(string, string) ([]entity.ChatStar, error)
+	DeleteMessageStars(string) error
+	DeleteProfileStars(string) error
+}
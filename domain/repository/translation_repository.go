@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/majid-cj/go-chat-server/domain/entity"
+
+// TranslationRepository ...
+type TranslationRepository interface {
+	GetTranslation(string, string) (*entity.MessageTranslation, error)
+	AddTranslation(*entity.MessageTranslation) error
+	DeleteTranslations(string) error
+}
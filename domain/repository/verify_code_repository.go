@@ -9,4 +9,5 @@ type VerificationCodeRepository interface {
 	ResetPassword(*entity.VerificationCode) error
 	CheckVerificationCode(*entity.VerificationCode) error
 	RenewVerificationCode(*entity.VerificationCode) (*entity.VerificationCode, error)
+	DeleteVerificationCodes(string) error
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// WebhookRepository ...
+type WebhookRepository interface {
+	CreateWebhook(*entity.Webhook) (*entity.Webhook, error)
+	GetWebhook(string) (*entity.Webhook, error)
+	GetWebhooks() ([]entity.Webhook, error)
+	GetWebhooksByEvent(string) ([]entity.Webhook, error)
+	UpdateWebhook(*entity.Webhook) error
+	DeleteWebhook(string) error
+	AddWebhookDeliveries([]entity.WebhookDelivery) error
+	ClaimWebhookDelivery(time.Time, time.Duration) (*entity.WebhookDelivery, error)
+	GetWebhookDelivery(string) (*entity.WebhookDelivery, error)
+	GetWebhookDeliveries(string, string, int64) ([]entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(*entity.WebhookDelivery) error
+}
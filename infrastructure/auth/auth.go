@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -36,6 +37,8 @@ type AuthenticationInterface interface {
 	FetchToken(string) (string, error)
 	DeleteAccessToken(*AccessDetail) error
 	DeleteRefreshToken(string) error
+	DeleteMemberTokens(string) error
+	CountMemberTokens(string) (int, error)
 }
 
 // NewAccessData ...
@@ -103,3 +106,35 @@ func (access *AccessData) DeleteRefreshToken(refreshUUID string) error {
 	}
 	return nil
 }
+
+// DeleteMemberTokens revokes every access and refresh token of userID. Refresh
+// keys are "<access uuid>++<user id>", so each one also names its access key.
+func (access *AccessData) DeleteMemberTokens(userID string) error {
+	iterator := access.redisDB.Scan(ctx, 0, fmt.Sprintf("*++%s", userID), 100).Iterator()
+	for iterator.Next(ctx) {
+		refreshUUID := iterator.Val()
+		accessUUID := strings.TrimSuffix(refreshUUID, fmt.Sprintf("++%s", userID))
+		_, err := access.redisDB.Del(ctx, accessUUID, refreshUUID).Result()
+		if err != nil {
+			return errors.New("general_error")
+		}
+	}
+	if iterator.Err() != nil {
+		return errors.New("general_error")
+	}
+	return nil
+}
+
+// CountMemberTokens counts the sessions userID is signed in with, one refresh
+// token each.
+func (access *AccessData) CountMemberTokens(userID string) (int, error) {
+	count := 0
+	iterator := access.redisDB.Scan(ctx, 0, fmt.Sprintf("*++%s", userID), 100).Iterator()
+	for iterator.Next(ctx) {
+		count++
+	}
+	if iterator.Err() != nil {
+		return 0, errors.New("general_error")
+	}
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// claim states returned by MessageDedupInterface.Claim
+const (
+	CLAIM_NEW = iota
+	CLAIM_PENDING
+	CLAIM_DONE
+)
+
+const pending = "pending"
+
+// Claim is what is known about a client message id. ID, Seq and CreatedAt
+// are only set once the first send with that id was delivered.
+type Claim struct {
+	State     int
+	ID        string
+	Seq       int64
+	CreatedAt time.Time
+}
+
+// MessageDedupInterface ...
+type MessageDedupInterface interface {
+	Claim(sender, clientMsgId string) (*Claim, error)
+	Complete(sender, clientMsgId, ID string, seq int64, createdAt time.Time) error
+	Release(sender, clientMsgId string) error
+}
+
+// MessageDedup remembers client message ids per sender in redis for Window,
+// so a retried send is recognised on any replica.
+type MessageDedup struct {
+	redisDB *redis.Client
+	Window  time.Duration
+}
+
+var _ MessageDedupInterface = &MessageDedup{}
+
+// NewMessageDedup reads the window from MESSAGE_DEDUP_WINDOW, one hour by
+// default.
+func NewMessageDedup(redisDB *redis.Client) *MessageDedup {
+	window, err := time.ParseDuration(os.Getenv("MESSAGE_DEDUP_WINDOW"))
+	if err != nil || window <= 0 {
+		window = time.Hour
+	}
+	return &MessageDedup{
+		redisDB: redisDB,
+		Window:  window,
+	}
+}
+
+func key(sender, clientMsgId string) string {
+	return fmt.Sprintf("dedup:%s:%s", sender, clientMsgId)
+}
+
+// Claim takes clientMsgId for sender. Only the first caller gets CLAIM_NEW;
+// later ones learn whether that send is still in flight or what it stored.
+func (dedup *MessageDedup) Claim(sender, clientMsgId string) (*Claim, error) {
+	ctx := context.Background()
+	ok, err := dedup.redisDB.SetNX(ctx, key(sender, clientMsgId), pending, dedup.Window).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &Claim{State: CLAIM_NEW}, nil
+	}
+
+	value, err := dedup.redisDB.Get(ctx, key(sender, clientMsgId)).Result()
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(value, "|")
+	if len(parts) < 2 {
+		return &Claim{State: CLAIM_PENDING}, nil
+	}
+	claim := &Claim{State: CLAIM_DONE, ID: parts[0]}
+	nanos, _ := strconv.ParseInt(parts[1], 10, 64)
+	claim.CreatedAt = time.Unix(0, nanos).UTC()
+	if len(parts) > 2 {
+		claim.Seq, _ = strconv.ParseInt(parts[2], 10, 64)
+	}
+	return claim, nil
+}
+
+// Complete records the server id and seq the claimed send was stored under.
+func (dedup *MessageDedup) Complete(sender, clientMsgId, ID string, seq int64, createdAt time.Time) error {
+	value := fmt.Sprintf("%s|%d|%d", ID, createdAt.UnixNano(), seq)
+	return dedup.redisDB.Set(context.Background(), key(sender, clientMsgId), value, dedup.Window).Err()
+}
+
+// Release forgets a claim whose send failed, so the client can retry it.
+func (dedup *MessageDedup) Release(sender, clientMsgId string) error {
+	return dedup.redisDB.Del(context.Background(), key(sender, clientMsgId)).Err()
+}
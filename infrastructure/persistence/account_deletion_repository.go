@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AccountDeletionRepository ...
+type AccountDeletionRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewAccountDeletionRepository ...
+func NewAccountDeletionRepository(db *mongo.Database) *AccountDeletionRepository {
+	return &AccountDeletionRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(ACCOUNT_DELETION),
+	}
+}
+
+var _ repository.AccountDeletionRepository = &AccountDeletionRepository{}
+
+// CreateAccountDeletion ...
+func (repo *AccountDeletionRepository) CreateAccountDeletion(deletion *entity.AccountDeletion) error {
+	_, err := repo.DB.InsertOne(repo.Ctx, deletion)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetAccountDeletion returns the deletion of member that has not run yet.
+func (repo *AccountDeletionRepository) GetAccountDeletion(member string) (*entity.AccountDeletion, error) {
+	var deletion entity.AccountDeletion
+	filter := bson.M{"member": member, "status": bson.M{"$in": bson.A{entity.DELETION_PENDING, entity.DELETION_RUNNING}}}
+	err := repo.DB.FindOne(repo.Ctx, filter).Decode(&deletion)
+	if err != nil {
+		return nil, util.GetError("account_deletion_not_found")
+	}
+	return &deletion, nil
+}
+
+// CancelAccountDeletion cancels the pending deletion of member. A deletion
+// that already started cannot be cancelled.
+func (repo *AccountDeletionRepository) CancelAccountDeletion(member string) error {
+	filter := bson.M{"member": member, "status": entity.DELETION_PENDING}
+	result, err := repo.DB.UpdateOne(repo.Ctx, filter, bson.M{
+		"$set":   bson.M{"status": entity.DELETION_CANCELLED},
+		"$unset": bson.M{"email": ""},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.MatchedCount == 0 {
+		return util.GetError("account_deletion_not_found")
+	}
+	return nil
+}
+
+// GetDueAccountDeletions lists the pending deletions whose grace period has
+// ended and the running ones whose claim has run out.
+func (repo *AccountDeletionRepository) GetDueAccountDeletions(now time.Time) ([]entity.AccountDeletion, error) {
+	deletions := []entity.AccountDeletion{}
+	filter := dueAccountDeletions(now)
+	cursor, err := repo.DB.Find(repo.Ctx, filter)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &deletions)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return deletions, nil
+}
+
+// ClaimAccountDeletion marks the due deletion with ID as running for
+// DELETION_LEASE, so only one replica carries it out and the member can no
+// longer cancel it.
+func (repo *AccountDeletionRepository) ClaimAccountDeletion(ID string, now time.Time) (*entity.AccountDeletion, error) {
+	var deletion entity.AccountDeletion
+	after := options.After
+	filter := dueAccountDeletions(now)
+	filter["id"] = ID
+	err := repo.DB.FindOneAndUpdate(repo.Ctx, filter, bson.M{
+		"$set": bson.M{"status": entity.DELETION_RUNNING, "claimed_until": now.Add(entity.DELETION_LEASE)},
+	}, &options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&deletion)
+	if err != nil {
+		return nil, util.GetError("account_deletion_not_found")
+	}
+	return &deletion, nil
+}
+
+// SetAccountDeletionStatus sets the status of the deletion with ID and drops
+// its claim.
+func (repo *AccountDeletionRepository) SetAccountDeletionStatus(ID, status string) error {
+	_, err := repo.DB.UpdateOne(repo.Ctx, bson.M{"id": ID}, bson.M{
+		"$set":   bson.M{"status": status},
+		"$unset": bson.M{"claimed_until": ""},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// CompleteAccountDeletion marks the deletion with ID as done and drops the
+// email it kept for the confirmation.
+func (repo *AccountDeletionRepository) CompleteAccountDeletion(ID string, completedAt time.Time) error {
+	_, err := repo.DB.UpdateOne(repo.Ctx, bson.M{"id": ID}, bson.M{
+		"$set":   bson.M{"status": entity.DELETION_DONE, "completed_at": completedAt},
+		"$unset": bson.M{"email": "", "claimed_until": ""},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// dueAccountDeletions matches the pending deletions whose grace period has
+// ended, and the running ones whose claim ran out before now or that were
+// claimed without one.
+func dueAccountDeletions(now time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"status": entity.DELETION_PENDING, "delete_at": bson.M{"$lte": now}},
+		bson.M{"status": entity.DELETION_RUNNING, "claimed_until": bson.M{"$not": bson.M{"$gt": now}}},
+	}}
+}
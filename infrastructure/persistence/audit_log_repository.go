@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditLogRepository ...
+type AuditLogRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewAuditLogRepository ...
+func NewAuditLogRepository(db *mongo.Database) *AuditLogRepository {
+	return &AuditLogRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(AUDIT_LOG),
+	}
+}
+
+var _ repository.AuditLogRepository = &AuditLogRepository{}
+
+// AddAuditLog ...
+func (repo *AuditLogRepository) AddAuditLog(log *entity.AuditLog) error {
+	_, err := repo.DB.InsertOne(repo.Ctx, log)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetAuditLogs lists entries for target, or every entry when target is
+// empty, newest first.
+func (repo *AuditLogRepository) GetAuditLogs(target string, page int64) ([]entity.AuditLog, error) {
+	logs := []entity.AuditLog{}
+	filter := bson.M{}
+	if target != "" {
+		filter["target"] = target
+	}
+	cursor, err := repo.DB.Find(repo.Ctx, filter, pageOptions(page))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &logs)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return logs, nil
+}
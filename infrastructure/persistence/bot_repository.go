@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BotRepository ...
+type BotRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewBotRepository ...
+func NewBotRepository(db *mongo.Database) *BotRepository {
+	return &BotRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(BOT),
+	}
+}
+
+var _ repository.BotRepository = &BotRepository{}
+
+// CreateBot ...
+func (repo *BotRepository) CreateBot(bot *entity.Bot) (*entity.Bot, error) {
+	_, err := repo.DB.InsertOne(repo.Ctx, bot)
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	return bot, nil
+}
+
+// GetBot ...
+func (repo *BotRepository) GetBot(ID string) (*entity.Bot, error) {
+	return repo.findBot(bson.M{"id": ID})
+}
+
+// GetBots ...
+func (repo *BotRepository) GetBots() ([]entity.Bot, error) {
+	bots := []entity.Bot{}
+	cursor, err := repo.DB.Find(repo.Ctx, bson.M{}, options.Find().SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &bots)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return bots, nil
+}
+
+// GetBotByToken looks a bot up by the hash of its API token.
+func (repo *BotRepository) GetBotByToken(hash string) (*entity.Bot, error) {
+	return repo.findBot(bson.M{"token_hash": hash})
+}
+
+// GetBotByProfile ...
+func (repo *BotRepository) GetBotByProfile(profile string) (*entity.Bot, error) {
+	return repo.findBot(bson.M{"profile": profile})
+}
+
+func (repo *BotRepository) findBot(filter bson.M) (*entity.Bot, error) {
+	var bot entity.Bot
+	err := repo.DB.FindOne(repo.Ctx, filter).Decode(&bot)
+	if err != nil {
+		return nil, util.GetError("bot_not_found")
+	}
+	return &bot, nil
+}
+
+// UpdateBot ...
+func (repo *BotRepository) UpdateBot(bot *entity.Bot) error {
+	filter := bson.M{"id": bot.ID}
+	update := bson.M{"$set": bson.M{
+		"token_hash": bot.TokenHash,
+		"token_hint": bot.TokenHint,
+		"webhook":    bot.Webhook,
+		"updated_at": bot.UpdatedAt,
+	}}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteBot ...
+func (repo *BotRepository) DeleteBot(ID string) error {
+	_, err := repo.DB.DeleteOne(repo.Ctx, bson.M{"id": ID})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
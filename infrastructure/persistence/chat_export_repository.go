@@ -0,0 +1,173 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+// ChatExportRepository ...
+type ChatExportRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewChatExportRepository ...
+func NewChatExportRepository(db *mongo.Database) *ChatExportRepository {
+	return &ChatExportRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(CHAT_EXPORT),
+	}
+}
+
+var _ repository.ChatExportRepository = &ChatExportRepository{}
+
+// CreateIndexes lets a profile have one pending export at a time. Exports
+// left pending before they were claimed never finish, so they are failed
+// first; several of them would keep the index from being built.
+func (repo *ChatExportRepository) CreateIndexes() error {
+	_, err := repo.DB.UpdateMany(repo.Ctx, bson.M{"status": entity.EXPORT_PENDING, "claimed_until": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{"status": entity.EXPORT_FAILED},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = repo.DB.Indexes().CreateOne(repo.Ctx, mongo.IndexModel{
+		Keys:    bsonx.MDoc{"profile": bsonx.Int64(1)},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": entity.EXPORT_PENDING}),
+	})
+	return err
+}
+
+// CreateChatExport ...
+func (repo *ChatExportRepository) CreateChatExport(export *entity.ChatExport) (*entity.ChatExport, error) {
+	_, err := repo.DB.InsertOne(repo.Ctx, export)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, util.GetError("export_pending")
+	}
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	return export, nil
+}
+
+// UpdateChatExport ...
+func (repo *ChatExportRepository) UpdateChatExport(export *entity.ChatExport) error {
+	filter := bson.M{"id": export.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       export.Status,
+			"file":         export.File,
+			"messages":     export.Messages,
+			"completed_at": export.CompletedAt,
+			"expires_at":   export.ExpiresAt,
+		},
+		"$unset": bson.M{"claimed_until": ""},
+	}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// RenewChatExports extends the claim on the pending exports with ids until
+// until.
+func (repo *ChatExportRepository) RenewChatExports(ids []string, until time.Time) error {
+	filter := bson.M{"id": bson.M{"$in": ids}, "status": entity.EXPORT_PENDING}
+	_, err := repo.DB.UpdateMany(repo.Ctx, filter, bson.M{"$set": bson.M{"claimed_until": until}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetAbandonedChatExports lists the pending exports whose claim ran out
+// before now.
+func (repo *ChatExportRepository) GetAbandonedChatExports(now time.Time) ([]entity.ChatExport, error) {
+	return repo.find(abandonedChatExports(now))
+}
+
+// ClaimChatExport takes over the abandoned export with ID for EXPORT_LEASE,
+// so only one replica writes it again.
+func (repo *ChatExportRepository) ClaimChatExport(ID string, now time.Time) (*entity.ChatExport, error) {
+	var export entity.ChatExport
+	after := options.After
+	filter := abandonedChatExports(now)
+	filter["id"] = ID
+	err := repo.DB.FindOneAndUpdate(repo.Ctx, filter, bson.M{
+		"$set": bson.M{"claimed_until": now.Add(entity.EXPORT_LEASE)},
+	}, &options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&export)
+	if err != nil {
+		return nil, util.GetError("export_not_found")
+	}
+	return &export, nil
+}
+
+// GetExpiredChatExports lists the ready exports that expired before now.
+func (repo *ChatExportRepository) GetExpiredChatExports(now time.Time) ([]entity.ChatExport, error) {
+	return repo.find(bson.M{"status": entity.EXPORT_READY, "expires_at": bson.M{"$lte": now}})
+}
+
+// ExpireChatExport marks the export with ID as expired, once its file is
+// gone.
+func (repo *ChatExportRepository) ExpireChatExport(ID string) error {
+	filter := bson.M{"id": ID, "status": entity.EXPORT_READY}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, bson.M{"$set": bson.M{"status": entity.EXPORT_EXPIRED, "file": ""}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+func (repo *ChatExportRepository) find(filter bson.M) ([]entity.ChatExport, error) {
+	exports := []entity.ChatExport{}
+	cursor, err := repo.DB.Find(repo.Ctx, filter)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &exports)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return exports, nil
+}
+
+// GetChatExport ...
+func (repo *ChatExportRepository) GetChatExport(ID, profile string) (*entity.ChatExport, error) {
+	var export entity.ChatExport
+	filter := bson.M{"id": ID, "profile": profile}
+	err := repo.DB.FindOne(repo.Ctx, filter).Decode(&export)
+	if err != nil {
+		return nil, util.GetError("export_not_found")
+	}
+	return &export, nil
+}
+
+// GetChatExports lists every export profile made.
+func (repo *ChatExportRepository) GetChatExports(profile string) ([]entity.ChatExport, error) {
+	return repo.find(bson.M{"profile": profile})
+}
+
+// DeleteChatExports ...
+func (repo *ChatExportRepository) DeleteChatExports(profile string) error {
+	_, err := repo.DB.DeleteMany(repo.Ctx, bson.M{"profile": profile})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// abandonedChatExports matches the pending exports whose claim ran out
+// before now, or that were created without one.
+func abandonedChatExports(now time.Time) bson.M {
+	return bson.M{"status": entity.EXPORT_PENDING, "claimed_until": bson.M{"$not": bson.M{"$gt": now}}}
+}
@@ -0,0 +1,428 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// CHAT_MIGRATION_BATCH is how many writes the chat migration sends at once.
+	CHAT_MIGRATION_BATCH = 500
+	// CHAT_MIGRATION_ID names the chat migration's record in MIGRATION.
+	CHAT_MIGRATION_ID = "chat"
+)
+
+// ErrChatMigrationPending is returned at startup while CHAT holds messages
+// the chat migration has not moved. Live messages take the first seqs of
+// their conversation, so the migration has to run before the server does.
+var ErrChatMigrationPending = errors.New("the chat collection has not been migrated, run cmd/chat-migrate before starting the server")
+
+// ChatMigration moves chats stored the old way, with a copy of every message
+// and a room per side in CHAT and CHAT_ROOM, into MESSAGE and CONVERSATION.
+// The old collections are only read, and running it again skips what was
+// moved already.
+type ChatMigration struct {
+	Ctx context.Context
+	DB  *mongo.Database
+}
+
+// ChatMigrationResult ...
+type ChatMigrationResult struct {
+	Copies        int64
+	Messages      int64
+	Rooms         int64
+	Conversations int64
+	Numbered      int64
+}
+
+// legacyChatMessage is a message copy in CHAT. Each copy belonged to the
+// side named first in its chat id and carried that side's translation.
+type legacyChatMessage struct {
+	entity.ChatMessage `bson:",inline"`
+	ChatId             string                     `bson:"chat_id"`
+	Translation        *entity.MessageTranslation `bson:"translation,omitempty"`
+}
+
+// NewChatMigration ...
+func NewChatMigration(db *mongo.Database) *ChatMigration {
+	return &ChatMigration{
+		Ctx: context.Background(),
+		DB:  db,
+	}
+}
+
+// Run moves messages first, then rooms, so read markers can be mapped to the
+// new message ids. Then it numbers the messages that have no seq yet and
+// records that it completed. The indexes of the new collections are created
+// by NewRepository before it runs.
+func (migration *ChatMigration) Run() (*ChatMigrationResult, error) {
+	var result ChatMigrationResult
+	err := migration.migrateMessages(&result)
+	if err != nil {
+		return nil, err
+	}
+	err = migration.migrateRooms(&result)
+	if err != nil {
+		return nil, err
+	}
+	err = migration.numberMessages(&result)
+	if err != nil {
+		return nil, err
+	}
+	_, err = migration.DB.Collection(MIGRATION).UpdateOne(migration.Ctx, bson.M{"id": CHAT_MIGRATION_ID}, bson.M{
+		"$set": bson.M{"completed_at": util.GetTimeNow()},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Pending reports whether CHAT holds messages and the migration has not
+// completed yet.
+func (migration *ChatMigration) Pending() (bool, error) {
+	legacy, err := migration.DB.Collection(CHAT).CountDocuments(migration.Ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil || legacy == 0 {
+		return false, err
+	}
+	completed, err := migration.DB.Collection(MIGRATION).CountDocuments(migration.Ctx, bson.M{"id": CHAT_MIGRATION_ID})
+	if err != nil {
+		return false, err
+	}
+	return completed == 0, nil
+}
+
+// migrateMessages keeps one record per ref. Copies from before refs existed
+// cannot be matched to each other, so only the sender's copy of those is
+// kept. A copy's translation is kept for the side it belonged to.
+func (migration *ChatMigration) migrateMessages(result *ChatMigrationResult) error {
+	cursor, err := migration.DB.Collection(CHAT).Find(migration.Ctx, bson.M{}, options.Find().SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(migration.Ctx)
+
+	models := make([]mongo.WriteModel, 0, CHAT_MIGRATION_BATCH)
+	for cursor.Next(migration.Ctx) {
+		var legacy legacyChatMessage
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+		result.Copies++
+
+		message := legacy.fold()
+		if message == nil {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": message.ID}).
+			SetUpdate(bson.M{"$setOnInsert": message}).
+			SetUpsert(true))
+
+		if legacy.Translation != nil {
+			owner, _ := entity.ChatParticipants(legacy.ChatId)
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"id": message.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"translations." + owner: legacy.Translation}}))
+		}
+
+		if len(models) >= CHAT_MIGRATION_BATCH {
+			if err := migration.writeMessages(models, result); err != nil {
+				return err
+			}
+			models = models[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(models) > 0 {
+		return migration.writeMessages(models, result)
+	}
+	return nil
+}
+
+// fold returns the record the copy becomes in MESSAGE, under its ref, or nil
+// when the copy is the receiver's copy of a message from before refs.
+func (legacy *legacyChatMessage) fold() *entity.ChatMessage {
+	own := legacy.ChatId == fmt.Sprintf("%s-%s", legacy.Sender, legacy.Receiver)
+	ref := legacy.Ref
+	if ref == "" {
+		if !own {
+			return nil
+		}
+		ref = legacy.ID
+	}
+
+	message := legacy.ChatMessage
+	message.ID = ref
+	message.Ref = ref
+	message.Conversation = entity.ConversationKey(message.Sender, message.Receiver)
+	message.Translations = nil
+	message.HiddenFor = nil
+	if !own {
+		message.ClientMsgId = ""
+	}
+	return &message
+}
+
+// writeMessages runs models in order, since a translation has to land after
+// the upsert that creates its message.
+func (migration *ChatMigration) writeMessages(models []mongo.WriteModel, result *ChatMigrationResult) error {
+	written, err := migration.DB.Collection(MESSAGE).BulkWrite(migration.Ctx, models, options.BulkWrite().SetOrdered(true))
+	if err != nil {
+		return err
+	}
+	result.Messages += written.UpsertedCount
+	return nil
+}
+
+// migrateRooms folds the two rooms of every pair into one conversation,
+// keeping each side's read marker, unread count, mute and auto-translate
+// settings, then points each conversation at its latest message. Unread
+// counts and settings are only copied into conversations this run created,
+// so a rerun does not overwrite what members changed since; read markers
+// only move forward.
+func (migration *ChatMigration) migrateRooms(result *ChatMigrationResult) error {
+	cursor, err := migration.DB.Collection(CHAT_ROOM).Find(migration.Ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(migration.Ctx)
+
+	keys := make(map[string]bool)
+	created := make(map[string]bool)
+	for cursor.Next(migration.Ctx) {
+		var room entity.ChatRoom
+		if err := cursor.Decode(&room); err != nil {
+			return err
+		}
+		if len(room.Receiver) == 0 {
+			continue
+		}
+		result.Rooms++
+
+		var conversation entity.Conversation
+		conversation.PrepareConversation(room.Sender, room.Receiver[0])
+		conversation.CreatedAt = room.CreatedAt
+		conversation.UpdatedAt = room.CreatedAt
+		upserted, err := migration.DB.Collection(CONVERSATION).UpdateOne(migration.Ctx, bson.M{"id": conversation.ID}, bson.M{
+			"$setOnInsert": &conversation,
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		keys[conversation.ID] = true
+		if upserted.UpsertedCount > 0 {
+			created[conversation.ID] = true
+		}
+
+		update := roomUpdate(&room, migration.messageId(room.LastReadId), created[conversation.ID])
+		if len(update) == 0 {
+			continue
+		}
+		_, err = migration.DB.Collection(CONVERSATION).UpdateOne(migration.Ctx, memberFilter(room.Sender, room.Receiver[0]), update)
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	for key := range keys {
+		var last entity.ChatMessage
+		opts := options.FindOne().SetSort(bson.M{"id": -1})
+		err := migration.DB.Collection(MESSAGE).FindOne(migration.Ctx, bson.M{"conversation": key}, opts).Decode(&last)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = migration.DB.Collection(CONVERSATION).UpdateOne(migration.Ctx, bson.M{"id": key}, bson.M{"$set": bson.M{
+			"last_message_id": last.ID,
+			"last_message":    last.RoomMessage(),
+			"last_sender":     last.Sender,
+			"updated_at":      last.CreatedAt,
+		}})
+		if err != nil {
+			return err
+		}
+		result.Conversations++
+	}
+	return nil
+}
+
+// roomUpdate is the update that carries room over to its side of the
+// conversation. readId is the room's read marker mapped to MESSAGE. The
+// unread count and settings are only copied when created is set.
+func roomUpdate(room *entity.ChatRoom, readId string, created bool) bson.M {
+	update := bson.M{}
+	if created {
+		// rooms written before unread_count existed count as one unread message.
+		unread := room.UnreadCount
+		if !room.IsRead && unread == 0 {
+			unread = 1
+		}
+		set := bson.M{
+			"members.$.unread_count": unread,
+			"members.$.muted":        room.Muted,
+		}
+		unset := bson.M{}
+		if room.MutedUntil != nil {
+			set["members.$.muted_until"] = room.MutedUntil
+		} else {
+			unset["members.$.muted_until"] = ""
+		}
+		if room.TranslateTo != "" {
+			set["members.$.translate_to"] = room.TranslateTo
+		} else {
+			unset["members.$.translate_to"] = ""
+		}
+		update["$set"] = set
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+	}
+	if readId != "" {
+		update["$max"] = bson.M{"members.$.last_read_id": readId}
+	}
+	return update
+}
+
+// numberMessages gives the messages of every conversation that have no seq
+// the next seqs of their conversation, in id order, then moves the read
+// markers and cleared marks kept as ids over to seqs.
+func (migration *ChatMigration) numberMessages(result *ChatMigrationResult) error {
+	cursor, err := migration.DB.Collection(CONVERSATION).Find(migration.Ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(migration.Ctx)
+
+	for cursor.Next(migration.Ctx) {
+		var conversation struct {
+			entity.Conversation `bson:",inline"`
+			Members             []struct {
+				entity.ConversationMember `bson:",inline"`
+				ClearedId                 string `bson:"cleared_id,omitempty"`
+			} `bson:"members"`
+		}
+		if err := cursor.Decode(&conversation); err != nil {
+			return err
+		}
+		seq, err := migration.numberConversation(conversation.ID, conversation.Seq, result)
+		if err != nil {
+			return err
+		}
+
+		set := bson.M{"seq": seq}
+		if lastSeq := migration.seqAt(conversation.ID, conversation.LastMessageId); lastSeq > 0 {
+			set["last_message_seq"] = lastSeq
+		}
+		for index, member := range conversation.Members {
+			if member.LastReadSeq == 0 {
+				set[fmt.Sprintf("members.%d.last_read_seq", index)] = migration.seqAt(conversation.ID, member.LastReadId)
+			}
+			if member.ClearedSeq == 0 && member.ClearedId != "" {
+				set[fmt.Sprintf("members.%d.cleared_seq", index)] = migration.seqAt(conversation.ID, member.ClearedId)
+			}
+		}
+		_, err = migration.DB.Collection(CONVERSATION).UpdateOne(migration.Ctx, bson.M{"id": conversation.ID}, bson.M{"$set": set})
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// numberConversation numbers the messages of the conversation with key that
+// have no seq, after seq, and returns the last seq it gave. Legacy messages
+// only get the first seqs because the server refuses to start before the
+// migration completed; see ErrChatMigrationPending.
+func (migration *ChatMigration) numberConversation(key string, seq int64, result *ChatMigrationResult) (int64, error) {
+	filter := bson.M{"conversation": key, "seq": bson.M{"$not": bson.M{"$gt": 0}}}
+	opts := options.Find().SetSort(bson.M{"id": 1}).SetProjection(bson.M{"id": 1})
+	cursor, err := migration.DB.Collection(MESSAGE).Find(migration.Ctx, filter, opts)
+	if err != nil {
+		return seq, err
+	}
+	defer cursor.Close(migration.Ctx)
+
+	models := make([]mongo.WriteModel, 0, CHAT_MIGRATION_BATCH)
+	for cursor.Next(migration.Ctx) {
+		var message entity.ChatMessage
+		if err := cursor.Decode(&message); err != nil {
+			return seq, err
+		}
+		seq++
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": message.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"seq": seq}}))
+
+		if len(models) >= CHAT_MIGRATION_BATCH {
+			if _, err := migration.DB.Collection(MESSAGE).BulkWrite(migration.Ctx, models); err != nil {
+				return seq, err
+			}
+			result.Numbered += int64(len(models))
+			models = models[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return seq, err
+	}
+	if len(models) > 0 {
+		if _, err := migration.DB.Collection(MESSAGE).BulkWrite(migration.Ctx, models); err != nil {
+			return seq, err
+		}
+		result.Numbered += int64(len(models))
+	}
+	return seq, nil
+}
+
+// seqAt returns the seq of the latest message of the conversation with key
+// whose id is not after ID, or zero when there is none.
+func (migration *ChatMigration) seqAt(key, ID string) int64 {
+	if ID == "" {
+		return 0
+	}
+	var message entity.ChatMessage
+	opts := options.FindOne().SetSort(bson.M{"id": -1})
+	filter := bson.M{"conversation": key, "id": bson.M{"$lte": ID}}
+	if err := migration.DB.Collection(MESSAGE).FindOne(migration.Ctx, filter, opts).Decode(&message); err != nil {
+		return 0
+	}
+	return message.Seq
+}
+
+// messageId maps the id of a copy in CHAT to the id its message has in
+// MESSAGE. Ids that are not found are kept, since ids only move forward.
+func (migration *ChatMigration) messageId(ID string) string {
+	if ID == "" {
+		return ""
+	}
+	var legacy legacyChatMessage
+	err := migration.DB.Collection(CHAT).FindOne(migration.Ctx, bson.M{"id": ID}).Decode(&legacy)
+	if err != nil {
+		return ID
+	}
+	return legacy.markerId()
+}
+
+// markerId is the id a read marker pointing at the copy takes in MESSAGE.
+// Copies from before refs were kept under their own id.
+func (legacy *legacyChatMessage) markerId() string {
+	if legacy.Ref == "" {
+		return legacy.ID
+	}
+	return legacy.Ref
+}
@@ -0,0 +1,66 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func legacyCopy(chatId, ID, ref string) *legacyChatMessage {
+	return &legacyChatMessage{
+		ChatMessage: entity.ChatMessage{
+			ID:          ID,
+			Ref:         ref,
+			ClientMsgId: "client-1",
+			Sender:      "b",
+			Receiver:    "a",
+			Message:     "hello",
+			HiddenFor:   []string{"b"},
+		},
+		ChatId: chatId,
+	}
+}
+
+func Test_FoldLegacyCopies(t *testing.T) {
+	own := legacyCopy("b-a", "01A", "01R").fold()
+	other := legacyCopy("a-b", "01B", "01R").fold()
+
+	assert.Equal(t, "01R", own.ID)
+	assert.Equal(t, "01R", own.Ref)
+	assert.Equal(t, "a-b", own.Conversation)
+	assert.Equal(t, "client-1", own.ClientMsgId)
+	assert.Nil(t, own.HiddenFor)
+	assert.Equal(t, own.ID, other.ID)
+	assert.Empty(t, other.ClientMsgId)
+
+	old := legacyCopy("b-a", "01A", "").fold()
+	assert.Equal(t, "01A", old.ID)
+	assert.Equal(t, "01A", old.Ref)
+	assert.Nil(t, legacyCopy("a-b", "01B", "").fold())
+}
+
+func Test_LegacyReadMarker(t *testing.T) {
+	assert.Equal(t, "01R", legacyCopy("a-b", "01B", "01R").markerId())
+	assert.Equal(t, "01A", legacyCopy("b-a", "01A", "").markerId())
+}
+
+func Test_RoomUpdate(t *testing.T) {
+	room := &entity.ChatRoom{Sender: "a", Receiver: []string{"b"}, TranslateTo: "ar"}
+
+	update := roomUpdate(room, "01R", true)
+	assert.Equal(t, bson.M{"members.$.last_read_id": "01R"}, update["$max"])
+	set := update["$set"].(bson.M)
+	assert.Equal(t, int64(1), set["members.$.unread_count"])
+	assert.Equal(t, "ar", set["members.$.translate_to"])
+	assert.Equal(t, bson.M{"members.$.muted_until": ""}, update["$unset"])
+
+	room.IsRead = true
+	assert.Equal(t, int64(0), roomUpdate(room, "", true)["$set"].(bson.M)["members.$.unread_count"])
+
+	update = roomUpdate(room, "01R", false)
+	assert.Equal(t, bson.M{"$max": bson.M{"members.$.last_read_id": "01R"}}, update)
+	assert.Empty(t, roomUpdate(room, "", false))
+}
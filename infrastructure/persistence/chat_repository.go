@@ -2,13 +2,18 @@ package persistence
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/majid-cj/go-chat-server/domain/entity"
 	"github.com/majid-cj/go-chat-server/domain/repository"
 	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
 )
 
 // ChatRepository ...
@@ -27,32 +32,278 @@ func NewChatRepository(db *mongo.Database) *ChatRepository {
 
 var _ repository.ChatRepository = &ChatRepository{}
 
-// AddNewChatMessage ...
-func (repo *ChatRepository) AddNewChatMessage(message *entity.ChatMessage) error {
-	_, err := repo.DB.Collection(CHAT).InsertOne(repo.Ctx, &message)
+// CreateIndexes creates the indexes MESSAGE and CONVERSATION rely on. The
+// unique conversation id keeps concurrent first messages from inserting the
+// same conversation twice.
+func (repo *ChatRepository) CreateIndexes() error {
+	_, err := repo.DB.Collection(MESSAGE).Indexes().CreateMany(repo.Ctx, []mongo.IndexModel{
+		{Keys: bsonx.MDoc{"id": bsonx.Int64(1)}, Options: options.Index().SetUnique(true)},
+		{Keys: bsonx.Doc{{Key: "conversation", Value: bsonx.Int64(1)}, {Key: "id", Value: bsonx.Int64(1)}}},
+		{Keys: bsonx.Doc{{Key: "conversation", Value: bsonx.Int64(1)}, {Key: "seq", Value: bsonx.Int64(1)}}},
+	})
 	if err != nil {
 		return err
 	}
+	_, err = repo.DB.Collection(CONVERSATION).Indexes().CreateMany(repo.Ctx, []mongo.IndexModel{
+		{Keys: bsonx.MDoc{"id": bsonx.Int64(1)}, Options: options.Index().SetUnique(true)},
+		{Keys: bsonx.Doc{{Key: "participants", Value: bsonx.Int64(1)}, {Key: "updated_at", Value: bsonx.Int64(-1)}}},
+	})
+	return err
+}
+
+// AddChatMessage gives message the next seq of its conversation, stores it
+// once and moves the conversation forward: it shows up again for members who
+// hid it, the sender has read up to the message and the receiver has one
+// more unread message, unless read is set because the receiver is looking at
+// the chat.
+func (repo *ChatRepository) AddChatMessage(message *entity.ChatMessage, read bool) error {
+	seq, err := repo.nextSeq(message.Sender, message.Receiver, 1)
+	if err != nil {
+		return err
+	}
+	message.Seq = seq
+	_, err = repo.DB.Collection(MESSAGE).InsertOne(repo.Ctx, message)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+
+	set := bson.M{
+		"members.$[sender].hidden":            false,
+		"members.$[sender].unread_count":      0,
+		"members.$[senderread].last_read_id":  message.ID,
+		"members.$[senderread].last_read_seq": seq,
+	}
+	update := bson.M{"$set": set}
+	filters := []interface{}{
+		bson.M{"sender.profile": message.Sender},
+		bson.M{"senderread.profile": message.Sender, "senderread.last_read_seq": bson.M{"$lt": seq}},
+	}
+	if message.Receiver != message.Sender {
+		set["members.$[receiver].hidden"] = false
+		filters = append(filters, bson.M{"receiver.profile": message.Receiver})
+		if read {
+			set["members.$[receiver].unread_count"] = 0
+			set["members.$[receiverread].last_read_id"] = message.ID
+			set["members.$[receiverread].last_read_seq"] = seq
+			filters = append(filters, bson.M{"receiverread.profile": message.Receiver, "receiverread.last_read_seq": bson.M{"$lt": seq}})
+		} else {
+			update["$inc"] = bson.M{"members.$[receiver].unread_count": 1}
+		}
+	}
+	key := message.Conversation
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters})
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": key}, update, opts)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+
+	// sends that raced each other may finish out of order, so the last
+	// message only moves forward.
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": key, "last_message_seq": bson.M{"$lt": seq}}, bson.M{
+		"$set": bson.M{
+			"last_message_id":  message.ID,
+			"last_message_seq": seq,
+			"last_message":     message.RoomMessage(),
+			"last_sender":      message.Sender,
+			"updated_at":       message.CreatedAt,
+		},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
 	return nil
 }
 
-// ReadChatMessage ...
-func (repo *ChatRepository) ReadChatMessage(sender, receiver string) error {
-	filter := bson.M{"sender": sender, "receiver": bson.M{"$in": []string{receiver}}, "is_read": false}
+// SetChatMessagePreview ...
+func (repo *ChatRepository) SetChatMessagePreview(ref string, preview *linkpreview.Preview) error {
+	filter := bson.M{"id": ref}
+	update := bson.M{"$set": bson.M{
+		"preview": preview,
+	}}
+	_, err := repo.DB.Collection(MESSAGE).UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// SetChatMessagePoll copies poll onto its message.
+func (repo *ChatRepository) SetChatMessagePoll(ref string, poll *entity.Poll) error {
+	_, err := repo.DB.Collection(MESSAGE).UpdateOne(repo.Ctx, bson.M{"id": ref}, bson.M{"$set": bson.M{
+		"poll": poll,
+	}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// SetChatMessageTranslation attaches translation to the message with ID for
+// profile only; the other participant does not see it.
+func (repo *ChatRepository) SetChatMessageTranslation(ID, profile string, translation *entity.MessageTranslation) error {
+	_, err := repo.DB.Collection(MESSAGE).UpdateOne(repo.Ctx, bson.M{"id": ID}, bson.M{"$set": bson.M{
+		"translations." + profile: translation,
+	}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// SetChatMessagePlayed marks a voice note as played by its receiver.
+func (repo *ChatRepository) SetChatMessagePlayed(ref, receiver string, playedAt time.Time) (*entity.ChatMessage, error) {
+	var message entity.ChatMessage
+	filter := bson.M{"id": ref, "receiver": receiver, "type": entity.MESSAGE_VOICE}
+	err := repo.DB.Collection(MESSAGE).FindOne(repo.Ctx, filter).Decode(&message)
+	if err != nil {
+		return nil, util.GetError("message_not_found")
+	}
+	message.ViewFor(receiver)
+	if message.PlayedAt != nil {
+		return &message, nil
+	}
+
 	update := bson.M{"$set": bson.M{
-		"is_read": true,
+		"played_at": playedAt,
 	}}
-	_, err := repo.DB.Collection(CHAT_ROOM).UpdateMany(repo.Ctx, filter, update)
+	_, err = repo.DB.Collection(MESSAGE).UpdateOne(repo.Ctx, filter, update)
 	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	message.PlayedAt = &playedAt
+	return &message, nil
+}
+
+// SetChatMessageReaction sets profile's reaction to message ID, or removes it
+// when emoji is empty. Either participant can react to a message it can see.
+func (repo *ChatRepository) SetChatMessageReaction(ID, profile, emoji string) (*entity.ChatMessage, error) {
+	var message entity.ChatMessage
+	after := options.After
+	filter := bson.M{
+		"id":         ID,
+		"$or":        bson.A{bson.M{"sender": profile}, bson.M{"receiver": profile}},
+		"hidden_for": bson.M{"$ne": profile},
+	}
+	update := bson.M{"$set": bson.M{"reactions." + profile: emoji}}
+	if emoji == "" {
+		update = bson.M{"$unset": bson.M{"reactions." + profile: ""}}
+	}
+	err := repo.DB.Collection(MESSAGE).FindOneAndUpdate(repo.Ctx, filter, update,
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&message)
+	if err != nil {
+		return nil, util.GetError("message_not_found")
+	}
+	message.ViewFor(profile)
+	return &message, nil
+}
+
+// DeleteProfileReactions removes profile's reactions from every message.
+func (repo *ChatRepository) DeleteProfileReactions(profile string) error {
+	field := "reactions." + profile
+	_, err := repo.DB.Collection(MESSAGE).UpdateMany(repo.Ctx, bson.M{field: bson.M{"$exists": true}}, bson.M{
+		"$unset": bson.M{field: ""},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// EditChatMessage replaces the text of sender's text message ref and drops
+// what was derived from the old text: its translations and link preview.
+// The conversation's last message follows when ref is the latest.
+func (repo *ChatRepository) EditChatMessage(ref, sender, text string, editedAt time.Time) (*entity.ChatMessage, error) {
+	var message entity.ChatMessage
+	after := options.After
+	filter := bson.M{"id": ref, "sender": sender, "type": entity.MESSAGE_TEXT}
+	err := repo.DB.Collection(MESSAGE).FindOneAndUpdate(repo.Ctx, filter, bson.M{
+		"$set":   bson.M{"message": text, "edited_at": editedAt},
+		"$unset": bson.M{"translations": "", "preview": ""},
+	}, &options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&message)
+	if err != nil {
+		return nil, util.GetError("message_not_found")
+	}
+
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": message.Conversation, "last_message_id": ref}, bson.M{
+		"$set": bson.M{"last_message": message.RoomMessage()},
+	})
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	message.ViewFor(sender)
+	return &message, nil
+}
+
+// ReadChatMessage marks the whole chat as read up to its latest message.
+func (repo *ChatRepository) ReadChatMessage(sender, receiver string) error {
+	_, _, err := repo.MarkChatMessagesRead(sender, receiver, 0)
+	if err != nil && err.Error() != "message_not_found" {
 		return err
 	}
 	return nil
 }
 
-// GetChatHistory ...
+// MarkChatMessagesRead moves the sender's read marker in the conversation
+// forward to seq (the latest message when zero) and recounts what is left
+// unread. It returns the latest message up to seq and the remaining unread
+// count.
+func (repo *ChatRepository) MarkChatMessagesRead(sender, receiver string, seq int64) (*entity.ChatMessage, int64, error) {
+	var message entity.ChatMessage
+	key := entity.ConversationKey(sender, receiver)
+
+	filter := bson.M{"conversation": key, "hidden_for": bson.M{"$ne": sender}}
+	if seq > 0 {
+		filter["seq"] = bson.M{"$lte": seq}
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})
+	err := repo.DB.Collection(MESSAGE).FindOne(repo.Ctx, filter, opts).Decode(&message)
+	if err != nil {
+		return nil, 0, util.GetError("message_not_found")
+	}
+	message.ViewFor(sender)
+
+	behind := bson.M{"id": key, "members": bson.M{"$elemMatch": bson.M{
+		"profile":       sender,
+		"last_read_seq": bson.M{"$lt": message.Seq},
+	}}}
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, behind, bson.M{"$set": bson.M{
+		"members.$.last_read_id":  message.ID,
+		"members.$.last_read_seq": message.Seq,
+	}})
+	if err != nil {
+		return nil, 0, util.GetError("general_error")
+	}
+	conversation, err := repo.GetConversation(sender, receiver)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unread, err := repo.DB.Collection(MESSAGE).CountDocuments(repo.Ctx, bson.M{
+		"conversation": key,
+		"sender":       receiver,
+		"hidden_for":   bson.M{"$ne": sender},
+		"seq":          bson.M{"$gt": conversation.Member(sender).LastReadSeq},
+	})
+	if err != nil {
+		return nil, 0, util.GetError("general_error")
+	}
+
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, memberFilter(sender, receiver), bson.M{"$set": bson.M{
+		"members.$.unread_count": unread,
+	}})
+	if err != nil {
+		return nil, 0, util.GetError("general_error")
+	}
+	return &message, unread, nil
+}
+
+// GetChatHistory returns what the owner of the chat id key can still see of
+// the conversation, oldest first.
 func (repo *ChatRepository) GetChatHistory(key string) (entity.ChatMessageHistory, error) {
 	var messages entity.ChatMessageHistory
-	cursor, err := repo.DB.Collection(CHAT).Find(repo.Ctx, bson.M{"chat_id": key})
+	profile, other := entity.ChatParticipants(key)
+	opts := options.Find().SetSort(bson.M{"seq": 1})
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, repo.historyFilter(profile, other), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -60,69 +311,701 @@ func (repo *ChatRepository) GetChatHistory(key string) (entity.ChatMessageHistor
 	if err != nil {
 		return nil, err
 	}
+	for index := range messages {
+		messages[index].ViewFor(profile)
+	}
 	return messages, nil
 }
 
-// AddChatRoom ...
-func (repo *ChatRepository) AddChatRoom(room *entity.ChatRoom) error {
-	filter := bson.M{"sender": room.Sender, "receiver": bson.M{"$in": room.Receiver}}
-	update := bson.M{"$set": bson.M{
-		"id":         room.ID,
-		"sender":     room.Sender,
-		"receiver":   room.Receiver,
-		"message":    room.Message,
-		"is_read":    room.IsRead,
-		"created_at": room.CreatedAt,
-	}}
-	upsert := true
-	_, err := repo.DB.Collection(CHAT_ROOM).UpdateOne(repo.Ctx, filter, update, &options.UpdateOptions{
-		Upsert: &upsert,
+// GetChatMessagesBySeq returns what the owner of the chat id key can see of
+// the messages after seq after and, when before is not zero, before seq
+// before, in seq order. A limit of zero returns the whole range.
+func (repo *ChatRepository) GetChatMessagesBySeq(key string, after, before, limit int64) (entity.ChatMessageHistory, error) {
+	messages := entity.ChatMessageHistory{}
+	profile, other := entity.ChatParticipants(key)
+	filter := repo.historyFilter(profile, other)
+	seq, ok := filter["seq"].(bson.M)
+	if !ok {
+		seq = bson.M{}
+	}
+	if cleared, _ := seq["$gt"].(int64); after > cleared {
+		seq["$gt"] = after
+	}
+	if before > 0 {
+		seq["$lt"] = before
+	}
+	if len(seq) > 0 {
+		filter["seq"] = seq
+	}
+
+	opts := options.Find().SetSort(bson.M{"seq": 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, filter, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &messages)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	for index := range messages {
+		messages[index].ViewFor(profile)
+	}
+	return messages, nil
+}
+
+// GetLatestChatMessages returns what the owner of the chat id key can see of
+// the conversation, newest first, before the seq before when it is set.
+func (repo *ChatRepository) GetLatestChatMessages(key string, before, limit int64) (entity.ChatMessageHistory, error) {
+	messages := entity.ChatMessageHistory{}
+	profile, other := entity.ChatParticipants(key)
+	filter := repo.historyFilter(profile, other)
+	if before > 0 {
+		seq, ok := filter["seq"].(bson.M)
+		if !ok {
+			seq = bson.M{}
+		}
+		seq["$lt"] = before
+		filter["seq"] = seq
+	}
+
+	opts := options.Find().SetSort(bson.M{"seq": -1}).SetLimit(limit)
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, filter, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &messages)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	for index := range messages {
+		messages[index].ViewFor(profile)
+	}
+	return messages, nil
+}
+
+// GetChatMessage returns the message with ID when profile takes part in it
+// and has not hidden it.
+func (repo *ChatRepository) GetChatMessage(profile, ID string) (*entity.ChatMessage, error) {
+	var message entity.ChatMessage
+	filter := bson.M{
+		"id":         ID,
+		"$or":        bson.A{bson.M{"sender": profile}, bson.M{"receiver": profile}},
+		"hidden_for": bson.M{"$ne": profile},
+	}
+	err := repo.DB.Collection(MESSAGE).FindOne(repo.Ctx, filter).Decode(&message)
+	if err != nil {
+		return nil, util.GetError("message_not_found")
+	}
+	message.ViewFor(profile)
+	return &message, nil
+}
+
+// GetChatMessagesByRefs returns the messages with refs that profile can
+// see, newest first.
+func (repo *ChatRepository) GetChatMessagesByRefs(profile string, refs []string) (entity.ChatMessageHistory, error) {
+	messages := entity.ChatMessageHistory{}
+	if len(refs) == 0 {
+		return messages, nil
+	}
+	filter := bson.M{
+		"id":         bson.M{"$in": refs},
+		"$or":        bson.A{bson.M{"sender": profile}, bson.M{"receiver": profile}},
+		"hidden_for": bson.M{"$ne": profile},
+	}
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, filter, options.Find().SetSort(bson.M{"id": -1}))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &messages)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	for index := range messages {
+		messages[index].ViewFor(profile)
+	}
+	return messages, nil
+}
+
+// GetChatContext returns up to size messages on each side of messageId in
+// the chat, oldest first. Without a message id it returns the latest size
+// messages. Messages the owner of the chat id hid are included, since the
+// context is kept for moderators.
+func (repo *ChatRepository) GetChatContext(key, messageId string, size int64) (entity.ChatMessageHistory, error) {
+	var before, after entity.ChatMessageHistory
+	profile, other := entity.ChatParticipants(key)
+	filter := bson.M{"conversation": entity.ConversationKey(profile, other)}
+	var pivot entity.ChatMessage
+	if messageId != "" {
+		err := repo.DB.Collection(MESSAGE).FindOne(repo.Ctx, bson.M{"conversation": filter["conversation"], "id": messageId}).Decode(&pivot)
+		if err != nil {
+			return nil, util.GetError("message_not_found")
+		}
+		filter["seq"] = bson.M{"$lte": pivot.Seq}
+	}
+	opts := options.Find().SetSort(bson.M{"seq": -1}).SetLimit(size)
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, filter, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	if err = cursor.All(repo.Ctx, &before); err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+
+	if messageId != "" {
+		filter["seq"] = bson.M{"$gt": pivot.Seq}
+		opts = options.Find().SetSort(bson.M{"seq": 1}).SetLimit(size)
+		cursor, err = repo.DB.Collection(MESSAGE).Find(repo.Ctx, filter, opts)
+		if err != nil {
+			return nil, util.GetError("error_retrieve")
+		}
+		if err = cursor.All(repo.Ctx, &after); err != nil {
+			return nil, util.GetError("error_retrieve")
+		}
+	}
+
+	history := make(entity.ChatMessageHistory, 0, len(before)+len(after))
+	for index := len(before) - 1; index >= 0; index-- {
+		history = append(history, before[index])
+	}
+	history = append(history, after...)
+	for index := range history {
+		history[index].ViewFor(profile)
+	}
+	return history, nil
+}
+
+// HideChatMessage removes the message with ID from profile's history. The
+// other participant still sees it.
+func (repo *ChatRepository) HideChatMessage(profile, ID string) error {
+	filter := bson.M{"id": ID, "$or": bson.A{bson.M{"sender": profile}, bson.M{"receiver": profile}}}
+	result, err := repo.DB.Collection(MESSAGE).UpdateOne(repo.Ctx, filter, bson.M{
+		"$addToSet": bson.M{"hidden_for": profile},
 	})
 	if err != nil {
 		return util.GetError("general_error")
 	}
+	if result.MatchedCount == 0 {
+		return util.GetError("message_not_found")
+	}
 	return nil
 }
 
-// GetChatList ...
-func (repo *ChatRepository) GetChatList(sender string) (entity.ChatList, error) {
-	var chatList entity.ChatList
-	match := bson.D{{"$match", bson.M{
-		"sender": sender,
-	}}}
-	lookupReceiver := bson.D{{
-		"$lookup", bson.D{{"from", PROFILE}, {"localField", "receiver"}, {"foreignField", "id"}, {"as", "receiver"}},
-	}}
-	project := bson.D{{"$project", bson.M{
-		"_id":          0,
-		"receiver._id": 0,
-	}}}
-	sort := bson.D{{
-		"$sort", bson.M{"created_at": -1},
-	}}
+// DeleteChatMessage removes a message for both participants.
+func (repo *ChatRepository) DeleteChatMessage(ref string) error {
+	result, err := repo.DB.Collection(MESSAGE).DeleteOne(repo.Ctx, bson.M{"id": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.DeletedCount == 0 {
+		return util.GetError("message_not_found")
+	}
+	return nil
+}
 
-	cursor, err := repo.DB.Collection(CHAT_ROOM).Aggregate(repo.Ctx, mongo.Pipeline{
-		match,
-		lookupReceiver,
-		project,
-		sort,
+// IterateChatHistory walks what the owner of the chat id key can see of the
+// conversation in seq order, without loading it into memory.
+func (repo *ChatRepository) IterateChatHistory(key string, handle func(*entity.ChatMessage) error) (int64, error) {
+	var count int64
+	profile, other := entity.ChatParticipants(key)
+	opts := options.Find().SetSort(bson.M{"seq": 1})
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, repo.historyFilter(profile, other), opts)
+	if err != nil {
+		return count, util.GetError("error_retrieve")
+	}
+	defer cursor.Close(repo.Ctx)
+
+	for cursor.Next(repo.Ctx) {
+		var message entity.ChatMessage
+		if err := cursor.Decode(&message); err != nil {
+			return count, util.GetError("error_retrieve")
+		}
+		message.ViewFor(profile)
+		if err := handle(&message); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
+
+// IterateSentMessages walks every message profile sent, in any
+// conversation.
+func (repo *ChatRepository) IterateSentMessages(profile string, handle func(*entity.ChatMessage) error) (int64, error) {
+	var count int64
+	cursor, err := repo.DB.Collection(MESSAGE).Find(repo.Ctx, bson.M{"sender": profile})
+	if err != nil {
+		return count, util.GetError("error_retrieve")
+	}
+	defer cursor.Close(repo.Ctx)
+
+	for cursor.Next(repo.Ctx) {
+		var message entity.ChatMessage
+		if err := cursor.Decode(&message); err != nil {
+			return count, util.GetError("error_retrieve")
+		}
+		if err := handle(&message); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
+
+// DeleteSentMessages removes every message profile sent, for both
+// participants.
+func (repo *ChatRepository) DeleteSentMessages(profile string) error {
+	_, err := repo.DB.Collection(MESSAGE).DeleteMany(repo.Ctx, bson.M{"sender": profile})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// LeaveConversations takes profile out of its conversations once its sent
+// messages are gone. The other participant keeps the conversation and the
+// messages it sent, with nothing left unread since everything it received
+// came from profile.
+func (repo *ChatRepository) LeaveConversations(profile string) error {
+	_, err := repo.DB.Collection(CONVERSATION).UpdateMany(repo.Ctx, bson.M{"participants": profile, "last_sender": profile}, bson.M{
+		"$set": bson.M{"last_message_id": "", "last_message": "", "last_sender": ""},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	_, err = repo.DB.Collection(CONVERSATION).UpdateMany(repo.Ctx, bson.M{"participants": profile}, bson.M{
+		"$set": bson.M{"members.$[].unread_count": 0},
 	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	_, err = repo.DB.Collection(CONVERSATION).UpdateMany(repo.Ctx, bson.M{"participants": profile}, bson.M{
+		"$pull": bson.M{"members": bson.M{"profile": profile}},
+	})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// CreateConversation starts the conversation of profile and other, or
+// returns it when it exists. An existing conversation profile had hidden is
+// put back on profile's chat list. The bool tells whether it was created.
+func (repo *ChatRepository) CreateConversation(profile, other string) (*entity.Conversation, bool, error) {
+	var conversation entity.Conversation
+	conversation.PrepareConversation(profile, other)
+	result, err := repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": conversation.ID}, bson.M{
+		"$setOnInsert": &conversation,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, false, util.GetError("general_error")
+	}
+	if result.UpsertedCount > 0 {
+		return &conversation, true, nil
+	}
+
+	err = repo.updateMember(profile, other, bson.M{"$set": bson.M{"members.$.hidden": false}})
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := repo.GetConversation(profile, other)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// GetConversation ...
+func (repo *ChatRepository) GetConversation(profile, other string) (*entity.Conversation, error) {
+	var conversation entity.Conversation
+	filter := bson.M{"id": entity.ConversationKey(profile, other), "participants": profile}
+	err := repo.DB.Collection(CONVERSATION).FindOne(repo.Ctx, filter).Decode(&conversation)
+	if err != nil {
+		return nil, util.GetError("chat_not_found")
+	}
+	return &conversation, nil
+}
+
+// GetConversations lists up to limit conversations profile has not hidden,
+// latest activity first, starting after the conversation ID that was last
+// active at updatedAt. A zero updatedAt starts from the top.
+func (repo *ChatRepository) GetConversations(profile string, updatedAt time.Time, ID string, limit int64) ([]entity.Conversation, error) {
+	conversations := []entity.Conversation{}
+	filter := bson.M{
+		"participants": profile,
+		"members":      bson.M{"$elemMatch": bson.M{"profile": profile, "hidden": false}},
+	}
+	if !updatedAt.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$lt": updatedAt}},
+			bson.M{"updated_at": updatedAt, "id": bson.M{"$lt": ID}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "id", Value: -1}}).SetLimit(limit)
+	cursor, err := repo.DB.Collection(CONVERSATION).Find(repo.Ctx, filter, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &conversations)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return conversations, nil
+}
+
+// HideChat takes the conversation off profile's chat list and clears its
+// history for profile, up to the latest message. The conversation comes
+// back with the next message.
+func (repo *ChatRepository) HideChat(profile, other string) error {
+	conversation, err := repo.GetConversation(profile, other)
+	if err != nil {
+		return err
+	}
+	set := bson.M{
+		"members.$.hidden":       true,
+		"members.$.cleared_seq":  conversation.Seq,
+		"members.$.unread_count": 0,
+	}
+	if conversation.Member(profile).LastReadSeq < conversation.Seq {
+		set["members.$.last_read_id"] = conversation.LastMessageId
+		set["members.$.last_read_seq"] = conversation.Seq
+	}
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, memberFilter(profile, other), bson.M{"$set": set})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// MuteChat mutes the conversation for sender, until the given time or, when
+// until is nil, until it is unmuted.
+func (repo *ChatRepository) MuteChat(sender, receiver string, until *time.Time) error {
+	update := bson.M{"$set": bson.M{"members.$.muted": true, "members.$.muted_until": until}}
+	if until == nil {
+		update = bson.M{"$set": bson.M{"members.$.muted": true}, "$unset": bson.M{"members.$.muted_until": ""}}
+	}
+	return repo.updateMember(sender, receiver, update)
+}
+
+// UnmuteChat ...
+func (repo *ChatRepository) UnmuteChat(sender, receiver string) error {
+	return repo.updateMember(sender, receiver, bson.M{
+		"$set":   bson.M{"members.$.muted": false},
+		"$unset": bson.M{"members.$.muted_until": ""},
+	})
+}
+
+// GetChatRoom returns the conversation with receiver as sender sees it.
+func (repo *ChatRepository) GetChatRoom(sender, receiver string) (*entity.ChatRoom, error) {
+	conversation, err := repo.GetConversation(sender, receiver)
+	if err != nil {
+		return nil, err
+	}
+	return conversation.ChatRoom(sender), nil
+}
+
+// SetChatAutoTranslate sets the language the conversation is translated to
+// for sender. An empty lang turns auto-translate off.
+func (repo *ChatRepository) SetChatAutoTranslate(sender, receiver, lang string) error {
+	update := bson.M{"$set": bson.M{"members.$.translate_to": lang}}
+	if lang == "" {
+		update = bson.M{"$unset": bson.M{"members.$.translate_to": ""}}
+	}
+	return repo.updateMember(sender, receiver, update)
+}
+
+// ImportChatMessages inserts messages that do not exist yet, keyed by id,
+// and returns the ids of the ones it inserted. Seqs already given out never
+// change, so the new ones are numbered after the latest seq of their
+// conversation, oldest first: history imported into a conversation that
+// already has messages comes after them in seq order, and clients place it
+// by created_at. See importReadState for how read state follows.
+func (repo *ChatRepository) ImportChatMessages(messages entity.ChatMessageHistory) ([]string, error) {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	stored, err := repo.DB.Collection(MESSAGE).Distinct(repo.Ctx, "id", bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	existing := make(map[string]bool, len(stored))
+	for _, ID := range stored {
+		if ID, ok := ID.(string); ok {
+			existing[ID] = true
+		}
+	}
+
+	models := make([]mongo.WriteModel, 0, len(messages))
+	modelIds := make([]string, 0, len(messages))
+	for key, batch := range importBatches(messages, existing) {
+		last, err := repo.nextSeq(batch[0].Sender, batch[0].Receiver, int64(len(batch)))
+		if err != nil {
+			return nil, err
+		}
+		for index, message := range batch {
+			message.Seq = last - int64(len(batch)-1-index)
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"id": message.ID}).
+				SetUpdate(bson.M{"$setOnInsert": message}).
+				SetUpsert(true))
+			modelIds = append(modelIds, message.ID)
+		}
+
+		update, filters := importReadState(batch, last)
+		opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters})
+		_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": key}, update, opts)
+		if err != nil {
+			return nil, util.GetError("general_error")
+		}
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	result, err := repo.DB.Collection(MESSAGE).BulkWrite(repo.Ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	inserted := make([]string, 0, len(result.UpsertedIDs))
+	for index := range result.UpsertedIDs {
+		inserted = append(inserted, modelIds[index])
+	}
+	return inserted, nil
+}
+
+// importBatches groups the messages that are not stored yet by
+// conversation, oldest first.
+func importBatches(messages entity.ChatMessageHistory, existing map[string]bool) map[string][]*entity.ChatMessage {
+	batches := make(map[string][]*entity.ChatMessage)
+	for index := range messages {
+		if !existing[messages[index].ID] {
+			key := messages[index].Conversation
+			batches[key] = append(batches[key], &messages[index])
+		}
+	}
+	for _, batch := range batches {
+		sort.SliceStable(batch, func(i, j int) bool {
+			return batch[i].CreatedAt.Before(batch[j].CreatedAt)
+		})
+	}
+	return batches
+}
+
+// importReadState is the conversation update for batch taking the seqs up
+// to last. A member who had read everything has read the imported history
+// as well. A member who had not keeps its last read seq, so the messages the
+// other participant sent in batch are added to its unread count, which
+// counts those after the last read seq.
+func importReadState(batch []*entity.ChatMessage, last int64) (bson.M, []interface{}) {
+	previous := last - int64(len(batch))
+	update := bson.M{"$set": bson.M{
+		"members.$[read].last_read_id":  batch[len(batch)-1].ID,
+		"members.$[read].last_read_seq": last,
+	}}
+	filters := []interface{}{bson.M{"read.last_read_seq": bson.M{"$gte": previous}}}
+
+	unread := bson.M{}
+	for index, profile := range []string{batch[0].Sender, batch[0].Receiver} {
+		if index > 0 && profile == batch[0].Sender {
+			break
+		}
+		var count int64
+		for _, message := range batch {
+			if message.Sender != profile {
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		name := fmt.Sprintf("behind%d", index)
+		unread[fmt.Sprintf("members.$[%s].unread_count", name)] = count
+		filters = append(filters, bson.M{name + ".profile": profile, name + ".last_read_seq": bson.M{"$lt": previous}})
+	}
+	if len(unread) > 0 {
+		update["$inc"] = unread
+	}
+	return update, filters
+}
+
+// ImportConversation creates the conversation if it is missing, or moves its
+// last message forward when the imported one is newer than what is stored.
+func (repo *ChatRepository) ImportConversation(conversation *entity.Conversation) error {
+	newer := bson.M{"id": conversation.ID, "updated_at": bson.M{"$lt": conversation.UpdatedAt}}
+	_, err := repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, newer, bson.M{"$set": bson.M{
+		"last_message_id": conversation.LastMessageId,
+		"last_message":    conversation.LastMessage,
+		"last_sender":     conversation.LastSender,
+		"updated_at":      conversation.UpdatedAt,
+	}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+
+	_, err = repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": conversation.ID}, bson.M{
+		"$setOnInsert": conversation,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// nextSeq reserves n sequence numbers in the conversation of profile and
+// other, creating the conversation when it is missing, and returns the last
+// of them.
+func (repo *ChatRepository) nextSeq(profile, other string, n int64) (int64, error) {
+	var conversation entity.Conversation
+	conversation.PrepareConversation(profile, other)
+	_, err := repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, bson.M{"id": conversation.ID}, bson.M{
+		"$setOnInsert": &conversation,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, util.GetError("general_error")
+	}
+
+	after := options.After
+	err = repo.DB.Collection(CONVERSATION).FindOneAndUpdate(repo.Ctx, bson.M{"id": conversation.ID}, bson.M{
+		"$inc": bson.M{"seq": n},
+	}, &options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&conversation)
+	if err != nil {
+		return 0, util.GetError("general_error")
+	}
+	return conversation.Seq, nil
+}
+
+// historyFilter matches the messages of the conversation of profile and
+// other that profile has neither hidden nor cleared.
+func (repo *ChatRepository) historyFilter(profile, other string) bson.M {
+	filter := bson.M{"conversation": entity.ConversationKey(profile, other), "hidden_for": bson.M{"$ne": profile}}
+	if conversation, err := repo.GetConversation(profile, other); err == nil {
+		if member := conversation.Member(profile); member != nil && member.ClearedSeq > 0 {
+			filter["seq"] = bson.M{"$gt": member.ClearedSeq}
+		}
+	}
+	return filter
+}
+
+// updateMember applies update to profile's state in the conversation with
+// other. The update addresses the member through members.$.
+func (repo *ChatRepository) updateMember(profile, other string, update bson.M) error {
+	result, err := repo.DB.Collection(CONVERSATION).UpdateOne(repo.Ctx, memberFilter(profile, other), update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.MatchedCount == 0 {
+		return util.GetError("chat_not_found")
+	}
+	return nil
+}
+
+// memberFilter matches the conversation of profile and other, with the
+// positional operator pointing at profile's member.
+func memberFilter(profile, other string) bson.M {
+	return bson.M{"id": entity.ConversationKey(profile, other), "members.profile": profile}
+}
+
+// GetChatList lists the conversations of sender that are not hidden, as
+// sender sees them, latest first. The other participants' profiles are
+// loaded in one batch for the whole list.
+func (repo *ChatRepository) GetChatList(sender string) (entity.ChatList, error) {
+	var conversations []entity.Conversation
+	filter := bson.M{
+		"participants": sender,
+		"members":      bson.M{"$elemMatch": bson.M{"profile": sender, "hidden": bson.M{"$ne": true}}},
+	}
+	opts := options.Find().SetSort(bson.M{"updated_at": -1})
+	cursor, err := repo.DB.Collection(CONVERSATION).Find(repo.Ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(repo.Ctx, &chatList)
+	err = cursor.All(repo.Ctx, &conversations)
 	if err != nil {
 		return nil, util.GetError("error_retrieve")
 	}
+
+	others := make([]string, 0, len(conversations))
+	for index := range conversations {
+		others = append(others, conversations[index].Other(sender))
+	}
+	var profiles []entity.MemberProfile
+	cursor, err = repo.DB.Collection(PROFILE).Find(repo.Ctx, bson.M{"id": bson.M{"$in": others}})
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &profiles)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	byID := make(map[string]entity.MemberProfile, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.ID] = profile
+	}
+
+	chatList := make(entity.ChatList, 0, len(conversations))
+	for index := range conversations {
+		room := conversations[index].ChatRoom(sender)
+		receivers := make([]entity.MemberProfile, 0, 1)
+		if profile, ok := byID[room.Receiver[0]]; ok {
+			receivers = append(receivers, profile)
+		}
+		chatList = append(chatList, entity.RetrieveChatRoom{
+			ID:          room.ID,
+			Sender:      room.Sender,
+			Receiver:    receivers,
+			Message:     room.Message,
+			Seq:         room.Seq,
+			IsRead:      room.IsRead,
+			UnreadCount: room.UnreadCount,
+			LastReadId:  room.LastReadId,
+			LastReadSeq: room.LastReadSeq,
+			Muted:       room.Muted,
+			MutedUntil:  room.MutedUntil,
+			TranslateTo: room.TranslateTo,
+			CreatedAt:   room.CreatedAt,
+		})
+	}
 	return chatList, nil
 }
 
-// GetChatCounter ...
-func (repo *ChatRepository) GetChatCounter(sender string) (int64, error) {
-	filter := bson.M{"sender": sender, "is_read": false}
-	chats, err := repo.DB.Collection(CHAT_ROOM).CountDocuments(repo.Ctx, filter, nil)
+// GetChatCounter counts unread messages and chats, leaving out muted and
+// hidden chats.
+func (repo *ChatRepository) GetChatCounter(sender string) (*entity.ChatCounter, error) {
+	var counters []entity.ChatCounter
+	match := bson.D{{Key: "$match", Value: bson.M{
+		"participants": sender,
+	}}}
+	unwind := bson.D{{Key: "$unwind", Value: "$members"}}
+	matchMember := bson.D{{Key: "$match", Value: bson.M{
+		"members.profile": sender,
+		"members.hidden":  bson.M{"$ne": true},
+		"$nor": bson.A{bson.M{"members.muted": true, "$or": bson.A{
+			bson.M{"members.muted_until": bson.M{"$exists": false}},
+			bson.M{"members.muted_until": bson.M{"$gt": util.GetTimeNow()}},
+		}}},
+	}}}
+	group := bson.D{{Key: "$group", Value: bson.M{
+		"_id":             nil,
+		"unread_messages": bson.M{"$sum": "$members.unread_count"},
+		"unread_chats": bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$members.unread_count", 0}}, 1, 0,
+		}}},
+	}}}
+
+	cursor, err := repo.DB.Collection(CONVERSATION).Aggregate(repo.Ctx, mongo.Pipeline{match, unwind, matchMember, group})
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	err = cursor.All(repo.Ctx, &counters)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	if len(counters) == 0 {
+		return &entity.ChatCounter{}, nil
 	}
-	return chats, nil
+	return &counters[0], nil
 }
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_ImportBatchesOldestFirst(t *testing.T) {
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	messages := entity.ChatMessageHistory{
+		{ID: "3", Conversation: "a-b", CreatedAt: start.Add(2 * time.Minute)},
+		{ID: "1", Conversation: "a-b", CreatedAt: start},
+		{ID: "2", Conversation: "a-b", CreatedAt: start.Add(time.Minute)},
+		{ID: "4", Conversation: "a-c", CreatedAt: start},
+	}
+
+	batches := importBatches(messages, map[string]bool{"2": true})
+
+	assert.Len(t, batches, 2)
+	assert.Len(t, batches["a-b"], 2)
+	assert.Equal(t, "1", batches["a-b"][0].ID)
+	assert.Equal(t, "3", batches["a-b"][1].ID)
+	assert.Equal(t, "4", batches["a-c"][0].ID)
+}
+
+func Test_ImportReadState(t *testing.T) {
+	batch := []*entity.ChatMessage{
+		{ID: "1", Sender: "a", Receiver: "b"},
+		{ID: "2", Sender: "b", Receiver: "a"},
+		{ID: "3", Sender: "b", Receiver: "a"},
+	}
+
+	update, filters := importReadState(batch, 10)
+
+	assert.Equal(t, bson.M{
+		"members.$[read].last_read_id":  "3",
+		"members.$[read].last_read_seq": int64(10),
+	}, update["$set"])
+	assert.Equal(t, bson.M{
+		"members.$[behind0].unread_count": int64(2),
+		"members.$[behind1].unread_count": int64(1),
+	}, update["$inc"])
+	assert.Equal(t, []interface{}{
+		bson.M{"read.last_read_seq": bson.M{"$gte": int64(7)}},
+		bson.M{"behind0.profile": "a", "behind0.last_read_seq": bson.M{"$lt": int64(7)}},
+		bson.M{"behind1.profile": "b", "behind1.last_read_seq": bson.M{"$lt": int64(7)}},
+	}, filters)
+
+	update, filters = importReadState(batch[:1], 4)
+	assert.Equal(t, bson.M{"members.$[behind1].unread_count": int64(1)}, update["$inc"])
+	assert.Len(t, filters, 2)
+}
@@ -13,12 +13,24 @@ import (
 
 // Repository ...
 type Repository struct {
-	Member     repository.MemberRepository
-	VerifyCode repository.VerificationCodeRepository
-	Profile    repository.ProfileRepository
-	Chat       repository.ChatRepository
-	Ctx        context.Context
-	Client     *mongo.Client
+	Member          repository.MemberRepository
+	VerifyCode      repository.VerificationCodeRepository
+	Profile         repository.ProfileRepository
+	Chat            repository.ChatRepository
+	ChatExport      repository.ChatExportRepository
+	Moderation      repository.ModerationRepository
+	Report          repository.ReportRepository
+	AuditLog        repository.AuditLogRepository
+	Webhook         repository.WebhookRepository
+	Bot             repository.BotRepository
+	Translation     repository.TranslationRepository
+	Poll            repository.PollRepository
+	Pin             repository.PinRepository
+	Star            repository.StarRepository
+	ServiceAccount  repository.ServiceAccountRepository
+	AccountDeletion repository.AccountDeletionRepository
+	Ctx             context.Context
+	Client          *mongo.Client
 }
 
 // NewRepository ...
@@ -33,13 +45,40 @@ func NewRepository() (*Repository, error) {
 		return nil, err
 	}
 	db := client.Database(os.Getenv("DB_NAME"))
+	chat := NewChatRepository(db)
+	err = chat.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
+	chatExport := NewChatExportRepository(db)
+	err = chatExport.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
+	poll := NewPollRepository(db)
+	err = poll.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
 	return &Repository{
-		Member:     NewMemberRepository(db),
-		VerifyCode: NewVerifyCodeRepository(db),
-		Profile:    NewMemberProfileRepository(db),
-		Chat:       NewChatRepository(db),
-		Ctx:        ctx,
-		Client:     client,
+		Member:          NewMemberRepository(db),
+		VerifyCode:      NewVerifyCodeRepository(db),
+		Profile:         NewMemberProfileRepository(db),
+		Chat:            chat,
+		ChatExport:      chatExport,
+		Moderation:      NewModerationRepository(db),
+		Report:          NewReportRepository(db),
+		AuditLog:        NewAuditLogRepository(db),
+		Webhook:         NewWebhookRepository(db),
+		Bot:             NewBotRepository(db),
+		Translation:     NewTranslationRepository(db),
+		Poll:            poll,
+		Pin:             NewPinRepository(db),
+		Star:            NewStarRepository(db),
+		ServiceAccount:  NewServiceAccountRepository(db),
+		AccountDeletion: NewAccountDeletionRepository(db),
+		Ctx:             ctx,
+		Client:          client,
 	}, nil
 }
 
@@ -50,8 +89,44 @@ const (
 	VERIFY_CODE = "verify_code"
 	// PROFILE ...
 	PROFILE = "profile"
-	// CHAT ...
+	// MESSAGE ...
+	MESSAGE = "message"
+	// CONVERSATION ...
+	CONVERSATION = "conversation"
+	// CHAT held one copy of every message per side before MESSAGE. It is
+	// only read by the chat migration.
 	CHAT = "chat"
-	// CHAT_ROOM ...
+	// CHAT_ROOM held one room per side before CONVERSATION. It is only read
+	// by the chat migration.
 	CHAT_ROOM = "chat_room"
+	// CHAT_EXPORT ...
+	CHAT_EXPORT = "chat_export"
+	// MODERATION_REVIEW ...
+	MODERATION_REVIEW = "moderation_review"
+	// REPORT ...
+	REPORT = "report"
+	// AUDIT_LOG ...
+	AUDIT_LOG = "audit_log"
+	// WEBHOOK ...
+	WEBHOOK = "webhook"
+	// WEBHOOK_DELIVERY ...
+	WEBHOOK_DELIVERY = "webhook_delivery"
+	// BOT ...
+	BOT = "bot"
+	// MESSAGE_TRANSLATION ...
+	MESSAGE_TRANSLATION = "message_translation"
+	// POLL ...
+	POLL = "poll"
+	// POLL_VOTE ...
+	POLL_VOTE = "poll_vote"
+	// CHAT_PIN ...
+	CHAT_PIN = "chat_pin"
+	// CHAT_STAR ...
+	CHAT_STAR = "chat_star"
+	// SERVICE_ACCOUNT ...
+	SERVICE_ACCOUNT = "service_account"
+	// ACCOUNT_DELETION ...
+	ACCOUNT_DELETION = "account_deletion"
+	// MIGRATION records the data migrations that completed.
+	MIGRATION = "migration"
 )
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"regexp"
 	"strings"
 
 	"github.com/majid-cj/go-chat-server/domain/entity"
@@ -10,6 +11,7 @@ import (
 	"github.com/majid-cj/go-chat-server/util/security"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
@@ -66,25 +68,34 @@ func (repo *MemberRepository) DeleteMember(ID string) error {
 	return nil
 }
 
-// GetMembers ...
-func (repo *MemberRepository) GetMembers() ([]entity.Member, error) {
-	var members entity.Members
+// SearchMembers lists the members matching search, newest first.
+func (repo *MemberRepository) SearchMembers(search entity.MemberSearch, page int64) ([]entity.Member, error) {
+	members := []entity.Member{}
 	filter := bson.M{}
-	cursor, err := repo.DB.Find(repo.Ctx, filter, nil)
+	ids := search.Members
+	match := bson.A{}
+	if search.Query != "" {
+		match = append(match, bson.M{"email": primitive.Regex{Pattern: regexp.QuoteMeta(search.Query), Options: "i"}})
+		ids = append(ids, search.Query)
+	}
+	if len(ids) > 0 {
+		match = append(match, bson.M{"id": bson.M{"$in": ids}})
+		filter["$or"] = match
+	}
+	if search.Role != "" {
+		filter["role"] = search.Role
+	}
+	if search.Active != nil {
+		filter["active"] = *search.Active
+	}
+	cursor, err := repo.DB.Find(repo.Ctx, filter, pageOptions(page))
 	if err != nil {
-		return nil, util.GetError("general_error")
+		return nil, util.GetError("error_retrieve")
 	}
-
-	defer cursor.Close(repo.Ctx)
-
 	err = cursor.All(repo.Ctx, &members)
 	if err != nil {
 		return nil, util.GetError("error_retrieve")
 	}
-
-	if len(members) == 0 {
-		return nil, util.GetError("empty_list")
-	}
 	return members, nil
 }
 
@@ -99,34 +110,6 @@ func (repo *MemberRepository) GetMember(ID string) (*entity.Member, error) {
 	return &member, nil
 }
 
-// GetMembersByType ...
-func (repo *MemberRepository) GetMembersByType(memberType uint8) ([]entity.Member, error) {
-	var members entity.Members
-
-	filter := bson.M{"member_type": memberType}
-
-	cursor, err := repo.DB.Find(repo.Ctx, filter, nil)
-	if err != nil {
-		return nil, util.GetError("general_error")
-	}
-
-	defer cursor.Close(repo.Ctx)
-
-	for cursor.Next(repo.Ctx) {
-		var member entity.Member
-		err := cursor.Decode(member)
-		if err != nil {
-			return nil, util.GetError("general_error")
-		}
-		members = append(members, member)
-	}
-
-	if len(members) == 0 {
-		return nil, util.GetError("empty_list")
-	}
-	return members, nil
-}
-
 // GetMembersBySource ...
 func (repo *MemberRepository) GetMembersBySource(source uint8) ([]entity.Member, error) {
 	var members entity.Members
@@ -212,3 +195,55 @@ func (repo *MemberRepository) UpdatePassword(member *entity.Member) error {
 	}
 	return nil
 }
+
+// SetMemberActive ...
+func (repo *MemberRepository) SetMemberActive(ID string, active bool) error {
+	filter := bson.M{"id": ID}
+	update := bson.M{"$set": bson.M{
+		"active":    active,
+		"update_at": util.GetTimeNow(),
+	}}
+	result, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.MatchedCount == 0 {
+		return util.GetError("member_not_found")
+	}
+	return nil
+}
+
+// SetMemberRole replaces the role and the permissions granted on top of it.
+func (repo *MemberRepository) SetMemberRole(ID, role string, permissions []string) error {
+	filter := bson.M{"id": ID}
+	update := bson.M{"$set": bson.M{
+		"role":        role,
+		"permissions": permissions,
+		"update_at":   util.GetTimeNow(),
+	}}
+	result, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.MatchedCount == 0 {
+		return util.GetError("member_not_found")
+	}
+	return nil
+}
+
+// SetPasswordReset ...
+func (repo *MemberRepository) SetPasswordReset(ID string, reset bool) error {
+	filter := bson.M{"id": ID}
+	update := bson.M{"$set": bson.M{
+		"password_reset": reset,
+		"update_at":      util.GetTimeNow(),
+	}}
+	result, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.MatchedCount == 0 {
+		return util.GetError("member_not_found")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ModerationRepository ...
+type ModerationRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewModerationRepository ...
+func NewModerationRepository(db *mongo.Database) *ModerationRepository {
+	return &ModerationRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(MODERATION_REVIEW),
+	}
+}
+
+var _ repository.ModerationRepository = &ModerationRepository{}
+
+// AddModerationReview ...
+func (repo *ModerationRepository) AddModerationReview(review *entity.ModerationReview) error {
+	_, err := repo.DB.InsertOne(repo.Ctx, review)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetModerationReview ...
+func (repo *ModerationRepository) GetModerationReview(ID string) (*entity.ModerationReview, error) {
+	var review entity.ModerationReview
+	err := repo.DB.FindOne(repo.Ctx, bson.M{"id": ID}).Decode(&review)
+	if err != nil {
+		return nil, util.GetError("review_not_found")
+	}
+	return &review, nil
+}
+
+// GetModerationReviews lists reviews with status, or every review when
+// status is empty, newest first.
+func (repo *ModerationRepository) GetModerationReviews(status string, page int64) ([]entity.ModerationReview, error) {
+	reviews := []entity.ModerationReview{}
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := repo.DB.Find(repo.Ctx, filter, pageOptions(page))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &reviews)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return reviews, nil
+}
+
+// UpdateModerationReview ...
+func (repo *ModerationRepository) UpdateModerationReview(review *entity.ModerationReview) error {
+	filter := bson.M{"id": review.ID}
+	update := bson.M{"$set": bson.M{
+		"status":     review.Status,
+		"resolution": review.Resolution,
+	}}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
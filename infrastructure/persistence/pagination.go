@@ -0,0 +1,20 @@
+package persistence
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PAGE_SIZE ...
+const PAGE_SIZE = 50
+
+// pageOptions sorts newest first and skips to page, counted from 1.
+func pageOptions(page int64) *options.FindOptions {
+	if page < 1 {
+		page = 1
+	}
+	return options.Find().
+		SetSort(bson.M{"id": -1}).
+		SetSkip((page - 1) * PAGE_SIZE).
+		SetLimit(PAGE_SIZE)
+}
@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PinRepository ...
+type PinRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewPinRepository ...
+func NewPinRepository(db *mongo.Database) *PinRepository {
+	return &PinRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(CHAT_PIN),
+	}
+}
+
+var _ repository.PinRepository = &PinRepository{}
+
+// AddPin pins a message unless it is pinned already. It gives pin_limit
+// when the conversation has MAX_CHAT_PINS pins.
+func (repo *PinRepository) AddPin(pin *entity.ChatPin) error {
+	filter := bson.M{"chat": pin.Chat, "ref": pin.Ref}
+	pinned, err := repo.DB.CountDocuments(repo.Ctx, filter)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if pinned > 0 {
+		return nil
+	}
+	count, err := repo.DB.CountDocuments(repo.Ctx, bson.M{"chat": pin.Chat})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if count >= entity.MAX_CHAT_PINS {
+		return util.GetError("pin_limit")
+	}
+	_, err = repo.DB.UpdateOne(repo.Ctx, filter, bson.M{"$setOnInsert": pin}, options.Update().SetUpsert(true))
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeletePin ...
+func (repo *PinRepository) DeletePin(chat, ref string) error {
+	result, err := repo.DB.DeleteOne(repo.Ctx, bson.M{"chat": chat, "ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.DeletedCount == 0 {
+		return util.GetError("pin_not_found")
+	}
+	return nil
+}
+
+// GetPins lists the pins of a conversation, newest first.
+func (repo *PinRepository) GetPins(chat string) ([]entity.ChatPin, error) {
+	pins := []entity.ChatPin{}
+	cursor, err := repo.DB.Find(repo.Ctx, bson.M{"chat": chat}, options.Find().SetSort(bson.M{"id": -1}))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &pins)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return pins, nil
+}
+
+// DeleteMessagePins ...
+func (repo *PinRepository) DeleteMessagePins(ref string) error {
+	_, err := repo.DB.DeleteMany(repo.Ctx, bson.M{"ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
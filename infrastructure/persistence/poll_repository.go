@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+// PollRepository ...
+type PollRepository struct {
+	Ctx context.Context
+	DB  *mongo.Database
+}
+
+// NewPollRepository ...
+func NewPollRepository(db *mongo.Database) *PollRepository {
+	return &PollRepository{
+		Ctx: context.Background(),
+		DB:  db,
+	}
+}
+
+var _ repository.PollRepository = &PollRepository{}
+
+// CreateIndexes makes a profile's vote unique per poll, so concurrent
+// SetPollVote upserts cannot store it twice.
+func (repo *PollRepository) CreateIndexes() error {
+	_, err := repo.DB.Collection(POLL_VOTE).Indexes().CreateOne(repo.Ctx, mongo.IndexModel{
+		Keys:    bsonx.Doc{{Key: "poll", Value: bsonx.Int64(1)}, {Key: "profile", Value: bsonx.Int64(1)}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// CreatePoll ...
+func (repo *PollRepository) CreatePoll(poll *entity.Poll) error {
+	_, err := repo.DB.Collection(POLL).InsertOne(repo.Ctx, poll)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetPoll ...
+func (repo *PollRepository) GetPoll(ref string) (*entity.Poll, error) {
+	var poll entity.Poll
+	err := repo.DB.Collection(POLL).FindOne(repo.Ctx, bson.M{"ref": ref}).Decode(&poll)
+	if err != nil {
+		return nil, util.GetError("poll_not_found")
+	}
+	return &poll, nil
+}
+
+// SetPollVote replaces the profile's vote. A vote without options is
+// removed.
+func (repo *PollRepository) SetPollVote(vote *entity.PollVote) error {
+	var err error
+	filter := bson.M{"poll": vote.Poll, "profile": vote.Profile}
+	if len(vote.Options) == 0 {
+		_, err = repo.DB.Collection(POLL_VOTE).DeleteOne(repo.Ctx, filter)
+	} else {
+		_, err = repo.DB.Collection(POLL_VOTE).ReplaceOne(repo.Ctx, filter, vote, options.Replace().SetUpsert(true))
+	}
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// GetPollVotes ...
+func (repo *PollRepository) GetPollVotes(ref string) ([]entity.PollVote, error) {
+	votes := []entity.PollVote{}
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := repo.DB.Collection(POLL_VOTE).Find(repo.Ctx, bson.M{"poll": ref}, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &votes)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return votes, nil
+}
+
+// UpdatePollResults stores the poll's tallies.
+func (repo *PollRepository) UpdatePollResults(poll *entity.Poll) error {
+	_, err := repo.DB.Collection(POLL).UpdateOne(repo.Ctx, bson.M{"ref": poll.Ref}, bson.M{"$set": bson.M{
+		"options": poll.Options,
+		"voters":  poll.Voters,
+	}})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// ClosePoll stops the poll from taking votes. Only the first call wins; a
+// poll that is already closed gives poll_closed.
+func (repo *PollRepository) ClosePoll(ref string, closedAt time.Time) (*entity.Poll, error) {
+	var poll entity.Poll
+	after := options.After
+	filter := bson.M{"ref": ref, "closed_at": bson.M{"$exists": false}}
+	err := repo.DB.Collection(POLL).FindOneAndUpdate(repo.Ctx, filter, bson.M{
+		"$set": bson.M{"closed_at": closedAt},
+	}, &options.FindOneAndUpdateOptions{ReturnDocument: &after}).Decode(&poll)
+	if err != nil {
+		return nil, util.GetError("poll_closed")
+	}
+	return &poll, nil
+}
+
+// GetDuePolls lists the open polls whose close time has passed.
+func (repo *PollRepository) GetDuePolls(now time.Time) ([]entity.Poll, error) {
+	polls := []entity.Poll{}
+	filter := bson.M{"closed_at": bson.M{"$exists": false}, "closes_at": bson.M{"$lte": now}}
+	cursor, err := repo.DB.Collection(POLL).Find(repo.Ctx, filter)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &polls)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return polls, nil
+}
+
+// DeletePoll removes the poll and its votes.
+func (repo *PollRepository) DeletePoll(ref string) error {
+	_, err := repo.DB.Collection(POLL_VOTE).DeleteMany(repo.Ctx, bson.M{"poll": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	_, err = repo.DB.Collection(POLL).DeleteOne(repo.Ctx, bson.M{"ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
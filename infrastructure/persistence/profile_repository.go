@@ -78,6 +78,24 @@ func (repo *MemberProfileRepository) GetMemberProfileByID(ID string) (*entity.Me
 	return &profile, nil
 }
 
+// GetMemberProfilesByIDs loads the profiles with the given ids in one query.
+// Ids without a profile are left out.
+func (repo *MemberProfileRepository) GetMemberProfilesByIDs(IDs []string) (entity.MemberProfiles, error) {
+	profiles := entity.MemberProfiles{}
+	if len(IDs) == 0 {
+		return profiles, nil
+	}
+	cursor, err := repo.DB.Find(repo.Ctx, bson.M{"id": bson.M{"$in": IDs}})
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &profiles)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return profiles, nil
+}
+
 // GetMemberProfileByMemberID ...
 func (repo *MemberProfileRepository) GetMemberProfileByMemberID(member string) (*entity.MemberProfile, error) {
 	var profile entity.MemberProfile
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReportRepository ...
+type ReportRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewReportRepository ...
+func NewReportRepository(db *mongo.Database) *ReportRepository {
+	return &ReportRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(REPORT),
+	}
+}
+
+var _ repository.ReportRepository = &ReportRepository{}
+
+// CreateReport ...
+func (repo *ReportRepository) CreateReport(report *entity.Report) (*entity.Report, error) {
+	_, err := repo.DB.InsertOne(repo.Ctx, report)
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	return report, nil
+}
+
+// GetReport ...
+func (repo *ReportRepository) GetReport(ID string) (*entity.Report, error) {
+	var report entity.Report
+	err := repo.DB.FindOne(repo.Ctx, bson.M{"id": ID}).Decode(&report)
+	if err != nil {
+		return nil, util.GetError("report_not_found")
+	}
+	return &report, nil
+}
+
+// GetReports lists reports with status, or every report when status is
+// empty, newest first.
+func (repo *ReportRepository) GetReports(status string, page int64) ([]entity.Report, error) {
+	reports := []entity.Report{}
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := repo.DB.Find(repo.Ctx, filter, pageOptions(page))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &reports)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return reports, nil
+}
+
+// UpdateReport ...
+func (repo *ReportRepository) UpdateReport(report *entity.Report) error {
+	filter := bson.M{"id": report.ID}
+	update := bson.M{"$set": bson.M{
+		"status":     report.Status,
+		"priority":   report.Priority,
+		"assignee":   report.Assignee,
+		"resolution": report.Resolution,
+		"updated_at": report.UpdatedAt,
+	}}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
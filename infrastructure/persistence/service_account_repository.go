@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ServiceAccountRepository ...
+type ServiceAccountRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewServiceAccountRepository ...
+func NewServiceAccountRepository(db *mongo.Database) *ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(SERVICE_ACCOUNT),
+	}
+}
+
+var _ repository.ServiceAccountRepository = &ServiceAccountRepository{}
+
+// CreateServiceAccount ...
+func (repo *ServiceAccountRepository) CreateServiceAccount(account *entity.ServiceAccount) (*entity.ServiceAccount, error) {
+	_, err := repo.DB.InsertOne(repo.Ctx, account)
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	return account, nil
+}
+
+// GetServiceAccount ...
+func (repo *ServiceAccountRepository) GetServiceAccount(ID string) (*entity.ServiceAccount, error) {
+	return repo.findServiceAccount(bson.M{"id": ID})
+}
+
+// GetServiceAccounts ...
+func (repo *ServiceAccountRepository) GetServiceAccounts() ([]entity.ServiceAccount, error) {
+	accounts := []entity.ServiceAccount{}
+	cursor, err := repo.DB.Find(repo.Ctx, bson.M{}, options.Find().SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &accounts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return accounts, nil
+}
+
+// GetServiceAccountByToken looks an account up by the hash of its token.
+func (repo *ServiceAccountRepository) GetServiceAccountByToken(hash string) (*entity.ServiceAccount, error) {
+	return repo.findServiceAccount(bson.M{"token_hash": hash})
+}
+
+func (repo *ServiceAccountRepository) findServiceAccount(filter bson.M) (*entity.ServiceAccount, error) {
+	var account entity.ServiceAccount
+	err := repo.DB.FindOne(repo.Ctx, filter).Decode(&account)
+	if err != nil {
+		return nil, util.GetError("service_account_not_found")
+	}
+	return &account, nil
+}
+
+// UpdateServiceAccount ...
+func (repo *ServiceAccountRepository) UpdateServiceAccount(account *entity.ServiceAccount) error {
+	filter := bson.M{"id": account.ID}
+	update := bson.M{"$set": bson.M{
+		"token_hash": account.TokenHash,
+		"token_hint": account.TokenHint,
+		"updated_at": account.UpdatedAt,
+	}}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteServiceAccount ...
+func (repo *ServiceAccountRepository) DeleteServiceAccount(ID string) error {
+	_, err := repo.DB.DeleteOne(repo.Ctx, bson.M{"id": ID})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StarRepository ...
+type StarRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewStarRepository ...
+func NewStarRepository(db *mongo.Database) *StarRepository {
+	return &StarRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(CHAT_STAR),
+	}
+}
+
+var _ repository.StarRepository = &StarRepository{}
+
+// AddStar stars a message unless the profile starred it already.
+func (repo *StarRepository) AddStar(star *entity.ChatStar) error {
+	filter := bson.M{"profile": star.Profile, "ref": star.Ref}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, bson.M{"$setOnInsert": star}, options.Update().SetUpsert(true))
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteStar ...
+func (repo *StarRepository) DeleteStar(profile, ref string) error {
+	result, err := repo.DB.DeleteOne(repo.Ctx, bson.M{"profile": profile, "ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.DeletedCount == 0 {
+		return util.GetError("star_not_found")
+	}
+	return nil
+}
+
+// GetStars lists what profile starred in every conversation, newest first.
+func (repo *StarRepository) GetStars(profile string, page int64) ([]entity.ChatStar, error) {
+	return repo.findStars(bson.M{"profile": profile}, pageOptions(page))
+}
+
+// GetChatStars lists what profile starred in one conversation.
+func (repo *StarRepository) GetChatStars(profile, chatId string) ([]entity.ChatStar, error) {
+	return repo.findStars(bson.M{"profile": profile, "chat_id": chatId}, options.Find())
+}
+
+func (repo *StarRepository) findStars(filter bson.M, opts *options.FindOptions) ([]entity.ChatStar, error) {
+	stars := []entity.ChatStar{}
+	cursor, err := repo.DB.Find(repo.Ctx, filter, opts)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &stars)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return stars, nil
+}
+
+// DeleteMessageStars ...
+func (repo *StarRepository) DeleteMessageStars(ref string) error {
+	_, err := repo.DB.DeleteMany(repo.Ctx, bson.M{"ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteProfileStars ...
+func (repo *StarRepository) DeleteProfileStars(profile string) error {
+	_, err := repo.DB.DeleteMany(repo.Ctx, bson.M{"profile": profile})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
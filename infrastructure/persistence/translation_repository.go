@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TranslationRepository ...
+type TranslationRepository struct {
+	Ctx context.Context
+	DB  *mongo.Collection
+}
+
+// NewTranslationRepository ...
+func NewTranslationRepository(db *mongo.Database) *TranslationRepository {
+	return &TranslationRepository{
+		Ctx: context.Background(),
+		DB:  db.Collection(MESSAGE_TRANSLATION),
+	}
+}
+
+var _ repository.TranslationRepository = &TranslationRepository{}
+
+// GetTranslation ...
+func (repo *TranslationRepository) GetTranslation(ref, lang string) (*entity.MessageTranslation, error) {
+	var translation entity.MessageTranslation
+	err := repo.DB.FindOne(repo.Ctx, bson.M{"ref": ref, "lang": lang}).Decode(&translation)
+	if err != nil {
+		return nil, util.GetError("translation_not_found")
+	}
+	return &translation, nil
+}
+
+// AddTranslation stores translation unless the message already has one in
+// that language.
+func (repo *TranslationRepository) AddTranslation(translation *entity.MessageTranslation) error {
+	filter := bson.M{"ref": translation.Ref, "lang": translation.Lang}
+	_, err := repo.DB.UpdateOne(repo.Ctx, filter, bson.M{"$setOnInsert": translation}, options.Update().SetUpsert(true))
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteTranslations drops every cached translation of the message ref,
+// once its text has changed.
+func (repo *TranslationRepository) DeleteTranslations(ref string) error {
+	_, err := repo.DB.DeleteMany(repo.Ctx, bson.M{"ref": ref})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
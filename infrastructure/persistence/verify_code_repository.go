@@ -82,9 +82,12 @@ func (repo *VerifyCodeRepository) ResetPassword(code *entity.VerificationCode) e
 		return util.GetError("general_error")
 	}
 
-	member.Password = saltedPassword
-	member.UpdateAt = util.GetTimeNow()
-	_, err = repo.DbMember.UpdateOne(repo.Ctx, bson.M{"id": member.ID}, bson.M{"$set": member})
+	update := bson.M{"$set": bson.M{
+		"password":       saltedPassword,
+		"password_reset": false,
+		"update_at":      util.GetTimeNow(),
+	}}
+	_, err = repo.DbMember.UpdateOne(repo.Ctx, bson.M{"id": member.ID}, update)
 	if err != nil {
 		return util.GetError("general_error")
 	}
@@ -134,3 +137,12 @@ func (repo *VerifyCodeRepository) RenewVerificationCode(code *entity.Verificatio
 	}
 	return &verifyCode, nil
 }
+
+// DeleteVerificationCodes ...
+func (repo *VerifyCodeRepository) DeleteVerificationCodes(member string) error {
+	_, err := repo.Db.DeleteMany(repo.Ctx, bson.M{"member": member})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookRepository ...
+type WebhookRepository struct {
+	Ctx context.Context
+	DB  *mongo.Database
+}
+
+// NewWebhookRepository ...
+func NewWebhookRepository(db *mongo.Database) *WebhookRepository {
+	return &WebhookRepository{
+		Ctx: context.Background(),
+		DB:  db,
+	}
+}
+
+var _ repository.WebhookRepository = &WebhookRepository{}
+
+// CreateWebhook ...
+func (repo *WebhookRepository) CreateWebhook(webhook *entity.Webhook) (*entity.Webhook, error) {
+	_, err := repo.DB.Collection(WEBHOOK).InsertOne(repo.Ctx, webhook)
+	if err != nil {
+		return nil, util.GetError("general_error")
+	}
+	return webhook, nil
+}
+
+// GetWebhook ...
+func (repo *WebhookRepository) GetWebhook(ID string) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	err := repo.DB.Collection(WEBHOOK).FindOne(repo.Ctx, bson.M{"id": ID}).Decode(&webhook)
+	if err != nil {
+		return nil, util.GetError("webhook_not_found")
+	}
+	return &webhook, nil
+}
+
+// GetWebhooks ...
+func (repo *WebhookRepository) GetWebhooks() ([]entity.Webhook, error) {
+	return repo.findWebhooks(bson.M{})
+}
+
+// GetWebhooksByEvent lists the active webhooks subscribed to event. Bot
+// webhooks are left out; they only receive what is sent to their bot.
+func (repo *WebhookRepository) GetWebhooksByEvent(event string) ([]entity.Webhook, error) {
+	return repo.findWebhooks(bson.M{"events": event, "active": true, "bot": bson.M{"$exists": false}})
+}
+
+func (repo *WebhookRepository) findWebhooks(filter bson.M) ([]entity.Webhook, error) {
+	webhooks := []entity.Webhook{}
+	cursor, err := repo.DB.Collection(WEBHOOK).Find(repo.Ctx, filter, options.Find().SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &webhooks)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook ...
+func (repo *WebhookRepository) UpdateWebhook(webhook *entity.Webhook) error {
+	filter := bson.M{"id": webhook.ID}
+	update := bson.M{"$set": bson.M{
+		"url":         webhook.URL,
+		"events":      webhook.Events,
+		"description": webhook.Description,
+		"secret":      webhook.Secret,
+		"active":      webhook.Active,
+		"updated_at":  webhook.UpdatedAt,
+	}}
+	_, err := repo.DB.Collection(WEBHOOK).UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// DeleteWebhook removes the webhook and drops its pending deliveries. The
+// delivery log and dead letters are kept.
+func (repo *WebhookRepository) DeleteWebhook(ID string) error {
+	result, err := repo.DB.Collection(WEBHOOK).DeleteOne(repo.Ctx, bson.M{"id": ID})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if result.DeletedCount == 0 {
+		return util.GetError("webhook_not_found")
+	}
+	_, err = repo.DB.Collection(WEBHOOK_DELIVERY).DeleteMany(repo.Ctx, bson.M{"webhook": ID, "status": entity.DELIVERY_PENDING})
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// AddWebhookDeliveries ...
+func (repo *WebhookRepository) AddWebhookDeliveries(deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(deliveries))
+	for index := range deliveries {
+		documents[index] = deliveries[index]
+	}
+	_, err := repo.DB.Collection(WEBHOOK_DELIVERY).InsertMany(repo.Ctx, documents)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// ClaimWebhookDelivery takes the oldest pending delivery that is due at now
+// and pushes its next attempt back by lease, so no other worker picks it up
+// while it is being sent.
+func (repo *WebhookRepository) ClaimWebhookDelivery(now time.Time, lease time.Duration) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	after := options.After
+	filter := bson.M{"status": entity.DELIVERY_PENDING, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	err := repo.DB.Collection(WEBHOOK_DELIVERY).FindOneAndUpdate(repo.Ctx, filter, update, &options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+		Sort:           bson.M{"next_attempt_at": 1},
+	}).Decode(&delivery)
+	if err != nil {
+		return nil, util.GetError("delivery_not_found")
+	}
+	return &delivery, nil
+}
+
+// GetWebhookDelivery ...
+func (repo *WebhookRepository) GetWebhookDelivery(ID string) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := repo.DB.Collection(WEBHOOK_DELIVERY).FindOne(repo.Ctx, bson.M{"id": ID}).Decode(&delivery)
+	if err != nil {
+		return nil, util.GetError("delivery_not_found")
+	}
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries lists deliveries for webhook with status, newest
+// first. An empty webhook or status matches every delivery.
+func (repo *WebhookRepository) GetWebhookDeliveries(webhook, status string, page int64) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+	filter := bson.M{}
+	if webhook != "" {
+		filter["webhook"] = webhook
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := repo.DB.Collection(WEBHOOK_DELIVERY).Find(repo.Ctx, filter, pageOptions(page))
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	err = cursor.All(repo.Ctx, &deliveries)
+	if err != nil {
+		return nil, util.GetError("error_retrieve")
+	}
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery ...
+func (repo *WebhookRepository) UpdateWebhookDelivery(delivery *entity.WebhookDelivery) error {
+	filter := bson.M{"id": delivery.ID}
+	update := bson.M{"$set": bson.M{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"log":             delivery.Log,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"updated_at":      delivery.UpdatedAt,
+	}}
+	_, err := repo.DB.Collection(WEBHOOK_DELIVERY).UpdateOne(repo.Ctx, filter, update)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	return nil
+}
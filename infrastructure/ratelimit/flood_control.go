@@ -0,0 +1,183 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// verdicts returned by FloodControlInterface.Check
+const (
+	FLOOD_ALLOW = iota
+	FLOOD_SLOW_DOWN
+	FLOOD_MUTED
+	FLOOD_DISCONNECT
+)
+
+// FloodRules are the token bucket sizes and the escalation thresholds. Rates
+// are tokens per second, bursts are bucket capacities.
+type FloodRules struct {
+	ProfileRate     float64
+	ProfileBurst    int
+	ChatRate        float64
+	ChatBurst       int
+	StrikeWindow    time.Duration
+	MuteAfter       int
+	MuteFor         time.Duration
+	DisconnectAfter int
+}
+
+// FloodVerdict ...
+type FloodVerdict struct {
+	Verdict    int
+	RetryAfter time.Duration
+}
+
+// FloodControlInterface ...
+type FloodControlInterface interface {
+	Check(profile, chatId string) (*FloodVerdict, error)
+}
+
+// FloodControl keeps its buckets in redis so every replica shares them.
+type FloodControl struct {
+	redisDB *redis.Client
+	Rules   FloodRules
+}
+
+var _ FloodControlInterface = &FloodControl{}
+
+// floodScript checks the mute flag, then takes one token from both the
+// profile and the conversation bucket, or none if either is empty. Every
+// rejection is a strike; enough strikes inside the window mute the profile,
+// and frames sent while muted count towards a disconnect.
+//
+// KEYS: mute, strikes, profile bucket, chat bucket
+// ARGV: now (ms), profile rate, profile burst, chat rate, chat burst,
+// strike window (ms), mute after, mute for (ms), disconnect after
+var floodScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[6])
+
+local muted = redis.call('PTTL', KEYS[1])
+if muted > 0 then
+	local strikes = redis.call('INCR', KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], math.max(window, muted))
+	if strikes >= tonumber(ARGV[9]) then
+		return {3, muted}
+	end
+	return {2, muted}
+end
+
+local function refill(key, rate, burst)
+	local state = redis.call('HMGET', key, 'tokens', 'ts')
+	local tokens = tonumber(state[1]) or burst
+	local ts = tonumber(state[2]) or now
+	return math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+end
+
+local function wait(tokens, rate)
+	if tokens >= 1 then
+		return 0
+	end
+	return math.ceil((1 - tokens) * 1000 / rate)
+end
+
+local profileRate, profileBurst = tonumber(ARGV[2]), tonumber(ARGV[3])
+local chatRate, chatBurst = tonumber(ARGV[4]), tonumber(ARGV[5])
+local profileTokens = refill(KEYS[3], profileRate, profileBurst)
+local chatTokens = refill(KEYS[4], chatRate, chatBurst)
+
+local retry = math.max(wait(profileTokens, profileRate), wait(chatTokens, chatRate))
+if retry > 0 then
+	local strikes = redis.call('INCR', KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], window)
+	if strikes >= tonumber(ARGV[7]) then
+		redis.call('SET', KEYS[1], 1, 'PX', ARGV[8])
+		redis.call('DEL', KEYS[2])
+		return {2, tonumber(ARGV[8])}
+	end
+	return {1, retry}
+end
+
+redis.call('HSET', KEYS[3], 'tokens', tostring(profileTokens - 1), 'ts', now)
+redis.call('PEXPIRE', KEYS[3], math.ceil(profileBurst * 1000 / profileRate))
+redis.call('HSET', KEYS[4], 'tokens', tostring(chatTokens - 1), 'ts', now)
+redis.call('PEXPIRE', KEYS[4], math.ceil(chatBurst * 1000 / chatRate))
+return {0, 0}
+`)
+
+// NewFloodControl ...
+func NewFloodControl(redisDB *redis.Client) *FloodControl {
+	return &FloodControl{
+		redisDB: redisDB,
+		Rules:   NewFloodRules(),
+	}
+}
+
+// DefaultFloodRules ...
+func DefaultFloodRules() FloodRules {
+	return FloodRules{
+		ProfileRate:     5,
+		ProfileBurst:    20,
+		ChatRate:        2,
+		ChatBurst:       10,
+		StrikeWindow:    time.Minute,
+		MuteAfter:       10,
+		MuteFor:         5 * time.Minute,
+		DisconnectAfter: 20,
+	}
+}
+
+// NewFloodRules reads the FLOOD_* environment variables, keeping the default
+// for anything missing or invalid.
+func NewFloodRules() FloodRules {
+	rules := DefaultFloodRules()
+	rules.ProfileRate = envFloat("FLOOD_PROFILE_RATE", rules.ProfileRate)
+	rules.ProfileBurst = int(envFloat("FLOOD_PROFILE_BURST", float64(rules.ProfileBurst)))
+	rules.ChatRate = envFloat("FLOOD_CHAT_RATE", rules.ChatRate)
+	rules.ChatBurst = int(envFloat("FLOOD_CHAT_BURST", float64(rules.ChatBurst)))
+	rules.MuteAfter = int(envFloat("FLOOD_MUTE_AFTER", float64(rules.MuteAfter)))
+	rules.DisconnectAfter = int(envFloat("FLOOD_DISCONNECT_AFTER", float64(rules.DisconnectAfter)))
+	if value, err := time.ParseDuration(os.Getenv("FLOOD_MUTE_FOR")); err == nil && value > 0 {
+		rules.MuteFor = value
+	}
+	return rules
+}
+
+// Check takes a token for one inbound frame from profile in chatId.
+func (flood *FloodControl) Check(profile, chatId string) (*FloodVerdict, error) {
+	rules := flood.Rules
+	keys := []string{
+		fmt.Sprintf("flood:mute:%s", profile),
+		fmt.Sprintf("flood:strikes:%s", profile),
+		fmt.Sprintf("flood:profile:%s", profile),
+		fmt.Sprintf("flood:chat:%s", chatId),
+	}
+	result, err := floodScript.Run(context.Background(), flood.redisDB, keys,
+		time.Now().UnixMilli(),
+		rules.ProfileRate, rules.ProfileBurst,
+		rules.ChatRate, rules.ChatBurst,
+		rules.StrikeWindow.Milliseconds(),
+		rules.MuteAfter, rules.MuteFor.Milliseconds(),
+		rules.DisconnectAfter,
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return &FloodVerdict{
+		Verdict:    int(result[0]),
+		RetryAfter: time.Duration(result[1]) * time.Millisecond,
+	}, nil
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -17,6 +18,7 @@ import (
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/middleware/logger"
 	"github.com/kataras/iris/v12/middleware/recover"
+	"google.golang.org/grpc"
 )
 
 func init() {
@@ -24,12 +26,13 @@ func init() {
 		log.Fatal(err.Error())
 	}
 	os.Mkdir(os.Getenv("UPLOADS"), os.FileMode(0766))
+	os.Mkdir(os.Getenv("EXPORTS"), os.FileMode(0700))
 }
 
 func main() {
 	appConfig, err := config.NewAppConfig()
 	if err != nil {
-		appConfig.ErrChan <- err
+		log.Fatal(err.Error())
 	}
 
 	defer appConfig.Persistence.Client.Disconnect(appConfig.AppContext)
@@ -53,6 +56,9 @@ func main() {
 	appConfig.App.I18n.SetDefault("en")
 
 	router.APIVersionOne(appConfig)
+	router.APIVersionTwo(appConfig)
+	router.APIDocs(appConfig)
+	go appConfig.Webhooks.Run(appConfig.AppContext)
 
 	go func() {
 		for range appConfig.ErrChan {
@@ -68,6 +74,23 @@ func main() {
 		}
 	}()
 
+	var rpcServer *grpc.Server
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		rpcServer = router.RPC(appConfig)
+		go func() {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+			if err != nil {
+				appConfig.Log.Errorf("Error starting gRPC server %+v", err)
+				os.Exit(1)
+			}
+			err = rpcServer.Serve(listener)
+			if err != nil {
+				appConfig.Log.Errorf("Error starting gRPC server %+v", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
@@ -77,4 +100,19 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	appConfig.App.Shutdown(ctx)
+
+	if rpcServer != nil {
+		// message streams never end on their own, so they are cut off once
+		// the shutdown timeout is up.
+		stopped := make(chan struct{})
+		go func() {
+			rpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			rpcServer.Stop()
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"expvar"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/util/middleware"
+)
+
+// AdminRouteEndPoints ...
+func AdminRouteEndPoints(
+	member *routers.MemberRouters,
+	moderation *routers.ModerationRouter,
+	webhook *routers.WebhookRouter,
+	bot *routers.BotRouter,
+	serviceAccount *routers.ServiceAccountRouter,
+	chatImport *routers.ChatImportRouter,
+	APIVersion router.Party,
+) {
+	adminRoute := APIVersion.Party("/admin")
+	{
+		adminRoute.Use(middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware)
+		moderate := moderation.Authorized(entity.PERMISSION_MODERATE)
+
+		adminRoute.Get("/reports", moderate, moderation.GetReports)
+		adminRoute.Get("/reports/{id:string}", moderate, moderation.GetReport)
+		adminRoute.Put("/reports/{id:string}/triage", moderate, moderation.TriageReport)
+		adminRoute.Put("/reports/{id:string}/resolve", moderate, moderation.ResolveReport)
+
+		adminRoute.Get("/reviews", moderate, moderation.GetReviews)
+		adminRoute.Put("/reviews/{id:string}/resolve", moderate, moderation.ResolveReview)
+
+		adminRoute.Put("/members/{id:string}/suspend", moderate, moderation.SuspendMember)
+		adminRoute.Put("/members/{id:string}/reinstate", moderate, moderation.ReinstateMember)
+
+		adminRoute.Get("/members", moderation.Authorized(entity.PERMISSION_MEMBERS_READ), member.SearchMembers)
+		adminRoute.Get("/members/{id:string}", moderation.Authorized(entity.PERMISSION_MEMBERS_READ), member.GetMember)
+		adminRoute.Put("/members/{id:string}/activate", moderation.Authorized(entity.PERMISSION_MEMBERS_ACTIVE), member.ActivateMember)
+		adminRoute.Put("/members/{id:string}/deactivate", moderation.Authorized(entity.PERMISSION_MEMBERS_ACTIVE), member.DeactivateMember)
+		adminRoute.Post("/members/{id:string}/password-reset", moderation.Authorized(entity.PERMISSION_MEMBERS_PASSWORD), member.ResetMemberPassword)
+		adminRoute.Delete("/members/{id:string}/sessions", moderation.Authorized(entity.PERMISSION_MEMBERS_SESSIONS), member.RevokeMemberSessions)
+		adminRoute.Put("/members/{id:string}/role", moderation.Authorized(entity.PERMISSION_MEMBERS_ROLES), member.UpdateMemberRole)
+
+		adminRoute.Get("/audit-log", moderation.Authorized(entity.PERMISSION_AUDIT_LOG), moderation.GetAuditLog)
+		adminRoute.Get("/debug/vars", moderation.Authorized(entity.PERMISSION_METRICS), iris.FromStd(expvar.Handler()))
+
+		adminRoute.Post("/chat-import/{owner:string}/{receiver:string}", moderation.Authorized(entity.PERMISSION_CHAT_IMPORT), chatImport.ImportChat)
+
+		webhookRoute := adminRoute.Party("/webhooks", moderation.Authorized(entity.PERMISSION_INTEGRATIONS))
+		webhookRoute.Post("/", webhook.CreateWebhook)
+		webhookRoute.Get("/", webhook.GetWebhooks)
+		webhookRoute.Get("/deliveries", webhook.GetDeliveries)
+		webhookRoute.Get("/deliveries/{id:string}", webhook.GetDelivery)
+		webhookRoute.Post("/deliveries/{id:string}/retry", webhook.RetryDelivery)
+		webhookRoute.Get("/{id:string}", webhook.GetWebhook)
+		webhookRoute.Put("/{id:string}", webhook.UpdateWebhook)
+		webhookRoute.Delete("/{id:string}", webhook.DeleteWebhook)
+		webhookRoute.Post("/{id:string}/secret", webhook.RotateWebhookSecret)
+		webhookRoute.Get("/{id:string}/deliveries", webhook.GetWebhookDeliveries)
+
+		botRoute := adminRoute.Party("/bots", moderation.Authorized(entity.PERMISSION_INTEGRATIONS))
+		botRoute.Post("/", bot.CreateBot)
+		botRoute.Get("/", bot.GetBots)
+		botRoute.Get("/{id:string}", bot.GetBot)
+		botRoute.Put("/{id:string}", bot.UpdateBot)
+		botRoute.Delete("/{id:string}", bot.DeleteBot)
+		botRoute.Post("/{id:string}/token", bot.RotateBotToken)
+
+		serviceAccountRoute := adminRoute.Party("/service-accounts", moderation.Authorized(entity.PERMISSION_INTEGRATIONS))
+		serviceAccountRoute.Post("/", serviceAccount.CreateServiceAccount)
+		serviceAccountRoute.Get("/", serviceAccount.GetServiceAccounts)
+		serviceAccountRoute.Get("/{id:string}", serviceAccount.GetServiceAccount)
+		serviceAccountRoute.Delete("/{id:string}", serviceAccount.DeleteServiceAccount)
+		serviceAccountRoute.Post("/{id:string}/token", serviceAccount.RotateServiceAccountToken)
+	}
+}
@@ -2,8 +2,12 @@ package router
 
 import (
 	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/router/graphql"
+	"github.com/majid-cj/go-chat-server/router/openapi"
 	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/router/rpc"
 	"github.com/majid-cj/go-chat-server/util/middleware"
+	"google.golang.org/grpc"
 )
 
 // APIVersionOne ...
@@ -11,9 +15,22 @@ func APIVersionOne(appConfig *config.AppConfig) {
 	authentication := routers.NewAuthenticationRouter(appConfig)
 	member := routers.NewMemberRouters(appConfig)
 	verifyCode := routers.NewVerifyCodeRouter(appConfig)
+	accountDeletion := routers.NewAccountDeletionRouter(appConfig)
 	profile := routers.NewMemberProfileRouter(appConfig)
 	chat := routers.NewChatRouter(appConfig)
+	chatExport := routers.NewChatExportRouter(appConfig)
+	chatImport := routers.NewChatImportRouter(appConfig)
+	report := routers.NewReportRouter(appConfig)
+	moderation := routers.NewModerationRouter(appConfig)
+	webhook := routers.NewWebhookRouter(appConfig)
+	bot := routers.NewBotRouter(appConfig, chat)
+	poll := routers.NewPollRouter(appConfig, chat)
+	pin := routers.NewPinRouter(appConfig)
+	star := routers.NewStarRouter(appConfig)
+	serviceAccount := routers.NewServiceAccountRouter(appConfig)
+	graphQL := graphql.NewGraphQLRouter(appConfig, chat)
 
+	middleware.Sessions = appConfig.Auth.Auth
 	appConfig.App.UseGlobal(middleware.RateLimit)
 
 	apiV1 := appConfig.App.Party("/api/v1")
@@ -23,13 +40,78 @@ func APIVersionOne(appConfig *config.AppConfig) {
 		apiV1.Get("/chat-list", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.GetChatList)
 		apiV1.Get("/chat-counter", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.GetChatCounter)
 
+		apiV1.Post("/chat-export/{receiver:string}", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chatExport.CreateChatExport)
+		apiV1.Get("/chat-export/{id:string}", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chatExport.GetChatExport)
+		apiV1.Get("/chat-export/{id:string}/download", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chatExport.DownloadChatExport)
+
+		apiV1.Post("/chat/{receiver:string}/voice", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.SendVoiceMessage)
+		apiV1.Get("/chat/{receiver:string}/messages", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.SyncMessages)
+		apiV1.Put("/chat/{receiver:string}/read", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.MarkChatRead)
+		apiV1.Delete("/chat/{receiver:string}", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.HideChat)
+		apiV1.Delete("/chat/message/{id:string}", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.HideMessage)
+		apiV1.Put("/chat/{receiver:string}/translate", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.SetAutoTranslate)
+		apiV1.Get("/chat/message/{id:string}/translate", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.GetMessageTranslation)
+		apiV1.Put("/chat/voice/{ref:string}/played", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.PlayVoiceMessage)
+
+		apiV1.Post("/chat/{receiver:string}/poll", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, poll.CreatePoll)
+		apiV1.Get("/chat/poll/{ref:string}", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, poll.GetPoll)
+		apiV1.Put("/chat/poll/{ref:string}/vote", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, poll.VotePoll)
+		apiV1.Put("/chat/poll/{ref:string}/close", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, poll.ClosePoll)
+		go poll.ClosePolls(appConfig.AppContext)
+
+		apiV1.Get("/chat/{receiver:string}/pins", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, pin.GetPins)
+		apiV1.Put("/chat/message/{id:string}/pin", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, pin.PinMessage)
+		apiV1.Delete("/chat/message/{id:string}/pin", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, pin.UnpinMessage)
+		apiV1.Get("/chat/starred", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, star.GetStarred)
+		apiV1.Put("/chat/message/{id:string}/star", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, star.StarMessage)
+		apiV1.Delete("/chat/message/{id:string}/star", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, star.UnstarMessage)
+		apiV1.Put("/chat/message/{id:string}/reaction", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.ReactToMessage)
+		apiV1.Delete("/chat/message/{id:string}/reaction", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, chat.RemoveReaction)
+
+		apiV1.Post("/graphql", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, graphQL.Query)
+		apiV1.Get("/graphql/ws", graphQL.HandleRequest)
+
+		apiV1.Post("/report", middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware, report.CreateReport)
+
 		apiV1.Get("/ws/{sender:string}/{receiver:string}", chat.HandleRequest)
 		appConfig.Melody.HandleConnect(chat.HandleConnect)
 		appConfig.Melody.HandleMessage(chat.HandleMessage)
+		appConfig.Melody.HandleMessageBinary(chat.HandleMessage)
 		appConfig.Melody.HandleDisconnect(chat.HandleDisconnect)
 		appConfig.Melody.HandleClose(chat.HandleClose)
+		appConfig.Melody.HandleSentMessage(appConfig.Socket.HandleSentMessage)
+		appConfig.Melody.HandleSentMessageBinary(appConfig.Socket.HandleSentMessage)
+		appConfig.Melody.HandleError(appConfig.Socket.HandleError)
 
-		MemberRouteEndPoints(authentication, member, verifyCode, apiV1)
+		MemberRouteEndPoints(authentication, member, verifyCode, accountDeletion, apiV1)
+		go accountDeletion.DeleteAccounts(appConfig.AppContext)
+		go chatExport.MaintainChatExports(appConfig.AppContext)
+		BotRouteEndPoints(bot, apiV1)
+		AdminRouteEndPoints(member, moderation, webhook, bot, serviceAccount, chatImport, apiV1)
 
 	}
 }
+
+// APIVersionTwo serves the resource-oriented API. Responses wrap their body
+// in "data", lists add "page", and errors come as "error" with a code.
+func APIVersionTwo(appConfig *config.AppConfig) {
+	chat := routers.NewChatRouter(appConfig)
+	conversation := routers.NewConversationRouter(appConfig, chat)
+
+	apiV2 := appConfig.App.Party("/api/v2")
+	{
+		ConversationRouteEndPoints(conversation, apiV2)
+	}
+}
+
+// APIDocs serves the OpenAPI document and the page to browse it.
+func APIDocs(appConfig *config.AppConfig) {
+	appConfig.App.Get(openapi.DOCS_PATH, openapi.GetDocs)
+	appConfig.App.Get(openapi.SPEC_PATH, openapi.GetSpec)
+}
+
+// RPC returns the gRPC server our backends call with a service account.
+func RPC(appConfig *config.AppConfig) *grpc.Server {
+	chat := routers.NewChatRouter(appConfig)
+	return rpc.NewServer(appConfig, chat)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/majid-cj/go-chat-server/router/routers"
+)
+
+// BotRouteEndPoints ...
+func BotRouteEndPoints(
+	bot *routers.BotRouter,
+	APIVersion router.Party,
+) {
+	botRoute := APIVersion.Party("/bot")
+	{
+		botRoute.Use(bot.AuthenticatedBot)
+
+		botRoute.Get("/me", bot.GetBotProfile)
+		botRoute.Post("/messages", bot.SendMessage)
+		botRoute.Get("/ws", bot.HandleRequest)
+	}
+}
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/util/middleware"
+)
+
+// ConversationRouteEndPoints ...
+func ConversationRouteEndPoints(
+	conversation *routers.ConversationRouter,
+	APIVersion router.Party,
+) {
+	conversationRoute := APIVersion.Party("/conversations")
+	{
+		conversationRoute.Use(middleware.AuthenticationJWTMiddleware, middleware.UniqueIdMiddleware)
+
+		conversationRoute.Post("/", conversation.CreateConversation)
+		conversationRoute.Get("/", conversation.ListConversations)
+		conversationRoute.Get("/{id:string}", conversation.GetConversation)
+		conversationRoute.Patch("/{id:string}", conversation.UpdateConversation)
+		conversationRoute.Get("/{id:string}/participants", conversation.ListParticipants)
+		conversationRoute.Post("/{id:string}/leave", conversation.LeaveConversation)
+
+		conversationRoute.Post("/{id:string}/messages", conversation.SendMessage)
+		conversationRoute.Get("/{id:string}/messages", conversation.ListMessages)
+		conversationRoute.Patch("/{id:string}/messages/{message_id:string}", conversation.EditMessage)
+		conversationRoute.Delete("/{id:string}/messages/{message_id:string}", conversation.DeleteMessage)
+	}
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// MAX_QUERY_DEPTH keeps nested selections, e.g. conversations inside
+// messages inside conversations, from running away.
+const MAX_QUERY_DEPTH = 8
+
+//go:embed schema.graphql
+var schema string
+
+// GraphQLRouter serves the GraphQL schema over HTTP for queries and
+// mutations, and over a websocket for subscriptions as well. Resolvers go
+// through the same repositories and routers as the REST API.
+type GraphQLRouter struct {
+	Config       *config.AppConfig
+	Chat         *routers.ChatRouter
+	Conversation *routers.ConversationRouter
+	Schema       *graphql.Schema
+}
+
+// NewGraphQLRouter ...
+func NewGraphQLRouter(config *config.AppConfig, chat *routers.ChatRouter) *GraphQLRouter {
+	router := &GraphQLRouter{
+		Config:       config,
+		Chat:         chat,
+		Conversation: routers.NewConversationRouter(config, chat),
+	}
+	router.Schema = graphql.MustParseSchema(schema, &resolver{router: router}, graphql.MaxDepth(MAX_QUERY_DEPTH))
+	return router
+}
+
+// Request is a GraphQL operation as clients post it.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query runs a query or mutation posted by a signed in member. The response
+// is the usual GraphQL {data, errors} rather than the REST envelope.
+func (router *GraphQLRouter) Query(c iris.Context) {
+	var request Request
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	ctx := withViewer(c.Request().Context(), &viewer{
+		Member:  auth.ExtractTokenClaims(c.Request(), "user_id"),
+		Profile: auth.ExtractTokenClaims(c.Request(), "profile_id"),
+		Lang:    util.AcceptLanguage(c.Request()),
+		Loader:  newProfileLoader(router.Config.Persistence.Profile),
+	})
+	response := router.Schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+	c.StatusCode(iris.StatusOK)
+	c.JSON(response)
+}
+
+// HandleRequest upgrades a subscription socket. Like the chat socket, it is
+// authenticated with the access token in the access query parameter.
+func (router *GraphQLRouter) HandleRequest(c iris.Context) {
+	if !auth.URLTokenValid(c.Request()) {
+		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+		return
+	}
+	if _, err := router.Config.Auth.Auth.FetchToken(auth.ExtractURLTokenClaims(c.Request(), "access_uuid")); err != nil {
+		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+		return
+	}
+	member, err := router.Config.Persistence.Member.GetMember(auth.ExtractURLTokenClaims(c.Request(), "user_id"))
+	if err != nil || !member.Active {
+		util.ResponseError(util.GetError("member_suspended"), iris.StatusForbidden, c)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.ResponseWriter(), c.Request(), nil)
+	if err != nil {
+		router.Config.Log.Debugf("graphql socket upgrade: %+v", err)
+		return
+	}
+	// profiles are not cached on a socket: a subscription lives long enough
+	// for them to change.
+	ctx := withViewer(router.Config.AppContext, &viewer{
+		Member:  member.ID,
+		Profile: auth.ExtractURLTokenClaims(c.Request(), "profile_id"),
+		Lang:    util.AcceptLanguage(c.Request()),
+	})
+	newSubscriptionConn(router, conn).Serve(ctx)
+}
+
+// Exec runs request for the viewer of ctx and streams its results: one for
+// a query or mutation, one per event for a subscription.
+func (router *GraphQLRouter) Exec(ctx context.Context, request *Request) (<-chan interface{}, error) {
+	return router.Schema.Subscribe(ctx, request.Query, request.OperationName, request.Variables)
+}
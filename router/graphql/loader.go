@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+)
+
+// profileLoader loads the profiles an operation needs in batches instead of
+// one query per field. List resolvers prime it with every id the list
+// refers to, so the fields resolved afterwards hit the cache.
+type profileLoader struct {
+	sync.Mutex
+	repo     repository.ProfileRepository
+	profiles map[string]*entity.MemberProfile
+}
+
+func newProfileLoader(repo repository.ProfileRepository) *profileLoader {
+	return &profileLoader{
+		repo:     repo,
+		profiles: make(map[string]*entity.MemberProfile),
+	}
+}
+
+// Prime loads the profiles of IDs that are not cached yet, in one query.
+// Ids without a profile are cached as missing.
+func (loader *profileLoader) Prime(IDs ...string) {
+	loader.Lock()
+	defer loader.Unlock()
+
+	missing := make([]string, 0, len(IDs))
+	for _, ID := range IDs {
+		if _, ok := loader.profiles[ID]; !ok && ID != "" {
+			loader.profiles[ID] = nil
+			missing = append(missing, ID)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	profiles, err := loader.repo.GetMemberProfilesByIDs(missing)
+	if err != nil {
+		for _, ID := range missing {
+			delete(loader.profiles, ID)
+		}
+		return
+	}
+	for index := range profiles {
+		loader.profiles[profiles[index].ID] = &profiles[index]
+	}
+}
+
+// Load returns the profile with ID, or nil when there is none.
+func (loader *profileLoader) Load(ID string) *entity.MemberProfile {
+	loader.Prime(ID)
+	loader.Lock()
+	defer loader.Unlock()
+	return loader.profiles[ID]
+}
+
+type viewerKey struct{}
+
+// viewer is who an operation runs for.
+type viewer struct {
+	Member  string
+	Profile string
+	Lang    string
+	Loader  *profileLoader
+}
+
+func withViewer(ctx context.Context, viewer *viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+func viewerFrom(ctx context.Context) *viewer {
+	viewer, _ := ctx.Value(viewerKey{}).(*viewer)
+	return viewer
+}
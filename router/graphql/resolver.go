@@ -0,0 +1,421 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/ratelimit"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// resolver is the root of the schema: its methods are the fields of Query,
+// Mutation and Subscription.
+type resolver struct {
+	router *GraphQLRouter
+}
+
+// participant returns the viewer and the other participant of the
+// conversation ID.
+func (r *resolver) participant(ctx context.Context, ID graphql.ID) (string, string, error) {
+	profile := viewerFrom(ctx).Profile
+	other, err := entity.ConversationOther(string(ID), profile)
+	if err != nil {
+		return "", "", r.router.fail(ctx, err)
+	}
+	return profile, other, nil
+}
+
+func (r *resolver) conversation(profile string, conversation *entity.Conversation) *conversationResolver {
+	return &conversationResolver{router: r.router, conversation: conversation, profile: profile}
+}
+
+// Me ...
+func (r *resolver) Me(ctx context.Context) (*memberResolver, error) {
+	member, err := r.router.Config.Persistence.Member.GetMember(viewerFrom(ctx).Member)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &memberResolver{router: r.router, member: member}, nil
+}
+
+// Profile ...
+func (r *resolver) Profile(ctx context.Context, args struct {
+	ID       *graphql.ID
+	NickName *string
+}) (*profileResolver, error) {
+	var profile *entity.MemberProfile
+	var err error
+	switch {
+	case args.ID != nil:
+		profile, err = r.router.Config.Persistence.Profile.GetMemberProfileByID(string(*args.ID))
+	case args.NickName != nil:
+		profile, err = r.router.Config.Persistence.Profile.GetMemberProfileByNickName(*args.NickName)
+	default:
+		err = util.GetError("profile_not_found")
+	}
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &profileResolver{profile}, nil
+}
+
+// Conversation ...
+func (r *resolver) Conversation(ctx context.Context, args struct{ ID graphql.ID }) (*conversationResolver, error) {
+	profile, other, err := r.participant(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := r.router.Config.Persistence.Chat.GetConversation(profile, other)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return r.conversation(profile, conversation), nil
+}
+
+// Conversations pages through the viewer's conversations with the same
+// cursors as the v2 API.
+func (r *resolver) Conversations(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*conversationConnectionResolver, error) {
+	profile := viewerFrom(ctx).Profile
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	var updatedAt time.Time
+	var ID string
+	if args.After != nil && *args.After != "" {
+		updatedAt, ID, err = entity.ParseConversationCursor(*args.After)
+		if err != nil {
+			return nil, r.router.fail(ctx, err)
+		}
+	}
+
+	conversations, err := r.router.Config.Persistence.Chat.GetConversations(profile, updatedAt, ID, limit+1)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	page := util.Page{Limit: limit}
+	if int64(len(conversations)) > limit {
+		conversations = conversations[:limit]
+		page.HasMore = true
+		page.NextCursor = entity.ConversationCursor(&conversations[limit-1])
+	}
+	return &conversationConnectionResolver{nodes: r.conversations(ctx, profile, conversations), page: page}, nil
+}
+
+// conversations wraps conversations and batches the lookup of everyone
+// taking part in them.
+func (r *resolver) conversations(ctx context.Context, profile string, conversations []entity.Conversation) []*conversationResolver {
+	participants := make([]string, 0, len(conversations)*2)
+	nodes := make([]*conversationResolver, 0, len(conversations))
+	for index := range conversations {
+		participants = append(participants, conversations[index].Participants...)
+		nodes = append(nodes, r.conversation(profile, &conversations[index]))
+	}
+	prime(ctx, participants...)
+	return nodes
+}
+
+// Messages ...
+func (r *resolver) Messages(ctx context.Context, args struct {
+	Conversation graphql.ID
+	First        int32
+	Before       *string
+}) (*messageConnectionResolver, error) {
+	profile, other, err := r.participant(ctx, args.Conversation)
+	if err != nil {
+		return nil, err
+	}
+	return r.router.messages(ctx, profile, other, args.First, args.Before)
+}
+
+// messages pages through profile's history with other, newest first. The
+// cursor is the seq to continue before.
+func (router *GraphQLRouter) messages(ctx context.Context, profile, other string, first int32, before *string) (*messageConnectionResolver, error) {
+	limit, err := pageSize(first)
+	if err != nil {
+		return nil, router.fail(ctx, err)
+	}
+	var seq int64
+	if before != nil && *before != "" {
+		seq, err = strconv.ParseInt(*before, 10, 64)
+		if err != nil || seq <= 0 {
+			return nil, router.fail(ctx, util.GetError("invalid_cursor"))
+		}
+	}
+
+	messages, err := router.Config.Persistence.Chat.GetLatestChatMessages(fmt.Sprintf("%s-%s", profile, other), seq, limit+1)
+	if err != nil {
+		return nil, router.fail(ctx, err)
+	}
+	page := util.Page{Limit: limit}
+	if int64(len(messages)) > limit {
+		messages = messages[:limit]
+		page.HasMore = true
+		page.NextCursor = strconv.FormatInt(messages[limit-1].Seq, 10)
+	}
+	router.Chat.MarkPinsAndStars(messages, profile, other)
+
+	prime(ctx, profile, other)
+	nodes := make([]*messageResolver, 0, len(messages))
+	for _, message := range messages {
+		nodes = append(nodes, &messageResolver{router: router, message: message})
+	}
+	return &messageConnectionResolver{nodes: nodes, page: page}, nil
+}
+
+// ChatList ...
+func (r *resolver) ChatList(ctx context.Context) ([]*conversationResolver, error) {
+	profile := viewerFrom(ctx).Profile
+	conversations, err := r.router.Config.Persistence.Chat.GetConversations(profile, time.Time{}, "", 0)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return r.conversations(ctx, profile, conversations), nil
+}
+
+// ChatCounter ...
+func (r *resolver) ChatCounter(ctx context.Context) (*chatCounterResolver, error) {
+	counter, err := r.router.Config.Persistence.Chat.GetChatCounter(viewerFrom(ctx).Profile)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &chatCounterResolver{counter}, nil
+}
+
+// StartConversation ...
+func (r *resolver) StartConversation(ctx context.Context, args struct{ Participant graphql.ID }) (*conversationResolver, error) {
+	profile := viewerFrom(ctx).Profile
+	participant, err := r.router.Config.Persistence.Profile.GetMemberProfileByID(string(args.Participant))
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	conversation, _, err := r.router.Config.Persistence.Chat.CreateConversation(profile, participant.ID)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	r.router.Chat.ChatListChanged(profile, participant.ID)
+	return r.conversation(profile, conversation), nil
+}
+
+// UpdateConversation ...
+func (r *resolver) UpdateConversation(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input struct {
+		Muted       *bool
+		MutedUntil  *graphql.Time
+		TranslateTo *string
+	}
+}) (*conversationResolver, error) {
+	profile, other, err := r.participant(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	request := entity.UpdateConversationRequest{
+		Muted:       args.Input.Muted,
+		TranslateTo: args.Input.TranslateTo,
+	}
+	if args.Input.MutedUntil != nil {
+		request.MutedUntil = &args.Input.MutedUntil.Time
+	}
+	conversation, err := r.router.Conversation.Update(profile, other, &request)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return r.conversation(profile, conversation), nil
+}
+
+// LeaveConversation ...
+func (r *resolver) LeaveConversation(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	profile, other, err := r.participant(ctx, args.ID)
+	if err != nil {
+		return false, err
+	}
+	err = r.router.Config.Persistence.Chat.HideChat(profile, other)
+	if err != nil {
+		return false, r.router.fail(ctx, err)
+	}
+	r.router.Chat.ChatListChanged(profile, other)
+	return true, nil
+}
+
+// SendMessage sends a text message the same way the v2 API does, flood
+// control and client_msg_id included.
+func (r *resolver) SendMessage(ctx context.Context, args struct {
+	Conversation graphql.ID
+	Message      string
+	ClientMsgId  *string
+}) (*messageResolver, error) {
+	profile, other, err := r.participant(ctx, args.Conversation)
+	if err != nil {
+		return nil, err
+	}
+	if verdict := r.router.Conversation.CheckFlood(profile, other); verdict != nil {
+		code := "muted"
+		if verdict.Verdict == ratelimit.FLOOD_SLOW_DOWN {
+			code = "slow_down"
+		}
+		return nil, r.router.fail(ctx, util.GetError(code))
+	}
+
+	request := entity.ConversationMessageRequest{Message: args.Message}
+	if args.ClientMsgId != nil {
+		request.ClientMsgId = *args.ClientMsgId
+	}
+	message, _, err := r.router.Conversation.PostMessage(profile, other, &request)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &messageResolver{router: r.router, message: *message}, nil
+}
+
+// EditMessage ...
+func (r *resolver) EditMessage(ctx context.Context, args struct {
+	Conversation graphql.ID
+	ID           graphql.ID
+	Message      string
+}) (*messageResolver, error) {
+	profile, other, err := r.participant(ctx, args.Conversation)
+	if err != nil {
+		return nil, err
+	}
+	message, err := r.router.Config.Persistence.Chat.GetChatMessage(profile, string(args.ID))
+	if err != nil || message.Conversation != string(args.Conversation) {
+		return nil, r.router.fail(ctx, util.GetError("message_not_found"))
+	}
+	edited, err := r.router.Conversation.Edit(profile, other, message, args.Message)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &messageResolver{router: r.router, message: *edited}, nil
+}
+
+// MarkRead marks the conversation as read up to seq, or all of it.
+func (r *resolver) MarkRead(ctx context.Context, args struct {
+	Conversation graphql.ID
+	Seq          *int32
+}) (*readReceiptResolver, error) {
+	profile, other, err := r.participant(ctx, args.Conversation)
+	if err != nil {
+		return nil, err
+	}
+	var seq int64
+	if args.Seq != nil {
+		seq = int64(*args.Seq)
+	}
+	read, err := r.router.Chat.MarkRead(profile, other, seq)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	return &readReceiptResolver{read}, nil
+}
+
+// UpdateProfile changes the viewer's display name and nick name.
+func (r *resolver) UpdateProfile(ctx context.Context, args struct {
+	Input struct {
+		DisplayName *string
+		NickName    *string
+	}
+}) (*profileResolver, error) {
+	profile, err := r.router.Config.Persistence.Profile.GetMemberProfileByID(viewerFrom(ctx).Profile)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	if args.Input.DisplayName != nil {
+		profile.DisplayName = *args.Input.DisplayName
+	}
+	if args.Input.NickName != nil {
+		profile.NickName = strings.ToLower(*args.Input.NickName)
+	}
+	if err = profile.ValidateMemberProfile(); err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	updated, err := r.router.Config.Persistence.Profile.UpdateMemberProfile(profile)
+	if err != nil {
+		return nil, r.router.fail(ctx, err)
+	}
+	go r.router.Config.SendNotifications(entity.WEBHOOK_PROFILE_UPDATED, updated)
+	return &profileResolver{updated}, nil
+}
+
+// MessageAdded streams the messages the viewer sends and receives, as the
+// viewer sees them, optionally in one conversation only.
+func (r *resolver) MessageAdded(ctx context.Context, args struct{ Conversation *graphql.ID }) (<-chan *messageResolver, error) {
+	profile := viewerFrom(ctx).Profile
+	if args.Conversation != nil {
+		if _, _, err := r.participant(ctx, *args.Conversation); err != nil {
+			return nil, err
+		}
+	}
+
+	events, cancel := r.router.Config.Events.Subscribe(entity.MessageTopic(profile))
+	messages := make(chan *messageResolver)
+	go func() {
+		defer close(messages)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				message, ok := event.(entity.ChatMessage)
+				if !ok || (args.Conversation != nil && message.Conversation != string(*args.Conversation)) {
+					continue
+				}
+				select {
+				case messages <- &messageResolver{router: r.router, message: message}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+// ChatListChanged streams the conversations on the viewer's chat list as
+// they change: new messages, reads, mutes and hides.
+func (r *resolver) ChatListChanged(ctx context.Context) (<-chan *conversationResolver, error) {
+	profile := viewerFrom(ctx).Profile
+	events, cancel := r.router.Config.Events.Subscribe(entity.ChatListTopic(profile))
+	conversations := make(chan *conversationResolver)
+	go func() {
+		defer close(conversations)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				key, _ := event.(string)
+				other, err := entity.ConversationOther(key, profile)
+				if err != nil {
+					continue
+				}
+				conversation, err := r.router.Config.Persistence.Chat.GetConversation(profile, other)
+				if err != nil {
+					continue
+				}
+				select {
+				case conversations <- r.conversation(profile, conversation):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return conversations, nil
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// the graphql-transport-ws protocol, as spoken by graphql-ws clients
+const (
+	SUBSCRIPTION_PROTOCOL = "graphql-transport-ws"
+
+	MESSAGE_CONNECTION_INIT = "connection_init"
+	MESSAGE_CONNECTION_ACK  = "connection_ack"
+	MESSAGE_PING            = "ping"
+	MESSAGE_PONG            = "pong"
+	MESSAGE_SUBSCRIBE       = "subscribe"
+	MESSAGE_NEXT            = "next"
+	MESSAGE_ERROR           = "error"
+	MESSAGE_COMPLETE        = "complete"
+
+	// CONNECTION_INIT_TIMEOUT is how long a client has to send
+	// connection_init after connecting.
+	CONNECTION_INIT_TIMEOUT = 10 * time.Second
+	// MAX_SUBSCRIPTION_MESSAGE_SIZE ...
+	MAX_SUBSCRIPTION_MESSAGE_SIZE = 64 * 1024
+)
+
+// close codes of the protocol
+const (
+	CLOSE_BAD_REQUEST     = 4400
+	CLOSE_UNAUTHORIZED    = 4401
+	CLOSE_INIT_TIMEOUT    = 4408
+	CLOSE_DUPLICATE_ID    = 4409
+	CLOSE_TOO_MANY_INITS  = 4429
+	CLOSE_INTERNAL_SERVER = 4500
+)
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{SUBSCRIPTION_PROTOCOL},
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// wsMessage is one frame of the protocol.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// subscriptionConn runs the operations of one socket. Each operation has
+// its own context, cancelled when the client completes it or the socket
+// closes.
+type subscriptionConn struct {
+	sync.Mutex
+	router     *GraphQLRouter
+	conn       *websocket.Conn
+	operations map[string]context.CancelFunc
+	writeLock  sync.Mutex
+}
+
+func newSubscriptionConn(router *GraphQLRouter, conn *websocket.Conn) *subscriptionConn {
+	return &subscriptionConn{
+		router:     router,
+		conn:       conn,
+		operations: make(map[string]context.CancelFunc),
+	}
+}
+
+// Serve reads frames until the socket closes.
+func (socket *subscriptionConn) Serve(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	defer socket.conn.Close()
+
+	socket.conn.SetReadLimit(MAX_SUBSCRIPTION_MESSAGE_SIZE)
+	socket.conn.SetReadDeadline(time.Now().Add(CONNECTION_INIT_TIMEOUT))
+	acknowledged := false
+	for {
+		var message wsMessage
+		if err := socket.conn.ReadJSON(&message); err != nil {
+			if !acknowledged {
+				socket.Close(CLOSE_INIT_TIMEOUT, "Connection initialisation timeout")
+			}
+			return
+		}
+
+		switch message.Type {
+		case MESSAGE_CONNECTION_INIT:
+			if acknowledged {
+				socket.Close(CLOSE_TOO_MANY_INITS, "Too many initialisation requests")
+				return
+			}
+			acknowledged = true
+			socket.conn.SetReadDeadline(time.Time{})
+			socket.Write(wsMessage{Type: MESSAGE_CONNECTION_ACK})
+		case MESSAGE_PING:
+			socket.Write(wsMessage{Type: MESSAGE_PONG})
+		case MESSAGE_PONG:
+		case MESSAGE_SUBSCRIBE:
+			if !acknowledged {
+				socket.Close(CLOSE_UNAUTHORIZED, "Unauthorized")
+				return
+			}
+			var request Request
+			if message.ID == "" || json.Unmarshal(message.Payload, &request) != nil {
+				socket.Close(CLOSE_BAD_REQUEST, "Invalid subscribe message")
+				return
+			}
+			if !socket.Start(ctx, message.ID, &request) {
+				socket.Close(CLOSE_DUPLICATE_ID, fmt.Sprintf("Subscriber for %s already exists", message.ID))
+				return
+			}
+		case MESSAGE_COMPLETE:
+			socket.Stop(message.ID)
+		default:
+			socket.Close(CLOSE_BAD_REQUEST, fmt.Sprintf("Unknown message type %s", message.Type))
+			return
+		}
+	}
+}
+
+// Start runs request as the operation ID. It returns false when an
+// operation with that ID is already running.
+func (socket *subscriptionConn) Start(parent context.Context, ID string, request *Request) bool {
+	socket.Lock()
+	if _, ok := socket.operations[ID]; ok {
+		socket.Unlock()
+		return false
+	}
+	ctx, cancel := context.WithCancel(parent)
+	socket.operations[ID] = cancel
+	socket.Unlock()
+
+	go func() {
+		defer socket.Stop(ID)
+		results, err := socket.router.Exec(ctx, request)
+		if err != nil {
+			socket.Close(CLOSE_INTERNAL_SERVER, "Subscriptions are not available")
+			return
+		}
+		for result := range results {
+			response, ok := result.(*graphql.Response)
+			if !ok {
+				continue
+			}
+			if len(response.Errors) > 0 && (len(response.Data) == 0 || string(response.Data) == "null") {
+				payload, _ := json.Marshal(response.Errors)
+				socket.Write(wsMessage{ID: ID, Type: MESSAGE_ERROR, Payload: payload})
+				return
+			}
+			payload, _ := json.Marshal(response)
+			socket.Write(wsMessage{ID: ID, Type: MESSAGE_NEXT, Payload: payload})
+		}
+		if ctx.Err() == nil {
+			socket.Write(wsMessage{ID: ID, Type: MESSAGE_COMPLETE})
+		}
+	}()
+	return true
+}
+
+// Stop cancels the operation ID.
+func (socket *subscriptionConn) Stop(ID string) {
+	socket.Lock()
+	cancel, ok := socket.operations[ID]
+	delete(socket.operations, ID)
+	socket.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// Write sends message. Operations write from their own goroutines, so
+// writes take turns.
+func (socket *subscriptionConn) Write(message wsMessage) error {
+	socket.writeLock.Lock()
+	defer socket.writeLock.Unlock()
+	return socket.conn.WriteJSON(message)
+}
+
+// Close closes the socket with one of the protocol's close codes.
+func (socket *subscriptionConn) Close(code int, reason string) {
+	socket.writeLock.Lock()
+	defer socket.writeLock.Unlock()
+	socket.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	socket.conn.Close()
+}
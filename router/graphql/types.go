@@ -0,0 +1,259 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// Error is a resolver error. Its message is translated for the viewer and
+// its locale key is sent as the code extension.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Extensions ...
+func (err *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": err.Code}
+}
+
+// fail turns err into an Error translated for the viewer of ctx.
+func (router *GraphQLRouter) fail(ctx context.Context, err error) error {
+	code := fmt.Sprintf("%+v", err)
+	lang := ""
+	if viewer := viewerFrom(ctx); viewer != nil {
+		lang = viewer.Lang
+	}
+	return &Error{Code: code, Message: router.Config.App.I18n.Tr(lang, code)}
+}
+
+// profile returns the profile with ID, through the operation's loader when
+// it has one.
+func (router *GraphQLRouter) profile(ctx context.Context, ID string) *profileResolver {
+	var profile *entity.MemberProfile
+	if viewer := viewerFrom(ctx); viewer != nil && viewer.Loader != nil {
+		profile = viewer.Loader.Load(ID)
+	} else {
+		profile, _ = router.Config.Persistence.Profile.GetMemberProfileByID(ID)
+	}
+	if profile == nil {
+		return nil
+	}
+	return &profileResolver{profile}
+}
+
+// prime batches the profile lookups the fields of a list are about to make.
+func prime(ctx context.Context, IDs ...string) {
+	if viewer := viewerFrom(ctx); viewer != nil && viewer.Loader != nil {
+		viewer.Loader.Prime(IDs...)
+	}
+}
+
+func toTime(value *time.Time) *graphql.Time {
+	if value == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *value}
+}
+
+func toString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// pageSize checks the first argument of a list.
+func pageSize(first int32) (int64, error) {
+	if first <= 0 || first > entity.MAX_CONVERSATION_PAGE_SIZE {
+		return 0, util.GetError("invalid_page_size")
+	}
+	return int64(first), nil
+}
+
+type pageInfoResolver struct {
+	page util.Page
+}
+
+func (r *pageInfoResolver) Limit() int32        { return int32(r.page.Limit) }
+func (r *pageInfoResolver) NextCursor() *string { return toString(r.page.NextCursor) }
+func (r *pageInfoResolver) HasMore() bool       { return r.page.HasMore }
+
+type memberResolver struct {
+	router *GraphQLRouter
+	member *entity.Member
+}
+
+func (r *memberResolver) ID() graphql.ID          { return graphql.ID(r.member.ID) }
+func (r *memberResolver) Email() string           { return r.member.Email }
+func (r *memberResolver) Verified() bool          { return r.member.Verified }
+func (r *memberResolver) Active() bool            { return r.member.Active }
+func (r *memberResolver) Role() string            { return r.member.Role }
+func (r *memberResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.member.CreatedAt} }
+
+func (r *memberResolver) Profile() *profileResolver {
+	profile, err := r.router.Config.Persistence.Profile.GetMemberProfileByMemberID(r.member.ID)
+	if err != nil {
+		return nil
+	}
+	return &profileResolver{profile}
+}
+
+type profileResolver struct {
+	profile *entity.MemberProfile
+}
+
+func (r *profileResolver) ID() graphql.ID          { return graphql.ID(r.profile.ID) }
+func (r *profileResolver) DisplayName() string     { return r.profile.DisplayName }
+func (r *profileResolver) NickName() string        { return r.profile.NickName }
+func (r *profileResolver) ProfileImage() string    { return r.profile.ProfileImage }
+func (r *profileResolver) IsPrivate() bool         { return r.profile.Private }
+func (r *profileResolver) IsBot() bool             { return r.profile.Bot }
+func (r *profileResolver) IsDeleted() bool         { return r.profile.Deleted }
+func (r *profileResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.profile.CreatedAt} }
+
+// conversationResolver is a conversation as the viewer sees it.
+type conversationResolver struct {
+	router       *GraphQLRouter
+	conversation *entity.Conversation
+	profile      string
+}
+
+func (r *conversationResolver) member() entity.ConversationMember {
+	if member := r.conversation.Member(r.profile); member != nil {
+		return *member
+	}
+	return entity.ConversationMember{}
+}
+
+func (r *conversationResolver) ID() graphql.ID { return graphql.ID(r.conversation.ID) }
+
+func (r *conversationResolver) Participants(ctx context.Context) []*profileResolver {
+	prime(ctx, r.conversation.Participants...)
+	profiles := make([]*profileResolver, 0, len(r.conversation.Participants))
+	for _, participant := range r.conversation.Participants {
+		if profile := r.router.profile(ctx, participant); profile != nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+func (r *conversationResolver) Other(ctx context.Context) *profileResolver {
+	return r.router.profile(ctx, r.conversation.Other(r.profile))
+}
+
+func (r *conversationResolver) Seq() int32 { return int32(r.conversation.Seq) }
+
+func (r *conversationResolver) LastMessageId() *graphql.ID {
+	if r.conversation.LastMessageId == "" {
+		return nil
+	}
+	ID := graphql.ID(r.conversation.LastMessageId)
+	return &ID
+}
+
+func (r *conversationResolver) LastMessage() string { return r.conversation.LastMessage }
+
+func (r *conversationResolver) LastSender(ctx context.Context) *profileResolver {
+	if r.conversation.LastSender == "" {
+		return nil
+	}
+	return r.router.profile(ctx, r.conversation.LastSender)
+}
+
+func (r *conversationResolver) UnreadCount() int32        { return int32(r.member().UnreadCount) }
+func (r *conversationResolver) LastReadSeq() int32        { return int32(r.member().LastReadSeq) }
+func (r *conversationResolver) Muted() bool               { return r.member().Muted }
+func (r *conversationResolver) MutedUntil() *graphql.Time { return toTime(r.member().MutedUntil) }
+func (r *conversationResolver) TranslateTo() *string      { return toString(r.member().TranslateTo) }
+func (r *conversationResolver) Hidden() bool              { return r.member().Hidden }
+func (r *conversationResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.conversation.CreatedAt}
+}
+func (r *conversationResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.conversation.UpdatedAt}
+}
+
+func (r *conversationResolver) Messages(ctx context.Context, args struct {
+	First  int32
+	Before *string
+}) (*messageConnectionResolver, error) {
+	return r.router.messages(ctx, r.profile, r.conversation.Other(r.profile), args.First, args.Before)
+}
+
+type conversationConnectionResolver struct {
+	nodes []*conversationResolver
+	page  util.Page
+}
+
+func (r *conversationConnectionResolver) Nodes() []*conversationResolver { return r.nodes }
+func (r *conversationConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{r.page}
+}
+
+// messageResolver is a message as the viewer sees it.
+type messageResolver struct {
+	router  *GraphQLRouter
+	message entity.ChatMessage
+}
+
+func (r *messageResolver) ID() graphql.ID           { return graphql.ID(r.message.Ref) }
+func (r *messageResolver) Conversation() graphql.ID { return graphql.ID(r.message.Conversation) }
+func (r *messageResolver) Seq() int32               { return int32(r.message.Seq) }
+func (r *messageResolver) Type() string             { return r.message.Type }
+func (r *messageResolver) Message() string          { return r.message.Message }
+func (r *messageResolver) ClientMsgId() *string     { return toString(r.message.ClientMsgId) }
+func (r *messageResolver) Pinned() bool             { return r.message.Pinned }
+func (r *messageResolver) Starred() bool            { return r.message.Starred }
+func (r *messageResolver) EditedAt() *graphql.Time  { return toTime(r.message.EditedAt) }
+func (r *messageResolver) CreatedAt() graphql.Time  { return graphql.Time{Time: r.message.CreatedAt} }
+
+func (r *messageResolver) Sender(ctx context.Context) *profileResolver {
+	return r.router.profile(ctx, r.message.Sender)
+}
+
+func (r *messageResolver) Receiver(ctx context.Context) *profileResolver {
+	return r.router.profile(ctx, r.message.Receiver)
+}
+
+func (r *messageResolver) Translation() *string {
+	if r.message.Translation == nil {
+		return nil
+	}
+	return &r.message.Translation.Text
+}
+
+type messageConnectionResolver struct {
+	nodes []*messageResolver
+	page  util.Page
+}
+
+func (r *messageConnectionResolver) Nodes() []*messageResolver   { return r.nodes }
+func (r *messageConnectionResolver) PageInfo() *pageInfoResolver { return &pageInfoResolver{r.page} }
+
+type readReceiptResolver struct {
+	read *entity.ChatMessageRead
+}
+
+func (r *readReceiptResolver) Reader() graphql.ID   { return graphql.ID(r.read.Reader) }
+func (r *readReceiptResolver) ID() graphql.ID       { return graphql.ID(r.read.Ref) }
+func (r *readReceiptResolver) Seq() int32           { return int32(r.read.Seq) }
+func (r *readReceiptResolver) UnreadCount() int32   { return int32(r.read.UnreadCount) }
+func (r *readReceiptResolver) ReadAt() graphql.Time { return graphql.Time{Time: r.read.ReadAt} }
+
+type chatCounterResolver struct {
+	counter *entity.ChatCounter
+}
+
+func (r *chatCounterResolver) UnreadMessages() int32 { return int32(r.counter.UnreadMessages) }
+func (r *chatCounterResolver) UnreadChats() int32    { return int32(r.counter.UnreadChats) }
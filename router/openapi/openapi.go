@@ -0,0 +1,59 @@
+// Package openapi serves the OpenAPI document of the REST API and a page to
+// browse and try it. The document is the contract client types are
+// generated from, and a test keeps it in step with the routes.
+package openapi
+
+import (
+	_ "embed"
+
+	"github.com/kataras/iris/v12"
+)
+
+//go:generate oapi-codegen -generate types -package client -o ../../client/types.gen.go openapi.yaml
+
+const (
+	// DOCS_PATH ...
+	DOCS_PATH = "/api/docs"
+	// SPEC_PATH ...
+	SPEC_PATH = DOCS_PATH + "/openapi.yaml"
+)
+
+// Spec is the OpenAPI document.
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// page loads Swagger UI from a CDN so the binary only carries the document.
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Go Chat Server API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({
+	url: "` + SPEC_PATH + `",
+	dom_id: "#swagger-ui",
+	deepLinking: true,
+	persistAuthorization: true,
+});
+</script>
+</body>
+</html>
+`
+
+// GetSpec ...
+func GetSpec(c iris.Context) {
+	c.ContentType("application/yaml")
+	c.Write(Spec)
+}
+
+// GetDocs serves the interactive documentation.
+func GetDocs(c iris.Context) {
+	c.HTML(page)
+}
@@ -0,0 +1,155 @@
+package router
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/infrastructure/persistence"
+	"github.com/majid-cj/go-chat-server/router/graphql"
+	"github.com/majid-cj/go-chat-server/router/openapi"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+	"github.com/olahol/melody"
+	"github.com/stretchr/testify/assert"
+)
+
+// routes registers the API on an app without connecting to anything, the
+// same way main does.
+func routes(t *testing.T) []string {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	appConfig := &config.AppConfig{
+		App:         iris.New(),
+		Melody:      melody.New(),
+		Auth:        &auth.DBAuth{},
+		Persistence: &persistence.Repository{},
+		AppContext:  ctx,
+	}
+	APIVersionOne(appConfig)
+	APIVersionTwo(appConfig)
+	APIDocs(appConfig)
+
+	parameter := regexp.MustCompile(`\{([a-z_]+):[a-z]+\}`)
+	var operations []string
+	for _, route := range appConfig.App.GetRoutes() {
+		path := parameter.ReplaceAllString(route.Tmpl().Src, "{$1}")
+		operations = append(operations, route.Method+" "+path)
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func spec(t *testing.T) *openapi3.T {
+	document, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	assert.NoError(t, err)
+	assert.NoError(t, document.Validate(context.Background()))
+	return document
+}
+
+func Test_OpenAPIValid(t *testing.T) {
+	spec(t)
+}
+
+func Test_OpenAPIRoutes(t *testing.T) {
+	var documented []string
+	for path, item := range spec(t).Paths {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+	sort.Strings(documented)
+
+	assert.Equal(t, routes(t), documented)
+}
+
+// Test_OpenAPISchemas checks the fields of each schema against the JSON the
+// type it describes encodes to.
+func Test_OpenAPISchemas(t *testing.T) {
+	types := map[string]interface{}{
+		"Page":                       util.Page{},
+		"SignUp":                     entity.SignUp{},
+		"TokenDetail":                auth.TokenDetail{},
+		"Member":                     entity.MemberSerializer{},
+		"MemberRoleRequest":          entity.MemberRoleRequest{},
+		"AccountDeletionRequest":     entity.AccountDeletionRequest{},
+		"AccountDeletion":            entity.AccountDeletion{},
+		"Profile":                    entity.MemberProfile{},
+		"Attachment":                 entity.Attachment{},
+		"Preview":                    linkpreview.Preview{},
+		"MessageTranslation":         entity.MessageTranslation{},
+		"ChatMessage":                entity.ChatMessage{},
+		"ChatSync":                   entity.ChatSync{},
+		"ChatRoom":                   entity.RetrieveChatRoom{},
+		"ChatCounter":                entity.ChatCounter{},
+		"ChatListSummary":            entity.ChatListSummary{},
+		"ChatMessageRead":            entity.ChatMessageRead{},
+		"AutoTranslateRequest":       entity.AutoTranslateRequest{},
+		"ChatExport":                 entity.ChatExport{},
+		"Poll":                       entity.Poll{},
+		"PollOption":                 entity.PollOption{},
+		"PollRequest":                entity.PollRequest{},
+		"PollVoteRequest":            entity.PollVoteRequest{},
+		"ChatPin":                    entity.ChatPin{},
+		"PinnedMessage":              entity.PinnedMessage{},
+		"ChatStar":                   entity.ChatStar{},
+		"StarredMessage":             entity.StarredMessage{},
+		"ReactionRequest":            entity.ReactionRequest{},
+		"GraphQLRequest":             graphql.Request{},
+		"ReportRequest":              entity.ReportRequest{},
+		"Resolution":                 entity.Resolution{},
+		"Report":                     entity.Report{},
+		"ModerationReason":           entity.ModerationReason{},
+		"ModerationReview":           entity.ModerationReview{},
+		"AuditLog":                   entity.AuditLog{},
+		"WebhookRequest":             entity.WebhookRequest{},
+		"Webhook":                    entity.Webhook{},
+		"WebhookAttempt":             entity.WebhookAttempt{},
+		"WebhookDelivery":            entity.WebhookDelivery{},
+		"BotRequest":                 entity.BotRequest{},
+		"BotMessage":                 entity.BotMessage{},
+		"Bot":                        entity.Bot{},
+		"ServiceAccountRequest":      entity.ServiceAccountRequest{},
+		"ServiceAccount":             entity.ServiceAccount{},
+		"ConversationRequest":        entity.ConversationRequest{},
+		"UpdateConversationRequest":  entity.UpdateConversationRequest{},
+		"ConversationMessageRequest": entity.ConversationMessageRequest{},
+		"ConversationResource":       entity.ConversationResource{},
+		"ConversationParticipant":    entity.ConversationParticipant{},
+	}
+
+	schemas := spec(t).Components.Schemas
+	for name, value := range types {
+		schema, ok := schemas[name]
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		var properties []string
+		for property := range schema.Value.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		assert.Equal(t, fields(reflect.TypeOf(value)), properties, name)
+	}
+}
+
+func fields(value reflect.Type) []string {
+	var names []string
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
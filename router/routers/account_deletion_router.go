@@ -0,0 +1,254 @@
+package routers
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/security"
+	"github.com/olahol/melody"
+
+	"github.com/kataras/iris/v12"
+)
+
+// ACCOUNT_DELETION_INTERVAL is how often accounts past their grace period are
+// deleted.
+const ACCOUNT_DELETION_INTERVAL = time.Minute
+
+// AccountDeletionRouter ...
+type AccountDeletionRouter struct {
+	Config *config.AppConfig
+}
+
+// NewAccountDeletionRouter ...
+func NewAccountDeletionRouter(config *config.AppConfig) *AccountDeletionRouter {
+	return &AccountDeletionRouter{
+		Config: config,
+	}
+}
+
+// RequestAccountDeletion schedules the deletion of the member's account once
+// the grace period is over. The member confirms it with its password.
+func (router *AccountDeletionRouter) RequestAccountDeletion(c iris.Context) {
+	var data entity.AccountDeletionRequest
+	var deletion entity.AccountDeletion
+
+	err := c.ReadJSON(&data)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	member, err := router.Config.Persistence.Member.GetMember(auth.ExtractTokenClaims(c.Request(), "user_id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if !security.EqualPassHash(member.ID, member.Email, data.Password, member.Password) {
+		util.ResponseError(util.GetError("email_password_wrong"), iris.StatusUnauthorized, c)
+		return
+	}
+	if _, err := router.Config.Persistence.AccountDeletion.GetAccountDeletion(member.ID); err == nil {
+		util.ResponseError(util.GetError("account_deletion_pending"), iris.StatusConflict, c)
+		return
+	}
+
+	profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(auth.ExtractTokenClaims(c.Request(), "profile_id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	deletion.PrepareAccountDeletion(member, profile.ID, entity.DeletionGrace())
+	err = router.Config.Persistence.AccountDeletion.CreateAccountDeletion(&deletion)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	err = util.SendMail([]string{member.Email}, util.AccountDeletionMail{
+		ReceiverMail: member.Email,
+		ReceiverName: profile.DisplayName,
+		DeleteAt:     deletion.DeleteAt.Format(time.ANSIC),
+	}, "account_deletion.txt", "Your Account Will Be Deleted")
+	if err != nil {
+		router.Config.Log.Errorf("account deletion mail to %s: %+v", member.ID, err)
+	}
+
+	util.Response(deletion, iris.StatusAccepted, c)
+}
+
+// GetAccountDeletion returns the member's deletion that has not run yet.
+func (router *AccountDeletionRouter) GetAccountDeletion(c iris.Context) {
+	deletion, err := router.Config.Persistence.AccountDeletion.GetAccountDeletion(auth.ExtractTokenClaims(c.Request(), "user_id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(deletion, iris.StatusOK, c)
+}
+
+// CancelAccountDeletion keeps the account. It only works during the grace
+// period.
+func (router *AccountDeletionRouter) CancelAccountDeletion(c iris.Context) {
+	err := router.Config.Persistence.AccountDeletion.CancelAccountDeletion(auth.ExtractTokenClaims(c.Request(), "user_id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// DeleteAccounts deletes accounts as their grace period ends, until ctx is
+// done. A deletion that fails is put back and tried again on the next tick,
+// and one left running by a replica that stopped is taken over once its
+// claim runs out.
+func (router *AccountDeletionRouter) DeleteAccounts(ctx context.Context) {
+	tick := time.NewTicker(ACCOUNT_DELETION_INTERVAL)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			now := util.GetTimeNow()
+			deletions, err := router.Config.Persistence.AccountDeletion.GetDueAccountDeletions(now)
+			if err != nil {
+				router.Config.Log.Errorf("listing due account deletions: %+v", err)
+				continue
+			}
+			for _, due := range deletions {
+				deletion, err := router.Config.Persistence.AccountDeletion.ClaimAccountDeletion(due.ID, now)
+				if err != nil {
+					continue
+				}
+				if err := router.DeleteAccount(deletion); err != nil {
+					router.Config.Log.Errorf("deleting account %s: %+v", deletion.Member, err)
+					if err := router.Config.Persistence.AccountDeletion.SetAccountDeletionStatus(deletion.ID, entity.DELETION_PENDING); err != nil {
+						router.Config.Log.Errorf("releasing account deletion %s: %+v", deletion.ID, err)
+					}
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeleteAccount signs the member out, removes the messages it sent with their
+// files, pins, stars, translations and polls, takes it out of its
+// conversations, removes its reactions, exports, verification codes and
+// profile image, anonymizes its profile and deletes the member. Every step
+// can run again, so a deletion that failed half way is finished by the next
+// attempt.
+func (router *AccountDeletionRouter) DeleteAccount(deletion *entity.AccountDeletion) error {
+	profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(deletion.Profile)
+	if err != nil {
+		return err
+	}
+	name := profile.DisplayName
+	if profile.Deleted {
+		name = ""
+	}
+
+	err = router.Config.Auth.Auth.DeleteMemberTokens(deletion.Member)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	router.Config.CloseProfileSessions(profile.ID, melody.CloseNormalClosure, "account deleted")
+
+	_, err = router.Config.Persistence.Chat.IterateSentMessages(profile.ID, router.deleteMessage)
+	if err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.Chat.DeleteSentMessages(profile.ID); err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.Chat.LeaveConversations(profile.ID); err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.Star.DeleteProfileStars(profile.ID); err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.Chat.DeleteProfileReactions(profile.ID); err != nil {
+		return err
+	}
+	if err = router.deleteChatExports(profile.ID); err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.VerifyCode.DeleteVerificationCodes(deletion.Member); err != nil {
+		return err
+	}
+
+	if profile.ProfileImage != os.Getenv("DEFAULT_PROFILE_PIC") {
+		if err = router.Config.Upload.DeleteFile(profile.ProfileImage); err != nil {
+			return err
+		}
+	}
+	profile.Anonymize()
+	if _, err = router.Config.Persistence.Profile.UpdateMemberProfile(profile); err != nil {
+		return err
+	}
+	if err = router.Config.Persistence.Member.DeleteMember(deletion.Member); err != nil {
+		return err
+	}
+
+	go router.Config.SendNotifications(entity.WEBHOOK_MEMBER_DELETED, map[string]interface{}{
+		"member":  deletion.Member,
+		"profile": deletion.Profile,
+	})
+
+	if deletion.Email != "" {
+		err = util.SendMail([]string{deletion.Email}, util.AccountDeletionMail{
+			ReceiverMail: deletion.Email,
+			ReceiverName: name,
+		}, "account_deleted.txt", "Your Account Was Deleted")
+		if err != nil {
+			router.Config.Log.Errorf("account deleted mail to %s: %+v", deletion.Member, err)
+		}
+	}
+
+	return router.Config.Persistence.AccountDeletion.CompleteAccountDeletion(deletion.ID, util.GetTimeNow())
+}
+
+// deleteMessage removes what is stored alongside a message the deleted
+// member sent. The message itself goes with DeleteSentMessages.
+func (router *AccountDeletionRouter) deleteMessage(message *entity.ChatMessage) error {
+	ref := entity.MessageRef(message)
+	if message.Attachment != nil {
+		if err := router.Config.Upload.DeleteFile(message.Attachment.URL); err != nil {
+			return err
+		}
+	}
+	if message.Type == entity.MESSAGE_POLL {
+		if err := router.Config.Persistence.Poll.DeletePoll(ref); err != nil {
+			return err
+		}
+	}
+	if err := router.Config.Persistence.Pin.DeleteMessagePins(ref); err != nil {
+		return err
+	}
+	if err := router.Config.Persistence.Star.DeleteMessageStars(ref); err != nil {
+		return err
+	}
+	return router.Config.Persistence.Translation.DeleteTranslations(ref)
+}
+
+// deleteChatExports removes the export files profile made and their records.
+func (router *AccountDeletionRouter) deleteChatExports(profile string) error {
+	exports, err := router.Config.Persistence.ChatExport.GetChatExports(profile)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if export.File == "" {
+			continue
+		}
+		if err := os.Remove(export.File); err != nil && !os.IsNotExist(err) {
+			return util.GetError("general_error")
+		}
+	}
+	return router.Config.Persistence.ChatExport.DeleteChatExports(profile)
+}
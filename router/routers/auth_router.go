@@ -99,6 +99,11 @@ func (router *AuthenticationRouter) SignUp(c iris.Context) {
 		return
 	}
 
+	go router.Config.SendNotifications(entity.WEBHOOK_MEMBER_SIGNED_UP, map[string]interface{}{
+		"member":  newMember.GetMemberSerializer(),
+		"profile": newProfile,
+	})
+
 	memberResponse := make(map[string]interface{})
 
 	memberResponse["token"] = token
@@ -128,6 +133,18 @@ func (router *AuthenticationRouter) SignIn(c iris.Context) {
 		util.ResponseError(err, iris.StatusNotFound, c)
 		return
 	}
+	if memberLogin.IsBot() {
+		util.ResponseError(util.GetError("email_password_wrong"), iris.StatusNotFound, c)
+		return
+	}
+	if !memberLogin.Active {
+		util.ResponseError(util.GetError("member_suspended"), iris.StatusForbidden, c)
+		return
+	}
+	if memberLogin.PasswordReset {
+		util.ResponseError(util.GetError("password_reset_required"), iris.StatusForbidden, c)
+		return
+	}
 	profile, err := router.Config.Persistence.Profile.GetMemberProfileByMemberID(memberLogin.ID)
 	if err != nil {
 		util.ResponseError(err, iris.StatusNotFound, c)
@@ -252,6 +269,19 @@ func (router *AuthenticationRouter) Refresh(c iris.Context) {
 			util.ResponseError(util.GetError("general_error"), iris.StatusUnauthorized, c)
 			return
 		}
+		if _, err := router.Config.Auth.Auth.FetchToken(refreshUUID); err != nil {
+			util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+			return
+		}
+		member, err := router.Config.Persistence.Member.GetMember(userId)
+		if err != nil {
+			util.ResponseError(err, iris.StatusUnauthorized, c)
+			return
+		}
+		if !member.Active {
+			util.ResponseError(util.GetError("member_suspended"), iris.StatusForbidden, c)
+			return
+		}
 		token, err := router.Config.Token.ExtractJWTTokenMetadata(c.Request(), false)
 		if err != nil {
 			util.ResponseError(err, iris.StatusUnauthorized, c)
@@ -0,0 +1,319 @@
+package routers
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/infrastructure/ratelimit"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/olahol/melody"
+)
+
+// BotRouter ...
+type BotRouter struct {
+	Config *config.AppConfig
+	Chat   *ChatRouter
+}
+
+// NewBotRouter ...
+func NewBotRouter(config *config.AppConfig, chat *ChatRouter) *BotRouter {
+	return &BotRouter{
+		Config: config,
+		Chat:   chat,
+	}
+}
+
+// AuthenticatedBot accepts "Authorization: Bot <token>" and lets active bots
+// through. The bot is stored on the context for the handlers.
+func (router *BotRouter) AuthenticatedBot(c iris.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bot ")
+	if !strings.HasPrefix(token, entity.BOT_TOKEN_PREFIX) {
+		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+		return
+	}
+	bot, err := router.Config.Persistence.Bot.GetBotByToken(entity.HashBotToken(token))
+	if err != nil {
+		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+		return
+	}
+	member, err := router.Config.Persistence.Member.GetMember(bot.Member)
+	if err != nil || !member.Active {
+		util.ResponseError(util.GetError("member_suspended"), iris.StatusForbidden, c)
+		return
+	}
+	c.Values().Set(entity.BOT_SESSION_KEY, bot)
+	c.Next()
+}
+
+func botOf(c iris.Context) *entity.Bot {
+	bot, _ := c.Values().Get(entity.BOT_SESSION_KEY).(*entity.Bot)
+	return bot
+}
+
+// GetBotProfile ...
+func (router *BotRouter) GetBotProfile(c iris.Context) {
+	profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(botOf(c).Profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(profile, iris.StatusOK, c)
+}
+
+// SendMessage sends a text message as the bot. Flood control answers with
+// 429 and a Retry-After header.
+func (router *BotRouter) SendMessage(c iris.Context) {
+	var request entity.BotMessage
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	message, verdict, err := router.Chat.SendBotMessage(botOf(c).Profile, &request)
+	if verdict != nil {
+		code := "muted"
+		if verdict.Verdict == ratelimit.FLOOD_SLOW_DOWN {
+			code = "slow_down"
+		}
+		c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(verdict.RetryAfter.Seconds())), 10))
+		util.ResponseErrorCode(util.GetError(code), iris.StatusTooManyRequests, c)
+		return
+	}
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	util.Response(message, iris.StatusCreated, c)
+}
+
+// HandleRequest opens the bot's socket. It receives every message sent to
+// the bot and can send to any profile.
+func (router *BotRouter) HandleRequest(c iris.Context) {
+	router.Config.Socket.HandleRequestWithKeys(router.Config.Melody, c.ResponseWriter(), c.Request(), map[string]interface{}{
+		entity.BOT_SESSION_KEY: botOf(c).Profile,
+	})
+}
+
+// CreateBot creates the bot with its member and profile, and its webhook
+// when a webhook_url is given. The token and the webhook secret are only
+// returned here.
+func (router *BotRouter) CreateBot(c iris.Context) {
+	var request entity.BotRequest
+	var bot entity.Bot
+	var member entity.Member
+	var profile entity.MemberProfile
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	request.WebhookURL = strings.TrimSpace(request.WebhookURL)
+	err = request.ValidateBotRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	token := bot.PrepareBot(admin, &request, &member, &profile)
+	err = profile.ValidateMemberProfile()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	_, err = router.Config.Persistence.Member.CreateMember(&member)
+	if err != nil {
+		util.ResponseError(err, iris.StatusBadRequest, c)
+		return
+	}
+	_, err = router.Config.Persistence.Profile.CreateMemberProfile(&profile)
+	if err != nil {
+		router.Config.Persistence.Member.DeleteMember(member.ID)
+		util.ResponseError(err, iris.StatusBadRequest, c)
+		return
+	}
+
+	var webhook *entity.Webhook
+	if request.WebhookURL != "" {
+		webhook, err = router.createBotWebhook(admin, &bot, request.WebhookURL)
+		if err != nil {
+			util.ResponseError(err, iris.StatusInternalServerError, c)
+			return
+		}
+	}
+	_, err = router.Config.Persistence.Bot.CreateBot(&bot)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_BOT_CREATE, bot.ID, profile.NickName)
+	util.Response(iris.Map{
+		"bot":     bot,
+		"profile": profile,
+		"token":   token,
+		"webhook": webhook,
+	}, iris.StatusCreated, c)
+}
+
+// GetBots ...
+func (router *BotRouter) GetBots(c iris.Context) {
+	bots, err := router.Config.Persistence.Bot.GetBots()
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(bots, iris.StatusOK, c)
+}
+
+// GetBot ...
+func (router *BotRouter) GetBot(c iris.Context) {
+	bot, err := router.Config.Persistence.Bot.GetBot(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(bot.Profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(iris.Map{"bot": bot, "profile": profile}, iris.StatusOK, c)
+}
+
+// UpdateBot changes the bot's display name, nick name and webhook url. An
+// empty webhook_url removes the webhook; a new one is created with a new
+// secret, which is returned.
+func (router *BotRouter) UpdateBot(c iris.Context) {
+	var request entity.BotRequest
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	request.WebhookURL = strings.TrimSpace(request.WebhookURL)
+	err = request.ValidateBotRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	bot, err := router.Config.Persistence.Bot.GetBot(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(bot.Profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	profile.DisplayName = strings.TrimSpace(request.DisplayName)
+	if request.NickName != "" {
+		profile.NickName = strings.ToLower(request.NickName)
+	}
+	err = profile.ValidateMemberProfile()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	profile, err = router.Config.Persistence.Profile.UpdateMemberProfile(profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusBadRequest, c)
+		return
+	}
+
+	var webhook *entity.Webhook
+	if bot.Webhook != "" {
+		webhook, _ = router.Config.Persistence.Webhook.GetWebhook(bot.Webhook)
+	}
+	switch {
+	case webhook != nil && request.WebhookURL == "":
+		err = router.Config.Persistence.Webhook.DeleteWebhook(webhook.ID)
+		bot.Webhook, webhook = "", nil
+	case webhook != nil:
+		webhook.URL = request.WebhookURL
+		webhook.UpdatedAt = util.GetTimeNow()
+		err = router.Config.Persistence.Webhook.UpdateWebhook(webhook)
+		webhook.Secret = ""
+	case request.WebhookURL != "":
+		webhook, err = router.createBotWebhook(admin, bot, request.WebhookURL)
+	}
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	bot.UpdatedAt = util.GetTimeNow()
+	err = router.Config.Persistence.Bot.UpdateBot(bot)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_BOT_UPDATE, bot.ID, request.WebhookURL)
+	util.Response(iris.Map{"bot": bot, "profile": profile, "webhook": webhook}, iris.StatusOK, c)
+}
+
+// RotateBotToken replaces the bot's API token. The old token stops working
+// at once, but sockets already open stay connected.
+func (router *BotRouter) RotateBotToken(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	bot, err := router.Config.Persistence.Bot.GetBot(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	token := bot.NewToken()
+	err = router.Config.Persistence.Bot.UpdateBot(bot)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_BOT_TOKEN, bot.ID, "")
+	util.Response(iris.Map{"bot": bot, "token": token}, iris.StatusOK, c)
+}
+
+// DeleteBot revokes the bot's token, removes its webhook, closes its socket
+// and deactivates its member. The profile is kept so conversations with the
+// bot still render.
+func (router *BotRouter) DeleteBot(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	bot, err := router.Config.Persistence.Bot.GetBot(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	err = router.Config.Persistence.Bot.DeleteBot(bot.ID)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	if bot.Webhook != "" {
+		router.Config.Persistence.Webhook.DeleteWebhook(bot.Webhook)
+	}
+	router.Config.Persistence.Member.SetMemberActive(bot.Member, false)
+	router.Config.CloseProfileSessions(bot.Profile, melody.CloseNormalClosure, "bot deleted")
+	router.Config.Audit(admin, entity.AUDIT_BOT_DELETE, bot.ID, "")
+	c.StatusCode(iris.StatusNoContent)
+}
+
+func (router *BotRouter) createBotWebhook(admin string, bot *entity.Bot, link string) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	webhook.PrepareBotWebhook(admin, bot, link)
+	newWebhook, err := router.Config.Persistence.Webhook.CreateWebhook(&webhook)
+	if err != nil {
+		return nil, err
+	}
+	bot.Webhook = newWebhook.ID
+	return newWebhook, nil
+}
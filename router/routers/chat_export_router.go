@@ -0,0 +1,290 @@
+package routers
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/chatexport"
+
+	"github.com/kataras/iris/v12"
+)
+
+const (
+	// CHAT_EXPORT_WORKERS is how many exports are written at once. Others
+	// wait for a slot.
+	CHAT_EXPORT_WORKERS = 4
+	// CHAT_EXPORT_QUEUE is how many exports a replica holds, written or
+	// waiting. New ones are turned away while it is full.
+	CHAT_EXPORT_QUEUE = 64
+	// CHAT_EXPORT_INTERVAL is how often claims are renewed, expired exports
+	// removed and abandoned ones queued again.
+	CHAT_EXPORT_INTERVAL = time.Minute
+)
+
+// ChatExportRouter ...
+type ChatExportRouter struct {
+	Config *config.AppConfig
+	Slots  chan struct{}
+	Queue  chan struct{}
+
+	sync.Mutex
+	// Running holds the ids of the exports this replica has queued, whose
+	// claims it renews.
+	Running map[string]bool
+}
+
+// NewChatExportRouter ...
+func NewChatExportRouter(config *config.AppConfig) *ChatExportRouter {
+	return &ChatExportRouter{
+		Config:  config,
+		Slots:   make(chan struct{}, CHAT_EXPORT_WORKERS),
+		Queue:   make(chan struct{}, CHAT_EXPORT_QUEUE),
+		Running: make(map[string]bool),
+	}
+}
+
+// CreateChatExport ...
+func (router *ChatExportRouter) CreateChatExport(c iris.Context) {
+	var data struct {
+		Format string `json:"format"`
+	}
+	var export entity.ChatExport
+
+	err := c.ReadJSON(&data)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	export.PrepareChatExport(profile, c.Params().Get("receiver"), data.Format)
+	err = export.ValidateChatExport()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	if !router.reserve() {
+		util.ResponseError(util.GetError("export_busy"), iris.StatusServiceUnavailable, c)
+		return
+	}
+	newExport, err := router.Config.Persistence.ChatExport.CreateChatExport(&export)
+	if err != nil {
+		<-router.Queue
+		if err.Error() == "export_pending" {
+			util.ResponseError(err, iris.StatusConflict, c)
+			return
+		}
+		util.ResponseError(err, iris.StatusBadRequest, c)
+		return
+	}
+
+	router.queue(*newExport)
+
+	util.Response(newExport, iris.StatusAccepted, c)
+}
+
+// reserve takes a place in the queue, if there is one left.
+func (router *ChatExportRouter) reserve() bool {
+	select {
+	case router.Queue <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// queue writes export in the background, on a place reserved in the queue.
+func (router *ChatExportRouter) queue(export entity.ChatExport) {
+	router.Lock()
+	router.Running[export.ID] = true
+	router.Unlock()
+
+	go func() {
+		defer func() {
+			router.Lock()
+			delete(router.Running, export.ID)
+			router.Unlock()
+			<-router.Queue
+		}()
+		router.GenerateChatExport(export)
+	}()
+}
+
+// GenerateChatExport writes the export file and marks the export as ready,
+// until it expires, or failed. It waits while CHAT_EXPORT_WORKERS exports are
+// being written.
+func (router *ChatExportRouter) GenerateChatExport(export entity.ChatExport) {
+	router.Slots <- struct{}{}
+	defer func() { <-router.Slots }()
+
+	export.File = export.FilePath()
+	err := router.writeChatExport(&export)
+	if err != nil {
+		router.Config.Log.Errorf("chat export %s: %+v", export.ID, err)
+		os.Remove(export.File)
+		export.Status = entity.EXPORT_FAILED
+		export.File = ""
+	}
+
+	completedAt := util.GetTimeNow()
+	export.CompletedAt = &completedAt
+	if err == nil {
+		export.Status = entity.EXPORT_READY
+		expiresAt := completedAt.Add(entity.ExportTTL())
+		export.ExpiresAt = &expiresAt
+	}
+	err = router.Config.Persistence.ChatExport.UpdateChatExport(&export)
+	if err != nil {
+		router.Config.Log.Errorf("chat export %s: %+v", export.ID, err)
+	}
+}
+
+// MaintainChatExports runs every CHAT_EXPORT_INTERVAL until ctx is done. It
+// renews the claims on the exports this replica holds, removes the files of
+// expired exports and queues again the exports left pending by a replica
+// that stopped, or by this one before a restart, once their claim runs out.
+func (router *ChatExportRouter) MaintainChatExports(ctx context.Context) {
+	tick := time.NewTicker(CHAT_EXPORT_INTERVAL)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			now := util.GetTimeNow()
+			router.renewChatExports(now)
+			router.expireChatExports(now)
+			router.resumeChatExports(now)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (router *ChatExportRouter) renewChatExports(now time.Time) {
+	router.Lock()
+	ids := make([]string, 0, len(router.Running))
+	for ID := range router.Running {
+		ids = append(ids, ID)
+	}
+	router.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+	if err := router.Config.Persistence.ChatExport.RenewChatExports(ids, now.Add(entity.EXPORT_LEASE)); err != nil {
+		router.Config.Log.Errorf("renewing chat exports: %+v", err)
+	}
+}
+
+func (router *ChatExportRouter) expireChatExports(now time.Time) {
+	exports, err := router.Config.Persistence.ChatExport.GetExpiredChatExports(now)
+	if err != nil {
+		router.Config.Log.Errorf("listing expired chat exports: %+v", err)
+		return
+	}
+	for _, export := range exports {
+		if err := os.Remove(export.File); err != nil && !os.IsNotExist(err) {
+			router.Config.Log.Errorf("removing chat export %s: %+v", export.ID, err)
+			continue
+		}
+		if err := router.Config.Persistence.ChatExport.ExpireChatExport(export.ID); err != nil {
+			router.Config.Log.Errorf("expiring chat export %s: %+v", export.ID, err)
+		}
+	}
+}
+
+func (router *ChatExportRouter) resumeChatExports(now time.Time) {
+	exports, err := router.Config.Persistence.ChatExport.GetAbandonedChatExports(now)
+	if err != nil {
+		router.Config.Log.Errorf("listing abandoned chat exports: %+v", err)
+		return
+	}
+	for _, abandoned := range exports {
+		if !router.reserve() {
+			return
+		}
+		export, err := router.Config.Persistence.ChatExport.ClaimChatExport(abandoned.ID, now)
+		if err != nil {
+			<-router.Queue
+			continue
+		}
+		router.queue(*export)
+	}
+}
+
+func (router *ChatExportRouter) writeChatExport(export *entity.ChatExport) error {
+	var profiles entity.MemberProfiles
+	for _, id := range []string{export.Profile, export.Receiver} {
+		profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(id)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	file, err := os.Create(export.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffer := bufio.NewWriter(file)
+	writer, err := chatexport.NewWriter(export.Format, buffer)
+	if err != nil {
+		return err
+	}
+	if err = writer.WriteHeader(export, profiles); err != nil {
+		return err
+	}
+	export.Messages, err = router.Config.Persistence.Chat.IterateChatHistory(export.ChatId, writer.WriteMessage)
+	if err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+// GetChatExport ...
+func (router *ChatExportRouter) GetChatExport(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	export, err := router.Config.Persistence.ChatExport.GetChatExport(c.Params().Get("id"), profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	export.SetDownloadURL()
+	util.Response(export, iris.StatusOK, c)
+}
+
+// DownloadChatExport ...
+func (router *ChatExportRouter) DownloadChatExport(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	export, err := router.Config.Persistence.ChatExport.GetChatExport(c.Params().Get("id"), profile)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if export.Status == entity.EXPORT_EXPIRED {
+		util.ResponseError(util.GetError("export_expired"), iris.StatusGone, c)
+		return
+	}
+	if export.Status != entity.EXPORT_READY {
+		util.ResponseError(util.GetError("export_not_ready"), iris.StatusConflict, c)
+		return
+	}
+
+	c.ContentType(entity.ExportContentType[export.Format])
+	err = c.SendFile(export.File, "chat-"+export.ID+entity.ExportFileExtension[export.Format])
+	if err != nil {
+		util.ResponseError(util.GetError("export_not_found"), iris.StatusNotFound, c)
+	}
+}
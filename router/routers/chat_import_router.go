@@ -0,0 +1,84 @@
+package routers
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/chatimport"
+
+	"github.com/kataras/iris/v12"
+)
+
+// ChatImportRouter ...
+type ChatImportRouter struct {
+	Config   *config.AppConfig
+	Importer *chatimport.Importer
+}
+
+// NewChatImportRouter ...
+func NewChatImportRouter(config *config.AppConfig) *ChatImportRouter {
+	return &ChatImportRouter{
+		Config:   config,
+		Importer: chatimport.NewImporter(config.Persistence.Chat, config.Moderation, config.Persistence.Moderation),
+	}
+}
+
+// ImportChat loads an export into the conversation of owner and receiver.
+// The messages it writes speak for both profiles, so only staff holding
+// chat.import run it, on a request both members made.
+func (router *ChatImportRouter) ImportChat(c iris.Context) {
+	owner, err := router.Config.Persistence.Profile.GetMemberProfileByID(c.Params().Get("owner"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	receiver, err := router.Config.Persistence.Profile.GetMemberProfileByID(c.Params().Get("receiver"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if receiver.ID == owner.ID {
+		util.ResponseError(util.GetError("import_participants_unmapped"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	file, fileHeader, err := c.FormFile("file")
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	defer file.Close()
+
+	if fileHeader.Size > 20<<20 {
+		util.ResponseError(util.GetError("import_size_error"), iris.StatusRequestEntityTooLarge, c)
+		return
+	}
+
+	timeZone := c.FormValueDefault("time_zone", os.Getenv("TIME_ZONE"))
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		util.ResponseError(util.GetError("invalid_time_zone"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	result, err := router.Importer.Import(c.FormValue("source"), file, chatimport.Participants{
+		Owner:        owner.ID,
+		OwnerName:    c.FormValue("owner_name"),
+		Receiver:     receiver.ID,
+		ReceiverName: c.FormValue("receiver_name"),
+	}, location)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	staff := auth.ExtractTokenClaims(c.Request(), "user_id")
+	note := fmt.Sprintf("with %s: %d imported, %d rejected", receiver.ID, result.Imported, result.Rejected)
+	router.Config.Audit(staff, entity.AUDIT_CHAT_IMPORT, owner.ID, note)
+	util.Response(result, iris.StatusOK, c)
+}
@@ -2,13 +2,20 @@ package routers
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kataras/iris/v12"
 	"github.com/majid-cj/go-chat-server/config"
 	"github.com/majid-cj/go-chat-server/domain/entity"
 	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/infrastructure/dedup"
+	"github.com/majid-cj/go-chat-server/infrastructure/ratelimit"
 	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/command"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
 	"github.com/olahol/melody"
 )
 
@@ -24,34 +31,187 @@ func NewChatRouter(config *config.AppConfig) *ChatRouter {
 	}
 }
 
-// SaveChatMessage ...
-func (router *ChatRouter) SaveChatMessage(message *entity.ChatMessage, sender string, receiver string, isRead bool) {
+func (router *ChatRouter) ReadChatMessage(sender, receiver string) {
 	defer router.Config.Wg.Done()
-	var room entity.ChatRoom
-	room.ID = util.ULID()
-	room.Sender = sender
-	room.Receiver = []string{receiver}
-	room.CreatedAt = util.GetTimeNow()
-	room.Message = message.Message
-	room.IsRead = isRead
-	router.Config.Persistence.Chat.AddNewChatMessage(message)
-	router.Config.Persistence.Chat.AddChatRoom(&room)
+	if router.Config.Persistence.Chat.ReadChatMessage(sender, receiver) == nil {
+		router.ChatListChanged(sender, receiver)
+	}
 }
 
-func (router *ChatRouter) ReadChatMessage(sender, receiver string) {
-	defer router.Config.Wg.Done()
-	router.Config.Persistence.Chat.ReadChatMessage(sender, receiver)
+// ChatListChanged tells profile's chat list subscribers that the
+// conversation with other changed.
+func (router *ChatRouter) ChatListChanged(profile, other string) {
+	router.Config.Events.Publish(entity.ChatListTopic(profile), entity.ConversationKey(profile, other))
+}
+
+// AttachLinkPreview fetches the preview for link in the background, stores it
+// on the message and pushes it to whichever side is connected.
+func (router *ChatRouter) AttachLinkPreview(ref, link string, chatIds ...string) {
+	preview, err := router.Config.Preview.Fetch(router.Config.AppContext, link)
+	if err != nil {
+		router.Config.Log.Debugf("link preview %s: %+v", link, err)
+		return
+	}
+
+	err = router.Config.Persistence.Chat.SetChatMessagePreview(ref, preview)
+	if err != nil {
+		router.Config.Log.Errorf("saving link preview %s: %+v", ref, err)
+		return
+	}
+
+	sent := entity.NewChatEvent(entity.EVENT_MESSAGE_PREVIEW, entity.ChatMessagePreview{
+		Ref:     ref,
+		Preview: preview,
+	})
+	for _, chatId := range chatIds {
+		router.Config.Send(chatId, sent)
+	}
+}
+
+// TranslateMessage returns message's text in lang, from the cache when it
+// was translated before.
+func (router *ChatRouter) TranslateMessage(message *entity.ChatMessage, lang string) (*entity.MessageTranslation, error) {
+	ref := entity.MessageRef(message)
+	if translation, err := router.Config.Persistence.Translation.GetTranslation(ref, lang); err == nil {
+		return translation, nil
+	}
+
+	result, err := router.Config.Translator.Translate(router.Config.AppContext, message.Message, lang)
+	if err != nil {
+		return nil, err
+	}
+	var translation entity.MessageTranslation
+	translation.PrepareMessageTranslation(ref, lang, result.Source, result.Text)
+	if err = router.Config.Persistence.Translation.AddTranslation(&translation); err != nil {
+		router.Config.Log.Errorf("caching translation %s: %+v", ref, err)
+	}
+	return &translation, nil
+}
+
+// AutoTranslate translates a message the receiver just got when their side
+// of the chat has auto-translate on. The translation is stored on the message
+// for the receiver only and pushed to the receiver when connected.
+func (router *ChatRouter) AutoTranslate(message entity.ChatMessage, chatId string) {
+	room, err := router.Config.Persistence.Chat.GetChatRoom(message.Receiver, message.Sender)
+	if err != nil || room.TranslateTo == "" || strings.TrimSpace(message.Message) == "" {
+		return
+	}
+
+	translation, err := router.TranslateMessage(&message, room.TranslateTo)
+	if err != nil {
+		router.Config.Log.Debugf("auto-translate %s: %+v", message.Ref, err)
+		return
+	}
+	if translation.Unchanged() {
+		return
+	}
+	err = router.Config.Persistence.Chat.SetChatMessageTranslation(message.ID, message.Receiver, translation)
+	if err != nil {
+		router.Config.Log.Errorf("saving translation %s: %+v", message.Ref, err)
+		return
+	}
+	router.Config.Send(chatId, entity.NewChatEvent(entity.EVENT_MESSAGE_TRANSLATED, translation))
+}
+
+// GetMessageTranslation translates one of the member's messages to the lang
+// query parameter, or to the Accept-Language of the request.
+func (router *ChatRouter) GetMessageTranslation(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	lang, ok := router.matchLanguage(c.URLParamDefault("lang", util.AcceptLanguage(c.Request())))
+	if !ok {
+		util.ResponseError(util.GetError("invalid_language"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if strings.TrimSpace(message.Message) == "" {
+		util.ResponseError(util.GetError("nothing_to_translate"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	translation, err := router.TranslateMessage(message, lang)
+	if err != nil {
+		util.ResponseError(err, iris.StatusServiceUnavailable, c)
+		return
+	}
+	util.Response(translation, iris.StatusOK, c)
+}
+
+// SetAutoTranslate turns auto-translate on or off for the member's side of
+// the chat with receiver.
+func (router *ChatRouter) SetAutoTranslate(c iris.Context) {
+	var request entity.AutoTranslateRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	if request.Lang != "" {
+		lang, ok := router.matchLanguage(request.Lang)
+		if !ok {
+			util.ResponseError(util.GetError("invalid_language"), iris.StatusUnprocessableEntity, c)
+			return
+		}
+		request.Lang = lang
+	}
+
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver := c.Params().Get("receiver")
+	err = router.Config.Persistence.Chat.SetChatAutoTranslate(profile, receiver, request.Lang)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	router.ChatListChanged(profile, receiver)
+	util.Response(iris.Map{"receiver": receiver, "translate_to": request.Lang}, iris.StatusOK, c)
+}
+
+// matchLanguage maps lang to one of the languages the server has locales
+// for, which are the languages messages can be translated to.
+func (router *ChatRouter) matchLanguage(lang string) (string, bool) {
+	tag, _, ok := router.Config.App.I18n.TryMatchString(lang)
+	if !ok {
+		return "", false
+	}
+	base, _ := tag.Base()
+	return base.String(), true
 }
 
 // HandleRequest ...
 func (router *ChatRouter) HandleRequest(c iris.Context) {
-	if auth.URLTokenValid(c.Request()) {
-		router.Config.Melody.HandleRequest(c.ResponseWriter(), c.Request())
+	if !auth.URLTokenValid(c.Request()) {
+		return
 	}
+	if _, err := router.Config.Auth.Auth.FetchToken(auth.ExtractURLTokenClaims(c.Request(), "access_uuid")); err != nil {
+		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+		return
+	}
+	// The sender is read from the URL for the life of the socket, so it has to
+	// be the profile the token was issued for.
+	ids := util.GetURLIds(c.Request().URL.Path)
+	if len(ids) < 2 || ids[0] != auth.ExtractURLTokenClaims(c.Request(), "profile_id") {
+		util.ResponseError(util.GetError("forbidden_access"), iris.StatusForbidden, c)
+		return
+	}
+	member, err := router.Config.Persistence.Member.GetMember(auth.ExtractURLTokenClaims(c.Request(), "user_id"))
+	if err != nil || !member.Active {
+		util.ResponseError(util.GetError("member_suspended"), iris.StatusForbidden, c)
+		return
+	}
+	router.Config.Socket.HandleRequest(router.Config.Melody, c.ResponseWriter(), c.Request())
 }
 
 // HandleConnect ...
 func (router *ChatRouter) HandleConnect(s *melody.Session) {
+	if _, ok := s.Get(entity.BOT_SESSION_KEY); ok {
+		router.Config.Socket.Attach(s)
+		router.Config.Set(sessionKey(s), s)
+		return
+	}
+
 	URL := s.Request.URL.Path
 	chatId := util.GetChatId(URL, false)
 	sender := util.GetURLIds(URL)[0]
@@ -61,66 +221,652 @@ func (router *ChatRouter) HandleConnect(s *melody.Session) {
 	go router.ReadChatMessage(sender, receiver)
 	router.Config.Wg.Wait()
 
+	router.Config.Socket.Attach(s)
 	router.Config.Set(chatId, s)
-	history, _ := router.Config.Persistence.Chat.GetChatHistory(chatId)
-	sent, _ := json.Marshal(history)
-	s.Write(sent)
+	var history entity.ChatMessageHistory
+	if afterSeq, err := strconv.ParseInt(s.Request.URL.Query().Get("after_seq"), 10, 64); err == nil && afterSeq > 0 {
+		history, _ = router.Config.Persistence.Chat.GetChatMessagesBySeq(chatId, afterSeq, 0, 0)
+	} else {
+		history, _ = router.Config.Persistence.Chat.GetChatHistory(chatId)
+	}
+	router.MarkPinsAndStars(history, sender, receiver)
+	router.Config.Socket.Write(s, history)
+}
+
+// MarkPinsAndStars flags the messages of sender's chat with receiver that
+// are pinned in the conversation or starred by sender.
+func (router *ChatRouter) MarkPinsAndStars(history entity.ChatMessageHistory, sender, receiver string) {
+	pinned := make(map[string]bool)
+	if pins, err := router.Config.Persistence.Pin.GetPins(entity.ConversationKey(sender, receiver)); err == nil {
+		for _, pin := range pins {
+			pinned[pin.Ref] = true
+		}
+	}
+	starred := make(map[string]bool)
+	if stars, err := router.Config.Persistence.Star.GetChatStars(sender, fmt.Sprintf("%s-%s", sender, receiver)); err == nil {
+		for _, star := range stars {
+			starred[star.Ref] = true
+		}
+	}
+
+	for index := range history {
+		ref := entity.MessageRef(&history[index])
+		history[index].Pinned = pinned[ref]
+		history[index].Starred = starred[ref]
+	}
 }
 
 // HandleDisconnect ...
 func (router *ChatRouter) HandleDisconnect(s *melody.Session) {
-	chatId := util.GetChatId(s.Request.URL.Path, false)
-	router.Config.CloseSession(chatId)
+	router.Config.CloseSession(sessionKey(s), s)
+	router.Config.Socket.Detach(s)
 }
 
 // HandleClose ...
 func (router *ChatRouter) HandleClose(s *melody.Session, code int, reason string) error {
 	if code == 69 {
-		chatId := util.GetChatId(s.Request.URL.Path, false)
-		router.Config.CloseSession(chatId)
+		router.Config.CloseSession(sessionKey(s), s)
 		return nil
 	}
 	return util.GetError("general_error")
 }
 
+// sessionKey is the AppConfig session key of s: the chat id for a member's
+// conversation socket, or the bot key for a bot socket.
+func sessionKey(s *melody.Session) string {
+	if profile, ok := s.Get(entity.BOT_SESSION_KEY); ok {
+		return entity.BotSessionKey(profile.(string))
+	}
+	return util.GetChatId(s.Request.URL.Path, false)
+}
+
 // HandleMessage ...
 func (router *ChatRouter) HandleMessage(s *melody.Session, msg []byte) {
+	if profile, ok := s.Get(entity.BOT_SESSION_KEY); ok {
+		router.HandleBotMessage(s, profile.(string), msg)
+		return
+	}
+
+	ids := util.GetURLIds(s.Request.URL.Path)
+	if !router.AllowMessage(s, ids[0], util.GetChatId(s.Request.URL.Path, false)) {
+		return
+	}
+
 	var message *entity.ChatMessage
-	err := json.Unmarshal(msg, &message)
+	err := router.Config.Socket.Decode(s, msg, &message)
+	if err != nil || message == nil {
+		return
+	}
+	message.Type = entity.MESSAGE_TEXT
+	message.Sender = ids[0]
+	message.Receiver = ids[1]
+	message.Attachment = nil
+	message.Preview = nil
+	message.PlayedAt = nil
+	message.Poll = nil
+
+	if command.IsCommand(message.Message) {
+		router.RunCommand(s, message)
+		return
+	}
+	message.Message = command.Unescape(message.Message)
+
+	clientMsgId := message.ClientMsgId
+	if !router.ClaimClientMsgId(s, message) {
+		return
+	}
+	err = router.DeliverChatMessage(message)
 	if err != nil {
+		if clientMsgId != "" {
+			router.Config.Dedup.Release(message.Sender, clientMsgId)
+		}
+		router.SendError(s, fmt.Sprintf("%+v", err), 0)
 		return
 	}
-	URL := s.Request.URL.Path
-	senderChat := util.GetChatId(URL, false)
-	receiverChat := util.GetChatId(URL, true)
+	router.AckMessage(s, clientMsgId, message)
+}
+
+// ClaimClientMsgId makes sure a message with a client_msg_id is delivered
+// once. A retry of a delivered message is acked again instead, and a retry
+// of one still being delivered is dropped. Messages go through if redis is
+// down.
+func (router *ChatRouter) ClaimClientMsgId(s *melody.Session, message *entity.ChatMessage) bool {
+	if message.ClientMsgId == "" {
+		return true
+	}
+	if err := message.ValidateClientMsgId(); err != nil {
+		router.SendError(s, fmt.Sprintf("%+v", err), 0)
+		return false
+	}
+	claim, err := router.Config.Dedup.Claim(message.Sender, message.ClientMsgId)
+	if err != nil {
+		router.Config.Log.Errorf("message dedup %s: %+v", message.Sender, err)
+		return true
+	}
+
+	switch claim.State {
+	case dedup.CLAIM_NEW:
+		return true
+	case dedup.CLAIM_DONE:
+		router.Config.Socket.Write(s, entity.NewChatEvent(entity.EVENT_MESSAGE_ACK, entity.ChatMessageAck{
+			ClientMsgId: message.ClientMsgId,
+			ID:          claim.ID,
+			Seq:         claim.Seq,
+			CreatedAt:   claim.CreatedAt,
+			Duplicate:   true,
+		}))
+	}
+	return false
+}
+
+// AckMessage remembers the id and seq a client message was stored under and
+// tells the sender. Messages without a client_msg_id are not acked.
+func (router *ChatRouter) AckMessage(s *melody.Session, clientMsgId string, message *entity.ChatMessage) {
+	if clientMsgId == "" {
+		return
+	}
+	err := router.Config.Dedup.Complete(message.Sender, clientMsgId, message.Ref, message.Seq, message.CreatedAt)
+	if err != nil {
+		router.Config.Log.Errorf("message dedup %s: %+v", message.Sender, err)
+	}
+	router.Config.Socket.Write(s, entity.NewChatEvent(entity.EVENT_MESSAGE_ACK, entity.ChatMessageAck{
+		ClientMsgId: clientMsgId,
+		ID:          message.Ref,
+		Seq:         message.Seq,
+		CreatedAt:   message.CreatedAt,
+	}))
+}
+
+// RunCommand runs a slash command typed into a conversation instead of
+// storing it. Ephemeral replies only go to s; public ones are delivered as
+// the sender's message.
+func (router *ChatRouter) RunCommand(s *melody.Session, message *entity.ChatMessage) {
+	reply, err := router.Config.Commands.Run(message.Sender, message.Receiver, message.Message)
+	if err != nil {
+		router.SendError(s, fmt.Sprintf("%+v", err), 0)
+		return
+	}
+	if reply == nil {
+		return
+	}
+
+	if reply.Visibility == command.REPLY_PUBLIC && reply.Poll != nil {
+		_, err = router.SendPoll(message.Sender, message.Receiver, reply.Poll)
+		if err != nil {
+			router.SendError(s, fmt.Sprintf("%+v", err), 0)
+		}
+		return
+	}
+	if reply.Visibility == command.REPLY_PUBLIC {
+		message.Message = reply.Text
+		err = router.DeliverChatMessage(message)
+		if err != nil {
+			router.SendError(s, fmt.Sprintf("%+v", err), 0)
+		}
+		return
+	}
+	router.Config.Socket.Write(s, entity.NewChatEvent(entity.EVENT_COMMAND_REPLY, entity.CommandReply{
+		Command: reply.Command,
+		Text:    reply.Text,
+	}))
+}
+
+// HandleBotMessage delivers a frame received on a bot socket. Unlike a
+// conversation socket, each frame names its receiver.
+func (router *ChatRouter) HandleBotMessage(s *melody.Session, bot string, msg []byte) {
+	var request entity.BotMessage
+	err := router.Config.Socket.Decode(s, msg, &request)
+	if err != nil {
+		router.SendError(s, "error_parsing_data", 0)
+		return
+	}
+	_, verdict, err := router.SendBotMessage(bot, &request)
+	if verdict != nil {
+		router.RejectFlood(s, verdict)
+		return
+	}
+	if err != nil {
+		router.SendError(s, fmt.Sprintf("%+v", err), 0)
+	}
+}
+
+// SendBotMessage delivers a text message from the bot profile bot. It
+// returns the flood control verdict, without sending, when the message is
+// rejected.
+func (router *ChatRouter) SendBotMessage(bot string, request *entity.BotMessage) (*entity.ChatMessage, *ratelimit.FloodVerdict, error) {
+	receiver, err := router.Config.Persistence.Profile.GetMemberProfileByID(request.Receiver)
+	if err != nil || receiver.ID == bot {
+		return nil, nil, util.GetError("profile_not_found")
+	}
+	if verdict := router.CheckFlood(bot, fmt.Sprintf("%s-%s", bot, receiver.ID)); verdict != nil {
+		return nil, verdict, nil
+	}
+
+	message := entity.ChatMessage{
+		Type:     entity.MESSAGE_TEXT,
+		Sender:   bot,
+		Receiver: receiver.ID,
+		Message:  request.Message,
+	}
+	err = router.DeliverChatMessage(&message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &message, nil, nil
+}
+
+// ForwardToBot hands a message sent to a bot to the bot's socket, or to its
+// webhook when the socket is not connected. Messages to members are ignored.
+func (router *ChatRouter) ForwardToBot(message entity.ChatMessage) {
+	bot, err := router.Config.Persistence.Bot.GetBotByProfile(message.Receiver)
+	if err != nil {
+		return
+	}
+	if router.Config.Send(entity.BotSessionKey(bot.Profile), entity.ChatMessageHistory{message}) || bot.Webhook == "" {
+		return
+	}
+	err = router.Config.Webhooks.PublishTo(bot.Webhook, entity.WEBHOOK_BOT_MESSAGE, message)
+	if err != nil {
+		router.Config.Log.Errorf("forwarding %s to bot %s: %+v", message.Ref, bot.ID, err)
+	}
+}
+
+// AllowMessage runs flood control on a frame received from sender. Rejected
+// frames are answered with an error event, and a sender that keeps going
+// while muted is disconnected. Frames are let through if redis is down.
+func (router *ChatRouter) AllowMessage(s *melody.Session, sender, chatId string) bool {
+	verdict := router.CheckFlood(sender, chatId)
+	if verdict == nil {
+		return true
+	}
+	router.RejectFlood(s, verdict)
+	return false
+}
+
+// CheckFlood runs flood control on a message sender sends in chatId and
+// returns the verdict when the message is rejected. Messages go through if
+// redis is down.
+func (router *ChatRouter) CheckFlood(sender, chatId string) *ratelimit.FloodVerdict {
+	verdict, err := router.Config.Flood.Check(sender, chatId)
+	if err != nil {
+		router.Config.Log.Errorf("flood control %s: %+v", sender, err)
+		return nil
+	}
+	if verdict.Verdict == ratelimit.FLOOD_ALLOW {
+		return nil
+	}
+	return verdict
+}
+
+// RejectFlood answers a frame flood control rejected with an error event, or
+// disconnects a sender that keeps going while muted.
+func (router *ChatRouter) RejectFlood(s *melody.Session, verdict *ratelimit.FloodVerdict) {
+	switch verdict.Verdict {
+	case ratelimit.FLOOD_SLOW_DOWN:
+		router.SendError(s, "slow_down", verdict.RetryAfter)
+	case ratelimit.FLOOD_MUTED:
+		router.SendError(s, "muted", verdict.RetryAfter)
+	case ratelimit.FLOOD_DISCONNECT:
+		router.Config.Socket.Close(s, melody.ClosePolicyViolation, "message flood")
+	}
+}
+
+// SendError pushes an error event to s, translated for the language the
+// client connected with.
+func (router *ChatRouter) SendError(s *melody.Session, code string, retryAfter time.Duration) {
+	router.Config.Socket.Write(s, entity.NewChatEvent(entity.EVENT_ERROR, entity.ChatError{
+		Code:       code,
+		Message:    router.Config.App.I18n.Tr(util.AcceptLanguage(s.Request), code),
+		RetryAfter: retryAfter.Milliseconds(),
+	}))
+}
+
+// DeliverChatMessage runs message through moderation, stores it once and
+// pushes it to each side's session when it is connected, as that side sees
+// it. Flagged messages are delivered and queued for review. Webhooks get
+// message.created, and message.read when the receiver is connected. Messages
+// to a bot are forwarded to it, and text messages are translated for a
+// receiver who turned auto-translate on. message is left as its sender sees
+// it.
+func (router *ChatRouter) DeliverChatMessage(message *entity.ChatMessage) error {
+	decision := router.Config.Moderation.Run(message)
+	if decision.Rejected() {
+		return util.GetError("message_rejected")
+	}
+
+	senderChat := fmt.Sprintf("%s-%s", message.Sender, message.Receiver)
+	receiverChat := fmt.Sprintf("%s-%s", message.Receiver, message.Sender)
 	message.PrepareChatMessage()
-	message.ChatId = senderChat
 
-	router.Config.Wg.Add(1)
-	go router.SaveChatMessage(message, message.Sender, message.Receiver, true)
-	router.Config.Wg.Wait()
+	if message.Poll != nil {
+		message.Poll.Ref = message.Ref
+		message.Poll.Question = message.Message
+		if err := router.Config.Persistence.Poll.CreatePoll(message.Poll); err != nil {
+			return err
+		}
+	}
 
+	if decision.Flagged() {
+		var review entity.ModerationReview
+		review.PrepareModerationReview(message, decision.Original, decision.Reasons)
+		if err := router.Config.Persistence.Moderation.AddModerationReview(&review); err != nil {
+			router.Config.Log.Errorf("queueing review for %s: %+v", message.Ref, err)
+		}
+	}
+
+	receiver := router.Config.Get(receiverChat)
+	err := router.Config.Persistence.Chat.AddChatMessage(message, receiver != nil)
+	if err != nil {
+		return err
+	}
+
+	message.ViewFor(message.Sender)
 	if sender := router.Config.Get(senderChat); sender != nil {
-		sent, _ := json.Marshal(entity.ChatMessageHistory{*message})
-		sender.Write(sent)
+		router.Config.Socket.Write(sender, entity.ChatMessageHistory{*message})
 	}
+	router.Config.Events.Publish(entity.MessageTopic(message.Sender), *message)
+	router.Config.Events.Publish(entity.MESSAGES_TOPIC, *message)
+	router.ChatListChanged(message.Sender, message.Receiver)
+	go router.Config.SendNotifications(entity.WEBHOOK_MESSAGE_CREATED, *message)
 
-	message.PrepareChatMessage()
-	message.ChatId = receiverChat
+	received := *message
+	received.ViewFor(message.Receiver)
+	received.ClientMsgId = ""
+	if message.Receiver != message.Sender {
+		router.Config.Events.Publish(entity.MessageTopic(message.Receiver), received)
+		router.ChatListChanged(message.Receiver, message.Sender)
+	}
+	if receiver != nil {
+		router.Config.Socket.Write(receiver, entity.ChatMessageHistory{received})
+		go router.Config.SendNotifications(entity.WEBHOOK_MESSAGE_READ, entity.ChatMessageRead{
+			Reader: message.Receiver,
+			Ref:    message.Ref,
+			Seq:    message.Seq,
+			ReadAt: util.GetTimeNow(),
+		})
+	}
+	go router.ForwardToBot(received)
+	if message.Type == entity.MESSAGE_TEXT {
+		go router.AutoTranslate(received, receiverChat)
+	}
 
-	router.Config.Wg.Add(1)
-	go router.SaveChatMessage(message, message.Receiver, message.Sender, false)
-	router.Config.Wg.Wait()
+	if link := linkpreview.FindURL(message.Message); link != "" {
+		go router.AttachLinkPreview(message.Ref, link, senderChat, receiverChat)
+	}
+	return nil
+}
 
-	if receiver := router.Config.Get(receiverChat); receiver != nil {
+// SendPoll delivers a poll message from sender. The options go through
+// moderation as well as the question.
+func (router *ChatRouter) SendPoll(sender, receiver string, request *entity.PollRequest) (*entity.ChatMessage, error) {
+	err := request.ValidatePollRequest()
+	if err != nil {
+		return nil, err
+	}
+	for index, option := range request.Options {
+		checked := entity.ChatMessage{Type: entity.MESSAGE_TEXT, Sender: sender, Receiver: receiver, Message: option}
+		if router.Config.Moderation.Run(&checked).Rejected() {
+			return nil, util.GetError("message_rejected")
+		}
+		request.Options[index] = checked.Message
+	}
+
+	var poll entity.Poll
+	var message entity.ChatMessage
+	poll.PreparePoll(sender, receiver, request)
+	message.PreparePollMessage(sender, receiver, &poll)
+	err = router.DeliverChatMessage(&message)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// PublishPoll copies poll's tallies onto its message and pushes them to both
+// sides of the chat.
+func (router *ChatRouter) PublishPoll(poll *entity.Poll) error {
+	err := router.Config.Persistence.Poll.UpdatePollResults(poll)
+	if err != nil {
+		return err
+	}
+	err = router.Config.Persistence.Chat.SetChatMessagePoll(poll.Ref, poll)
+	if err != nil {
+		return err
+	}
 
-		router.Config.Wg.Add(1)
-		go router.ReadChatMessage(message.Receiver, message.Sender)
-		router.Config.Wg.Wait()
+	sent := entity.NewChatEvent(entity.EVENT_POLL_UPDATED, poll)
+	sender, receiver := poll.Participants[0], poll.Participants[1]
+	router.Config.Send(fmt.Sprintf("%s-%s", sender, receiver), sent)
+	router.Config.Send(fmt.Sprintf("%s-%s", receiver, sender), sent)
+	return nil
+}
+
+// SendVoiceMessage ...
+func (router *ChatRouter) SendVoiceMessage(c iris.Context) {
+	var message entity.ChatMessage
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver, err := router.Config.Persistence.Profile.GetMemberProfileByID(c.Params().Get("receiver"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	file, fileHeader, err := c.FormFile("audio")
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	defer file.Close()
+
+	fileURL, info, err := router.Config.Upload.UploadAudio(fileHeader, file, "voice")
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	message.PrepareVoiceMessage(profile, receiver.ID, &entity.Attachment{
+		URL:      fileURL,
+		MimeType: info.MimeType,
+		Size:     fileHeader.Size,
+		Duration: info.Duration.Milliseconds(),
+		Waveform: info.Waveform,
+	})
+	err = router.DeliverChatMessage(&message)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	util.Response(message, iris.StatusCreated, c)
+}
+
+// PlayVoiceMessage ...
+func (router *ChatRouter) PlayVoiceMessage(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.SetChatMessagePlayed(c.Params().Get("ref"), profile, util.GetTimeNow())
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	sent := entity.NewChatEvent(entity.EVENT_MESSAGE_PLAYED, entity.ChatMessagePlayed{
+		Ref:      message.Ref,
+		PlayedAt: *message.PlayedAt,
+	})
+	for _, chatId := range []string{
+		fmt.Sprintf("%s-%s", message.Sender, message.Receiver),
+		fmt.Sprintf("%s-%s", message.Receiver, message.Sender),
+	} {
+		router.Config.Send(chatId, sent)
+	}
+
+	util.Response(message, iris.StatusOK, c)
+}
+
+// ReactToMessage sets the member's reaction to a message, replacing the one
+// it had.
+func (router *ChatRouter) ReactToMessage(c iris.Context) {
+	var request entity.ReactionRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	err = request.ValidateReactionRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	message, err := router.SetReaction(auth.ExtractTokenClaims(c.Request(), "profile_id"), c.Params().Get("id"), request.Emoji)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(message, iris.StatusOK, c)
+}
+
+// RemoveReaction takes the member's reaction to a message back.
+func (router *ChatRouter) RemoveReaction(c iris.Context) {
+	_, err := router.SetReaction(auth.ExtractTokenClaims(c.Request(), "profile_id"), c.Params().Get("id"), "")
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// SetReaction stores profile's reaction to message ID and tells both sides.
+func (router *ChatRouter) SetReaction(profile, ID, emoji string) (*entity.ChatMessage, error) {
+	message, err := router.Config.Persistence.Chat.SetChatMessageReaction(ID, profile, emoji)
+	if err != nil {
+		return nil, err
+	}
+	sent := entity.NewChatEvent(entity.EVENT_MESSAGE_REACTION, entity.ChatMessageReaction{
+		Ref:     entity.MessageRef(message),
+		Profile: profile,
+		Emoji:   emoji,
+	})
+	for _, chatId := range []string{
+		fmt.Sprintf("%s-%s", message.Sender, message.Receiver),
+		fmt.Sprintf("%s-%s", message.Receiver, message.Sender),
+	} {
+		router.Config.Send(chatId, sent)
+	}
+	return message, nil
+}
+
+// HideMessage removes a message from the member's own history. The other
+// participant still sees it.
+func (router *ChatRouter) HideMessage(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	ID := c.Params().Get("id")
+	err := router.Config.Persistence.Chat.HideChatMessage(profile, ID)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(iris.Map{"id": ID, "hidden": true}, iris.StatusOK, c)
+}
+
+// HideChat clears the member's history with receiver and takes the chat
+// off the member's chat list until the next message.
+func (router *ChatRouter) HideChat(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver := c.Params().Get("receiver")
+	err := router.Config.Persistence.Chat.HideChat(profile, receiver)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	router.ChatListChanged(profile, receiver)
+	util.Response(iris.Map{"receiver": receiver, "hidden": true}, iris.StatusOK, c)
+}
+
+// MarkChatRead marks the chat as read up to seq, or up to the message with
+// message_id. Without either it marks the whole chat as read.
+func (router *ChatRouter) MarkChatRead(c iris.Context) {
+	var data struct {
+		MessageId string `json:"message_id"`
+		Seq       int64  `json:"seq"`
+	}
+
+	err := c.ReadJSON(&data)
+	if err != nil && !iris.IsErrEmptyJSON(err) {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver := c.Params().Get("receiver")
+	if data.Seq == 0 && data.MessageId != "" {
+		read, err := router.Config.Persistence.Chat.GetChatMessage(profile, data.MessageId)
+		if err != nil {
+			util.ResponseError(err, iris.StatusNotFound, c)
+			return
+		}
+		data.Seq = read.Seq
+	}
+	read, err := router.MarkRead(profile, receiver, data.Seq)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(read, iris.StatusOK, c)
+}
+
+// MarkRead marks profile's chat with receiver as read up to seq, or all of
+// it when seq is 0, and tells receiver and the webhooks.
+func (router *ChatRouter) MarkRead(profile, receiver string, seq int64) (*entity.ChatMessageRead, error) {
+	message, unread, err := router.Config.Persistence.Chat.MarkChatMessagesRead(profile, receiver, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	read := entity.ChatMessageRead{
+		Reader:      profile,
+		Ref:         message.Ref,
+		Seq:         message.Seq,
+		UnreadCount: unread,
+		ReadAt:      util.GetTimeNow(),
+	}
+	router.Config.Send(fmt.Sprintf("%s-%s", receiver, profile), entity.NewChatEvent(entity.EVENT_MESSAGE_READ, read))
+	router.ChatListChanged(profile, receiver)
+	go router.Config.SendNotifications(entity.WEBHOOK_MESSAGE_READ, read)
+	return &read, nil
+}
+
+// SyncMessages returns the member's messages with receiver after the
+// after_seq query parameter and, when given, before before_seq, so a client
+// can fill a gap it found in the seqs it has.
+func (router *ChatRouter) SyncMessages(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver := c.Params().Get("receiver")
+	after := c.URLParamInt64Default("after_seq", 0)
+	before := c.URLParamInt64Default("before_seq", 0)
+	limit := c.URLParamInt64Default("limit", entity.SYNC_PAGE_SIZE)
+	if after < 0 || before < 0 || limit <= 0 || limit > entity.MAX_SYNC_PAGE_SIZE {
+		util.ResponseError(util.GetError("invalid_sync_range"), iris.StatusBadRequest, c)
+		return
+	}
+
+	conversation, err := router.Config.Persistence.Chat.GetConversation(profile, receiver)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	chatId := fmt.Sprintf("%s-%s", profile, receiver)
+	messages, err := router.Config.Persistence.Chat.GetChatMessagesBySeq(chatId, after, before, limit+1)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
 
-		sent, _ := json.Marshal(entity.ChatMessageHistory{*message})
-		receiver.Write(sent)
+	sync := entity.ChatSync{Messages: messages, Seq: conversation.Seq}
+	if int64(len(messages)) > limit {
+		sync.Messages = messages[:limit]
+		sync.HasMore = true
 	}
+	router.MarkPinsAndStars(sync.Messages, profile, receiver)
+	util.Response(sync, iris.StatusOK, c)
 }
 
 // GetChatList ...
@@ -135,8 +881,12 @@ func (router *ChatRouter) GetChatList(c iris.Context) {
 	for {
 		select {
 		case <-tick.C:
-			rooms, _ := router.Config.Persistence.Chat.GetChatList(profile)
-			value, _ := json.Marshal(rooms)
+			var summary entity.ChatListSummary
+			summary.Chats, _ = router.Config.Persistence.Chat.GetChatList(profile)
+			if counter, err := router.Config.Persistence.Chat.GetChatCounter(profile); err == nil {
+				summary.Counter = *counter
+			}
+			value, _ := json.Marshal(summary)
 			c.Writef("data: %s\n\n", value)
 			c.ResponseWriter().Flush()
 
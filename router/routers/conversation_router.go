@@ -0,0 +1,519 @@
+package routers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/infrastructure/dedup"
+	"github.com/majid-cj/go-chat-server/infrastructure/ratelimit"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+)
+
+// ConversationRouter serves conversations and their messages as resources,
+// for clients that do not keep a websocket open. Messages sent here are
+// delivered the same way as over the websocket.
+type ConversationRouter struct {
+	Config *config.AppConfig
+	Chat   *ChatRouter
+}
+
+// NewConversationRouter ...
+func NewConversationRouter(config *config.AppConfig, chat *ChatRouter) *ConversationRouter {
+	return &ConversationRouter{
+		Config: config,
+		Chat:   chat,
+	}
+}
+
+// CreateConversation starts a conversation with the participant in the
+// body. Starting one that already exists returns it.
+func (router *ConversationRouter) CreateConversation(c iris.Context) {
+	var request entity.ConversationRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseErrorCode(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	participant, err := router.Config.Persistence.Profile.GetMemberProfileByID(request.Participant)
+	if err != nil {
+		util.ResponseErrorCode(util.GetError("profile_not_found"), iris.StatusNotFound, c)
+		return
+	}
+	conversation, created, err := router.Config.Persistence.Chat.CreateConversation(profile, participant.ID)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	router.Chat.ChatListChanged(profile, participant.ID)
+	status := iris.StatusOK
+	if created {
+		status = iris.StatusCreated
+	}
+	util.Response(conversation.Resource(profile), status, c)
+}
+
+// ListConversations lists the member's conversations, latest activity
+// first.
+func (router *ConversationRouter) ListConversations(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	var updatedAt time.Time
+	var ID string
+	if cursor := c.URLParam("cursor"); cursor != "" {
+		var err error
+		updatedAt, ID, err = entity.ParseConversationCursor(cursor)
+		if err != nil {
+			util.ResponseErrorCode(err, iris.StatusBadRequest, c)
+			return
+		}
+	}
+
+	conversations, err := router.Config.Persistence.Chat.GetConversations(profile, updatedAt, ID, limit+1)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	page := util.Page{Limit: limit}
+	if int64(len(conversations)) > limit {
+		conversations = conversations[:limit]
+		page.HasMore = true
+		page.NextCursor = entity.ConversationCursor(&conversations[limit-1])
+	}
+	resources := make([]*entity.ConversationResource, 0, len(conversations))
+	for index := range conversations {
+		resources = append(resources, conversations[index].Resource(profile))
+	}
+	util.ResponsePage(resources, page, iris.StatusOK, c)
+}
+
+// GetConversation ...
+func (router *ConversationRouter) GetConversation(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	conversation, err := router.Config.Persistence.Chat.GetConversation(profile, other)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(conversation.Resource(profile), iris.StatusOK, c)
+}
+
+// ListParticipants returns the profiles taking part in the conversation
+// and how far each has read.
+func (router *ConversationRouter) ListParticipants(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	conversation, err := router.Config.Persistence.Chat.GetConversation(profile, other)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return
+	}
+
+	participants := []entity.ConversationParticipant{}
+	for _, member := range conversation.Members {
+		found, err := router.Config.Persistence.Profile.GetMemberProfileByID(member.Profile)
+		if err != nil {
+			continue
+		}
+		participants = append(participants, entity.ConversationParticipant{
+			Profile:     *found,
+			LastReadSeq: member.LastReadSeq,
+		})
+	}
+	util.Response(participants, iris.StatusOK, c)
+}
+
+// UpdateConversation changes the member's own mute and auto-translate
+// settings for the conversation.
+func (router *ConversationRouter) UpdateConversation(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	var request entity.UpdateConversationRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseErrorCode(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	conversation, err := router.Update(profile, other, &request)
+	if err != nil {
+		util.ResponseErrorCode(err, conversationStatus(err), c)
+		return
+	}
+	util.Response(conversation.Resource(profile), iris.StatusOK, c)
+}
+
+// Update applies request to profile's settings for the conversation with
+// other and returns the conversation.
+func (router *ConversationRouter) Update(profile, other string, request *entity.UpdateConversationRequest) (*entity.Conversation, error) {
+	err := request.ValidateUpdateConversationRequest()
+	if err != nil {
+		return nil, err
+	}
+	if request.TranslateTo != nil && *request.TranslateTo != "" {
+		lang, ok := router.Chat.matchLanguage(*request.TranslateTo)
+		if !ok {
+			return nil, util.GetError("invalid_language")
+		}
+		request.TranslateTo = &lang
+	}
+
+	chat := router.Config.Persistence.Chat
+	switch {
+	case request.Muted != nil && !*request.Muted:
+		err = chat.UnmuteChat(profile, other)
+	case request.Muted != nil || request.MutedUntil != nil:
+		err = chat.MuteChat(profile, other, request.MutedUntil)
+	}
+	if err == nil && request.TranslateTo != nil {
+		err = chat.SetChatAutoTranslate(profile, other, *request.TranslateTo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	router.Chat.ChatListChanged(profile, other)
+	return chat.GetConversation(profile, other)
+}
+
+// LeaveConversation clears the member's history and takes the conversation
+// off the member's list until the next message, like hiding a chat.
+func (router *ConversationRouter) LeaveConversation(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	err := router.Config.Persistence.Chat.HideChat(profile, other)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return
+	}
+	router.Chat.ChatListChanged(profile, other)
+	util.Response(iris.Map{"id": c.Params().Get("id"), "left": true}, iris.StatusOK, c)
+}
+
+// SendMessage sends a text message to the conversation. A retry with the
+// same client_msg_id returns the message that was stored the first time.
+func (router *ConversationRouter) SendMessage(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	var request entity.ConversationMessageRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseErrorCode(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	verdict := router.CheckFlood(profile, other)
+	if verdict != nil {
+		code := "muted"
+		if verdict.Verdict == ratelimit.FLOOD_SLOW_DOWN {
+			code = "slow_down"
+		}
+		c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(verdict.RetryAfter.Seconds())), 10))
+		util.ResponseErrorCode(util.GetError(code), iris.StatusTooManyRequests, c)
+		return
+	}
+
+	message, duplicate, err := router.PostMessage(profile, other, &request)
+	if err != nil {
+		util.ResponseErrorCode(err, conversationStatus(err), c)
+		return
+	}
+	if duplicate {
+		util.Response(message, iris.StatusOK, c)
+		return
+	}
+	util.Response(message, iris.StatusCreated, c)
+}
+
+// PostMessage delivers a text message from profile to other. When the
+// request's client_msg_id was already stored, the stored message is returned
+// instead and the bool is true.
+func (router *ConversationRouter) PostMessage(profile, other string, request *entity.ConversationMessageRequest) (*entity.ChatMessage, bool, error) {
+	if strings.TrimSpace(request.Message) == "" {
+		return nil, false, util.GetError("empty_message")
+	}
+	if _, err := router.Config.Persistence.Chat.GetConversation(profile, other); err != nil {
+		return nil, false, err
+	}
+
+	message := entity.ChatMessage{
+		Type:        entity.MESSAGE_TEXT,
+		Sender:      profile,
+		Receiver:    other,
+		Message:     request.Message,
+		ClientMsgId: request.ClientMsgId,
+	}
+	if message.ClientMsgId != "" {
+		if err := message.ValidateClientMsgId(); err != nil {
+			return nil, false, err
+		}
+		claim, err := router.Config.Dedup.Claim(profile, message.ClientMsgId)
+		if err != nil {
+			router.Config.Log.Errorf("message dedup %s: %+v", profile, err)
+		} else if claim.State == dedup.CLAIM_PENDING {
+			return nil, false, util.GetError("message_pending")
+		} else if claim.State == dedup.CLAIM_DONE {
+			stored, err := router.Config.Persistence.Chat.GetChatMessage(profile, claim.ID)
+			if err != nil {
+				return nil, false, err
+			}
+			return stored, true, nil
+		}
+	}
+
+	err := router.Chat.DeliverChatMessage(&message)
+	if err != nil {
+		if message.ClientMsgId != "" {
+			router.Config.Dedup.Release(profile, message.ClientMsgId)
+		}
+		return nil, false, err
+	}
+	if message.ClientMsgId != "" {
+		err = router.Config.Dedup.Complete(profile, message.ClientMsgId, message.Ref, message.Seq, message.CreatedAt)
+		if err != nil {
+			router.Config.Log.Errorf("message dedup %s: %+v", profile, err)
+		}
+	}
+	return &message, false, nil
+}
+
+// ListMessages pages through the member's history of the conversation,
+// newest first. The cursor is the seq to continue before.
+func (router *ConversationRouter) ListMessages(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+	var before int64
+	if cursor := c.URLParam("cursor"); cursor != "" {
+		seq, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || seq <= 0 {
+			util.ResponseErrorCode(util.GetError("invalid_cursor"), iris.StatusBadRequest, c)
+			return
+		}
+		before = seq
+	}
+	if _, err := router.Config.Persistence.Chat.GetConversation(profile, other); err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return
+	}
+
+	messages, err := router.Config.Persistence.Chat.GetLatestChatMessages(fmt.Sprintf("%s-%s", profile, other), before, limit+1)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusInternalServerError, c)
+		return
+	}
+	page := util.Page{Limit: limit}
+	if int64(len(messages)) > limit {
+		messages = messages[:limit]
+		page.HasMore = true
+		page.NextCursor = strconv.FormatInt(messages[limit-1].Seq, 10)
+	}
+	router.Chat.MarkPinsAndStars(messages, profile, other)
+	util.ResponsePage(messages, page, iris.StatusOK, c)
+}
+
+// EditMessage replaces the text of one of the member's text messages.
+func (router *ConversationRouter) EditMessage(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	var request entity.ConversationMessageRequest
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseErrorCode(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	message, ok := router.message(c)
+	if !ok {
+		return
+	}
+	edited, err := router.Edit(profile, other, message, request.Message)
+	if err != nil {
+		util.ResponseErrorCode(err, conversationStatus(err), c)
+		return
+	}
+	util.Response(edited, iris.StatusOK, c)
+}
+
+// Edit replaces the text of message, which profile sent to other. The new
+// text goes through moderation, and both sides get message.edited.
+func (router *ConversationRouter) Edit(profile, other string, message *entity.ChatMessage, text string) (*entity.ChatMessage, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, util.GetError("empty_message")
+	}
+	if message.Sender != profile || message.Type != entity.MESSAGE_TEXT {
+		return nil, util.GetError("message_not_editable")
+	}
+
+	checked := entity.ChatMessage{Type: entity.MESSAGE_TEXT, Ref: message.Ref, Sender: profile, Receiver: other, Message: text}
+	decision := router.Config.Moderation.Run(&checked)
+	if decision.Rejected() {
+		return nil, util.GetError("message_rejected")
+	}
+	if decision.Flagged() {
+		var review entity.ModerationReview
+		review.PrepareModerationReview(&checked, decision.Original, decision.Reasons)
+		if err := router.Config.Persistence.Moderation.AddModerationReview(&review); err != nil {
+			router.Config.Log.Errorf("queueing review for %s: %+v", message.Ref, err)
+		}
+	}
+
+	edited, err := router.Config.Persistence.Chat.EditChatMessage(message.Ref, profile, checked.Message, util.GetTimeNow())
+	if err != nil {
+		return nil, err
+	}
+	if err = router.Config.Persistence.Translation.DeleteTranslations(edited.Ref); err != nil {
+		router.Config.Log.Errorf("dropping translations of %s: %+v", edited.Ref, err)
+	}
+
+	senderChat := fmt.Sprintf("%s-%s", profile, other)
+	receiverChat := fmt.Sprintf("%s-%s", other, profile)
+	sent := entity.NewChatEvent(entity.EVENT_MESSAGE_EDITED, entity.ChatMessageEdited{
+		Ref:      edited.Ref,
+		Seq:      edited.Seq,
+		Message:  edited.Message,
+		EditedAt: *edited.EditedAt,
+	})
+	router.Config.Send(senderChat, sent)
+	router.Config.Send(receiverChat, sent)
+
+	received := *edited
+	received.ViewFor(other)
+	go router.Chat.AutoTranslate(received, receiverChat)
+	if link := linkpreview.FindURL(edited.Message); link != "" {
+		go router.Chat.AttachLinkPreview(edited.Ref, link, senderChat, receiverChat)
+	}
+	return edited, nil
+}
+
+// DeleteMessage deletes one of the member's own messages for both sides.
+// A message from the other participant is only hidden from the member.
+func (router *ConversationRouter) DeleteMessage(c iris.Context) {
+	profile, other, ok := router.participant(c)
+	if !ok {
+		return
+	}
+	message, ok := router.message(c)
+	if !ok {
+		return
+	}
+
+	if message.Sender != profile {
+		err := router.Config.Persistence.Chat.HideChatMessage(profile, message.Ref)
+		if err != nil {
+			util.ResponseErrorCode(err, iris.StatusNotFound, c)
+			return
+		}
+		util.Response(iris.Map{"id": message.Ref, "deleted_for": "me"}, iris.StatusOK, c)
+		return
+	}
+
+	err := router.Config.Persistence.Chat.DeleteChatMessage(message.Ref)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return
+	}
+	router.Config.Persistence.Pin.DeleteMessagePins(message.Ref)
+	router.Config.Persistence.Star.DeleteMessageStars(message.Ref)
+	router.Config.Persistence.Translation.DeleteTranslations(message.Ref)
+
+	deleted := entity.NewChatEvent(entity.EVENT_MESSAGE_DELETED, entity.ChatMessageDeleted{Ref: message.Ref})
+	router.Config.Send(fmt.Sprintf("%s-%s", profile, other), deleted)
+	router.Config.Send(fmt.Sprintf("%s-%s", other, profile), deleted)
+	util.Response(iris.Map{"id": message.Ref, "deleted_for": "everyone"}, iris.StatusOK, c)
+}
+
+// participant returns the member and the other participant of the {id}
+// conversation. Conversations the member is not part of are answered as not
+// found.
+func (router *ConversationRouter) participant(c iris.Context) (string, string, bool) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	other, err := entity.ConversationOther(c.Params().Get("id"), profile)
+	if err != nil {
+		util.ResponseErrorCode(err, iris.StatusNotFound, c)
+		return "", "", false
+	}
+	return profile, other, true
+}
+
+// message returns the {message_id} message as the member sees it, when it
+// belongs to the {id} conversation.
+func (router *ConversationRouter) message(c iris.Context) (*entity.ChatMessage, bool) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("message_id"))
+	if err != nil || message.Conversation != c.Params().Get("id") {
+		util.ResponseErrorCode(util.GetError("message_not_found"), iris.StatusNotFound, c)
+		return nil, false
+	}
+	return message, true
+}
+
+// CheckFlood runs flood control on a message profile sends to other and
+// returns the verdict when the message is rejected. Messages go through if
+// redis is down.
+func (router *ConversationRouter) CheckFlood(profile, other string) *ratelimit.FloodVerdict {
+	verdict, err := router.Config.Flood.Check(profile, fmt.Sprintf("%s-%s", profile, other))
+	if err != nil {
+		router.Config.Log.Errorf("flood control %s: %+v", profile, err)
+		return nil
+	}
+	if verdict.Verdict == ratelimit.FLOOD_ALLOW {
+		return nil
+	}
+	return verdict
+}
+
+// conversationStatus is the status code of an error from sending, editing
+// or updating.
+func conversationStatus(err error) int {
+	switch fmt.Sprintf("%+v", err) {
+	case "chat_not_found", "message_not_found":
+		return iris.StatusNotFound
+	case "message_not_editable":
+		return iris.StatusForbidden
+	case "message_pending":
+		return iris.StatusConflict
+	case "general_error", "error_retrieve":
+		return iris.StatusInternalServerError
+	}
+	return iris.StatusUnprocessableEntity
+}
+
+// pageLimit reads the limit query parameter of a list.
+func pageLimit(c iris.Context) (int64, bool) {
+	limit := c.URLParamInt64Default("limit", entity.CONVERSATION_PAGE_SIZE)
+	if limit <= 0 || limit > entity.MAX_CONVERSATION_PAGE_SIZE {
+		util.ResponseErrorCode(util.GetError("invalid_page_size"), iris.StatusBadRequest, c)
+		return 0, false
+	}
+	return limit, true
+}
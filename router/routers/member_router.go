@@ -1,9 +1,13 @@
 package routers
 
 import (
+	"fmt"
+
 	"github.com/majid-cj/go-chat-server/config"
 	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
 	"github.com/majid-cj/go-chat-server/util"
+	"github.com/olahol/melody"
 
 	"github.com/kataras/iris/v12"
 )
@@ -13,6 +17,10 @@ type MemberRouters struct {
 	Config *config.AppConfig
 }
 
+type memberNote struct {
+	Note string `json:"note"`
+}
+
 // NewMemberRouters ...
 func NewMemberRouters(config *config.AppConfig) *MemberRouters {
 	return &MemberRouters{
@@ -20,24 +28,220 @@ func NewMemberRouters(config *config.AppConfig) *MemberRouters {
 	}
 }
 
-// GetAllMembers ...
-func (router *MemberRouters) GetAllMembers(c iris.Context) {
-	var members entity.Members
-	members, err := router.Config.Persistence.Member.GetMembers()
+// SearchMembers lists members by part of their email, their id or their
+// exact nick name, filtered by role and active.
+func (router *MemberRouters) SearchMembers(c iris.Context) {
+	search := entity.MemberSearch{
+		Query: c.URLParamTrim("q"),
+		Role:  c.URLParamTrim("role"),
+	}
+	if c.URLParamExists("active") {
+		active, err := c.URLParamBool("active")
+		if err != nil {
+			util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+			return
+		}
+		search.Active = &active
+	}
+	if search.Query != "" {
+		if profile, err := router.Config.Persistence.Profile.GetMemberProfileByNickName(search.Query); err == nil {
+			search.Members = append(search.Members, profile.Member)
+		}
+	}
+
+	members, err := router.Config.Persistence.Member.SearchMembers(search, c.URLParamInt64Default("page", 1))
 	if err != nil {
-		util.ResponseError(err, iris.StatusNotFound, c)
+		util.ResponseError(err, iris.StatusInternalServerError, c)
 		return
 	}
-	util.Response(members.GetMembersSerializer(), iris.StatusOK, c)
+	util.Response(entity.Members(members).GetMembersSerializer(), iris.StatusOK, c)
 }
 
-// GetMembersByType ...
-func (router *MemberRouters) GetMembersByType(c iris.Context) {
-	var members entity.Members
-	members, err := router.Config.Persistence.Member.GetMembersByType(c.Params().GetUint8Default("type", 1))
+// GetMember returns the member with its profile and how many sessions it is
+// signed in with.
+func (router *MemberRouters) GetMember(c iris.Context) {
+	member, err := router.Config.Persistence.Member.GetMember(c.Params().Get("id"))
 	if err != nil {
 		util.ResponseError(err, iris.StatusNotFound, c)
 		return
 	}
-	util.Response(members.GetMembersSerializer(), iris.StatusOK, c)
+	sessions, err := router.Config.Auth.Auth.CountMemberTokens(member.ID)
+	if err != nil {
+		util.ResponseError(util.GetError("general_error"), iris.StatusInternalServerError, c)
+		return
+	}
+	profile, _ := router.Config.Persistence.Profile.GetMemberProfileByMemberID(member.ID)
+
+	util.Response(map[string]interface{}{
+		"member":   member.GetMemberSerializer(),
+		"profile":  profile,
+		"sessions": sessions,
+	}, iris.StatusOK, c)
+}
+
+// ActivateMember ...
+func (router *MemberRouters) ActivateMember(c iris.Context) {
+	var data memberNote
+	staff, member, ok := router.manage(c, &data)
+	if !ok {
+		return
+	}
+	err := router.Config.Persistence.Member.SetMemberActive(member.ID, true)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	router.Config.Audit(staff.ID, entity.AUDIT_MEMBER_ACTIVATE, member.ID, data.Note)
+	member.Active = true
+	util.Response(member.GetMemberSerializer(), iris.StatusOK, c)
+}
+
+// DeactivateMember turns the member off and signs it out everywhere, like a
+// suspension but on behalf of the member rather than as a sanction.
+func (router *MemberRouters) DeactivateMember(c iris.Context) {
+	var data memberNote
+	staff, member, ok := router.manage(c, &data)
+	if !ok {
+		return
+	}
+	err := router.Config.Persistence.Member.SetMemberActive(member.ID, false)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	err = router.signOut(member, "member deactivated")
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(staff.ID, entity.AUDIT_MEMBER_DEACTIVATE, member.ID, data.Note)
+	member.Active = false
+	util.Response(member.GetMemberSerializer(), iris.StatusOK, c)
+}
+
+// ResetMemberPassword signs the member out and emails it a reset code. It
+// cannot sign in again until it sets a new password with /user/reset/password.
+func (router *MemberRouters) ResetMemberPassword(c iris.Context) {
+	var code entity.VerificationCode
+	var data memberNote
+	staff, member, ok := router.manage(c, &data)
+	if !ok {
+		return
+	}
+	err := router.Config.Persistence.Member.SetPasswordReset(member.ID, true)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	err = router.signOut(member, "password reset")
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(staff.ID, entity.AUDIT_MEMBER_PASSWORD_RESET, member.ID, data.Note)
+
+	code.PrepareVerificationCode(member.ID, 3)
+	_, err = router.Config.Persistence.VerifyCode.CreateVerificationCode(&code)
+	if err != nil {
+		util.ResponseError(util.GetError("general_error"), iris.StatusInternalServerError, c)
+		return
+	}
+	name := ""
+	if profile, err := router.Config.Persistence.Profile.GetMemberProfileByMemberID(member.ID); err == nil {
+		name = profile.DisplayName
+	}
+	err = util.SendMail([]string{member.Email}, util.ReceiverMail{
+		ReceiverMail: member.Email,
+		ReceiverName: name,
+		ReceiverCode: code.Code,
+	}, "verification_code.txt", "Password Reset Required")
+	if err != nil {
+		router.Config.Log.Errorf("password reset mail to %s: %+v", member.ID, err)
+	}
+
+	member.PasswordReset = true
+	util.Response(member.GetMemberSerializer(), iris.StatusOK, c)
+}
+
+// RevokeMemberSessions signs the member out of every device. It can sign in
+// again straight away.
+func (router *MemberRouters) RevokeMemberSessions(c iris.Context) {
+	var data memberNote
+	staff, member, ok := router.manage(c, &data)
+	if !ok {
+		return
+	}
+	err := router.signOut(member, "sessions revoked")
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(staff.ID, entity.AUDIT_MEMBER_SESSIONS_REVOKE, member.ID, data.Note)
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// UpdateMemberRole sets the member's role and the permissions it holds on
+// top of it. Staff only grant what they hold themselves.
+func (router *MemberRouters) UpdateMemberRole(c iris.Context) {
+	var data entity.MemberRoleRequest
+	staff, member, ok := router.manage(c, &data)
+	if !ok {
+		return
+	}
+	err := data.ValidateMemberRoleRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	if !staff.CanGrant(data) {
+		util.ResponseError(util.GetError("forbidden_access"), iris.StatusForbidden, c)
+		return
+	}
+
+	err = router.Config.Persistence.Member.SetMemberRole(member.ID, data.Role, data.Permissions)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	router.Config.Audit(staff.ID, entity.AUDIT_MEMBER_ROLE, member.ID, fmt.Sprintf("%s %v. %s", data.Role, data.Permissions, data.Note))
+	member.Role = data.Role
+	member.Permissions = data.Permissions
+	util.Response(member.GetMemberSerializer(), iris.StatusOK, c)
+}
+
+// manage reads the optional body into data and loads the staff member making
+// the request and the member it acts on. It writes the error when staff may
+// not act on that member; nobody acts on themselves or on a bot.
+func (router *MemberRouters) manage(c iris.Context, data interface{}) (*entity.Member, *entity.Member, bool) {
+	if err := c.ReadJSON(data); err != nil && !iris.IsErrEmptyJSON(err) {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return nil, nil, false
+	}
+	staff, err := router.Config.Persistence.Member.GetMember(auth.ExtractTokenClaims(c.Request(), "user_id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusForbidden, c)
+		return nil, nil, false
+	}
+	member, err := router.Config.Persistence.Member.GetMember(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return nil, nil, false
+	}
+	if member.ID == staff.ID || member.IsBot() || !staff.CanManage(*member) {
+		util.ResponseError(util.GetError("forbidden_access"), iris.StatusForbidden, c)
+		return nil, nil, false
+	}
+	return staff, member, true
+}
+
+// signOut revokes every token of member and closes its chat sockets.
+func (router *MemberRouters) signOut(member *entity.Member, reason string) error {
+	err := router.Config.Auth.Auth.DeleteMemberTokens(member.ID)
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	if profile, err := router.Config.Persistence.Profile.GetMemberProfileByMemberID(member.ID); err == nil {
+		router.Config.CloseProfileSessions(profile.ID, melody.ClosePolicyViolation, reason)
+	}
+	return nil
 }
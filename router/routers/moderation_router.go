@@ -0,0 +1,302 @@
+package routers
+
+import (
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/olahol/melody"
+)
+
+// ModerationRouter ...
+type ModerationRouter struct {
+	Config *config.AppConfig
+}
+
+// NewModerationRouter ...
+func NewModerationRouter(config *config.AppConfig) *ModerationRouter {
+	return &ModerationRouter{
+		Config: config,
+	}
+}
+
+type moderationAction struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+// Authorized only lets active members holding permission through, by
+// their role or by a grant of their own.
+func (router *ModerationRouter) Authorized(permission string) iris.Handler {
+	return func(c iris.Context) {
+		member, err := router.Config.Persistence.Member.GetMember(auth.ExtractTokenClaims(c.Request(), "user_id"))
+		if err != nil || !member.Active || !member.Can(permission) {
+			util.ResponseError(util.GetError("forbidden_access"), iris.StatusForbidden, c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetReports ...
+func (router *ModerationRouter) GetReports(c iris.Context) {
+	reports, err := router.Config.Persistence.Report.GetReports(c.URLParamDefault("status", entity.REPORT_OPEN), c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(reports, iris.StatusOK, c)
+}
+
+// GetReport ...
+func (router *ModerationRouter) GetReport(c iris.Context) {
+	report, err := router.Config.Persistence.Report.GetReport(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(report, iris.StatusOK, c)
+}
+
+// TriageReport assigns an open report and sets its priority.
+func (router *ModerationRouter) TriageReport(c iris.Context) {
+	var data struct {
+		Priority int    `json:"priority"`
+		Assignee string `json:"assignee"`
+		Note     string `json:"note"`
+	}
+	moderator := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&data)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	report, err := router.Config.Persistence.Report.GetReport(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if report.Resolution != nil {
+		util.ResponseError(util.GetError("report_closed"), iris.StatusConflict, c)
+		return
+	}
+	if data.Assignee == "" {
+		data.Assignee = moderator
+	}
+
+	report.Status = entity.REPORT_TRIAGED
+	report.Priority = data.Priority
+	report.Assignee = data.Assignee
+	report.UpdatedAt = util.GetTimeNow()
+	err = router.Config.Persistence.Report.UpdateReport(report)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(moderator, entity.AUDIT_REPORT_TRIAGE, report.ID, fmt.Sprintf("priority %d, assignee %s. %s", data.Priority, data.Assignee, data.Note))
+	util.Response(report, iris.StatusOK, c)
+}
+
+// ResolveReport closes a report by suspending the reported member, deleting
+// the reported message or dismissing it.
+func (router *ModerationRouter) ResolveReport(c iris.Context) {
+	var data moderationAction
+	moderator := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&data)
+	if err != nil || !entity.ModerationActions[data.Action] {
+		util.ResponseError(util.GetError("invalid_moderation_action"), iris.StatusBadRequest, c)
+		return
+	}
+	report, err := router.Config.Persistence.Report.GetReport(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if report.Resolution != nil {
+		util.ResponseError(util.GetError("report_closed"), iris.StatusConflict, c)
+		return
+	}
+	if data.Action == entity.ACTION_DELETE && report.MessageRef == "" {
+		util.ResponseError(util.GetError("invalid_moderation_action"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	err = router.ApplyAction(moderator, data, report.Reported, report.Reporter, report.MessageRef)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	report.Resolve(moderator, data.Action, data.Note)
+	err = router.Config.Persistence.Report.UpdateReport(report)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(moderator, entity.AUDIT_REPORT_RESOLVE, report.ID, fmt.Sprintf("%s. %s", data.Action, data.Note))
+	util.Response(report, iris.StatusOK, c)
+}
+
+// GetReviews lists messages flagged by the moderation pipeline.
+func (router *ModerationRouter) GetReviews(c iris.Context) {
+	reviews, err := router.Config.Persistence.Moderation.GetModerationReviews(c.URLParamDefault("status", entity.REVIEW_PENDING), c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(reviews, iris.StatusOK, c)
+}
+
+// ResolveReview ...
+func (router *ModerationRouter) ResolveReview(c iris.Context) {
+	var data moderationAction
+	moderator := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&data)
+	if err != nil || !entity.ModerationActions[data.Action] {
+		util.ResponseError(util.GetError("invalid_moderation_action"), iris.StatusBadRequest, c)
+		return
+	}
+	review, err := router.Config.Persistence.Moderation.GetModerationReview(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if review.Resolution != nil {
+		util.ResponseError(util.GetError("report_closed"), iris.StatusConflict, c)
+		return
+	}
+
+	err = router.ApplyAction(moderator, data, review.Sender, review.Receiver, review.Ref)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	review.Resolve(moderator, data.Action, data.Note)
+	err = router.Config.Persistence.Moderation.UpdateModerationReview(review)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(moderator, entity.AUDIT_REVIEW_RESOLVE, review.ID, fmt.Sprintf("%s. %s", data.Action, data.Note))
+	util.Response(review, iris.StatusOK, c)
+}
+
+// SuspendMember ...
+func (router *ModerationRouter) SuspendMember(c iris.Context) {
+	router.setMemberActive(c, false)
+}
+
+// ReinstateMember ...
+func (router *ModerationRouter) ReinstateMember(c iris.Context) {
+	router.setMemberActive(c, true)
+}
+
+func (router *ModerationRouter) setMemberActive(c iris.Context, active bool) {
+	var data moderationAction
+	moderator := auth.ExtractTokenClaims(c.Request(), "user_id")
+	if err := c.ReadJSON(&data); err != nil && !iris.IsErrEmptyJSON(err) {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	member, err := router.Config.Persistence.Member.GetMember(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	if active {
+		err = router.Config.Persistence.Member.SetMemberActive(member.ID, true)
+		if err == nil {
+			router.Config.Audit(moderator, entity.AUDIT_MEMBER_REINSTATE, member.ID, data.Note)
+		}
+	} else {
+		err = router.Suspend(moderator, member, data.Note)
+	}
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	member.Active = active
+	util.Response(member.GetMemberSerializer(), iris.StatusOK, c)
+}
+
+// GetAuditLog ...
+func (router *ModerationRouter) GetAuditLog(c iris.Context) {
+	logs, err := router.Config.Persistence.AuditLog.GetAuditLogs(c.URLParamDefault("target", ""), c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(logs, iris.StatusOK, c)
+}
+
+// ApplyAction carries out a report or review decision against the profile
+// that was reported. The other profile is the second side of the
+// conversation the message belongs to.
+func (router *ModerationRouter) ApplyAction(moderator string, data moderationAction, reported, other, ref string) error {
+	switch data.Action {
+	case entity.ACTION_SUSPEND:
+		profile, err := router.Config.Persistence.Profile.GetMemberProfileByID(reported)
+		if err != nil {
+			return err
+		}
+		member, err := router.Config.Persistence.Member.GetMember(profile.Member)
+		if err != nil {
+			return err
+		}
+		return router.Suspend(moderator, member, data.Note)
+	case entity.ACTION_DELETE:
+		return router.DeleteMessage(moderator, ref, data.Note, reported, other)
+	}
+	return nil
+}
+
+// Suspend deactivates member, revokes every token it holds and closes its
+// chat sockets. Staff cannot be suspended; an admin changes their role
+// first.
+func (router *ModerationRouter) Suspend(moderator string, member *entity.Member, note string) error {
+	if member.IsStaff() {
+		return util.GetError("forbidden_access")
+	}
+	err := router.Config.Persistence.Member.SetMemberActive(member.ID, false)
+	if err != nil {
+		return err
+	}
+	err = router.Config.Auth.Auth.DeleteMemberTokens(member.ID)
+	if err != nil {
+		return err
+	}
+	if profile, err := router.Config.Persistence.Profile.GetMemberProfileByMemberID(member.ID); err == nil {
+		router.Config.CloseProfileSessions(profile.ID, melody.ClosePolicyViolation, "member suspended")
+	}
+	router.Config.Audit(moderator, entity.AUDIT_MEMBER_SUSPEND, member.ID, note)
+	return nil
+}
+
+// DeleteMessage removes the message for both participants, with its pins
+// and stars, and tells any connected participant to drop it.
+func (router *ModerationRouter) DeleteMessage(moderator, ref, note string, participants ...string) error {
+	err := router.Config.Persistence.Chat.DeleteChatMessage(ref)
+	if err != nil {
+		return err
+	}
+	router.Config.Audit(moderator, entity.AUDIT_MESSAGE_DELETE, ref, note)
+	router.Config.Persistence.Pin.DeleteMessagePins(ref)
+	router.Config.Persistence.Star.DeleteMessageStars(ref)
+
+	deleted := entity.NewChatEvent(entity.EVENT_MESSAGE_DELETED, entity.ChatMessageDeleted{Ref: ref})
+	for index, profile := range participants {
+		for _, other := range participants[index+1:] {
+			router.Config.Send(fmt.Sprintf("%s-%s", profile, other), deleted)
+			router.Config.Send(fmt.Sprintf("%s-%s", other, profile), deleted)
+		}
+	}
+	return nil
+}
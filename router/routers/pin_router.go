@@ -0,0 +1,110 @@
+package routers
+
+import (
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// PinRouter ...
+type PinRouter struct {
+	Config *config.AppConfig
+}
+
+// NewPinRouter ...
+func NewPinRouter(config *config.AppConfig) *PinRouter {
+	return &PinRouter{
+		Config: config,
+	}
+}
+
+// PinMessage pins one of the member's messages for both participants.
+func (router *PinRouter) PinMessage(c iris.Context) {
+	var pin entity.ChatPin
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	pin.PrepareChatPin(profile, message)
+	err = router.Config.Persistence.Pin.AddPin(&pin)
+	if err != nil {
+		util.ResponseError(err, iris.StatusConflict, c)
+		return
+	}
+	router.Notify(message, entity.NewChatEvent(entity.EVENT_MESSAGE_PINNED, entity.ChatMessagePinned{
+		Ref:      pin.Ref,
+		PinnedBy: pin.PinnedBy,
+		PinnedAt: pin.CreatedAt,
+	}))
+	util.Response(pin, iris.StatusOK, c)
+}
+
+// UnpinMessage lets either participant unpin a message.
+func (router *PinRouter) UnpinMessage(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	ref := entity.MessageRef(message)
+	err = router.Config.Persistence.Pin.DeletePin(entity.ConversationKey(message.Sender, message.Receiver), ref)
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	router.Notify(message, entity.NewChatEvent(entity.EVENT_MESSAGE_UNPINNED, entity.ChatMessagePinned{
+		Ref:      ref,
+		PinnedBy: profile,
+		PinnedAt: util.GetTimeNow(),
+	}))
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// GetPins lists the pinned messages of the member's chat with receiver,
+// newest pin first.
+func (router *PinRouter) GetPins(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	pins, err := router.Config.Persistence.Pin.GetPins(entity.ConversationKey(profile, c.Params().Get("receiver")))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	refs := make([]string, len(pins))
+	for index, pin := range pins {
+		refs[index] = pin.Ref
+	}
+	messages, err := router.Config.Persistence.Chat.GetChatMessagesByRefs(profile, refs)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	byRef := make(map[string]entity.ChatMessage)
+	for _, message := range messages {
+		message.Pinned = true
+		byRef[entity.MessageRef(&message)] = message
+	}
+
+	pinned := []entity.PinnedMessage{}
+	for _, pin := range pins {
+		if message, ok := byRef[pin.Ref]; ok {
+			pinned = append(pinned, entity.PinnedMessage{Pin: pin, Message: message})
+		}
+	}
+	util.Response(pinned, iris.StatusOK, c)
+}
+
+// Notify sends event to both sides of message's chat.
+func (router *PinRouter) Notify(message *entity.ChatMessage, event entity.ChatEvent) {
+	router.Config.Send(fmt.Sprintf("%s-%s", message.Sender, message.Receiver), event)
+	router.Config.Send(fmt.Sprintf("%s-%s", message.Receiver, message.Sender), event)
+}
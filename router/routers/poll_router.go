@@ -0,0 +1,186 @@
+package routers
+
+import (
+	"context"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// POLL_CLOSE_INTERVAL is how often polls past their close time are closed.
+const POLL_CLOSE_INTERVAL = 30 * time.Second
+
+// PollRouter ...
+type PollRouter struct {
+	Config *config.AppConfig
+	Chat   *ChatRouter
+}
+
+// NewPollRouter ...
+func NewPollRouter(config *config.AppConfig, chat *ChatRouter) *PollRouter {
+	return &PollRouter{
+		Config: config,
+		Chat:   chat,
+	}
+}
+
+// CreatePoll ...
+func (router *PollRouter) CreatePoll(c iris.Context) {
+	var request entity.PollRequest
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	receiver, err := router.Config.Persistence.Profile.GetMemberProfileByID(c.Params().Get("receiver"))
+	if err != nil || receiver.ID == profile {
+		util.ResponseError(util.GetError("profile_not_found"), iris.StatusNotFound, c)
+		return
+	}
+
+	err = c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	message, err := router.Chat.SendPoll(profile, receiver.ID, &request)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	util.Response(message, iris.StatusCreated, c)
+}
+
+// GetPoll returns the poll with the member's own vote.
+func (router *PollRouter) GetPoll(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	poll, err := router.Config.Persistence.Poll.GetPoll(c.Params().Get("ref"))
+	if err != nil || !poll.Participant(profile) {
+		util.ResponseError(util.GetError("poll_not_found"), iris.StatusNotFound, c)
+		return
+	}
+	votes, err := router.Config.Persistence.Poll.GetPollVotes(poll.Ref)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	mine := []int{}
+	for _, vote := range votes {
+		if vote.Profile == profile {
+			mine = vote.Options
+		}
+	}
+	util.Response(iris.Map{"poll": poll, "vote": mine}, iris.StatusOK, c)
+}
+
+// VotePoll replaces the member's vote and pushes the new tallies.
+func (router *PollRouter) VotePoll(c iris.Context) {
+	var request entity.PollVoteRequest
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+
+	poll, err := router.Config.Persistence.Poll.GetPoll(c.Params().Get("ref"))
+	if err != nil || !poll.Participant(profile) {
+		util.ResponseError(util.GetError("poll_not_found"), iris.StatusNotFound, c)
+		return
+	}
+	if poll.Closed(util.GetTimeNow()) {
+		util.ResponseError(util.GetError("poll_closed"), iris.StatusConflict, c)
+		return
+	}
+	err = poll.ValidatePollVote(&request)
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	err = router.Config.Persistence.Poll.SetPollVote(&entity.PollVote{
+		Poll:      poll.Ref,
+		Profile:   profile,
+		Options:   request.Options,
+		CreatedAt: util.GetTimeNow(),
+	})
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	poll, err = router.Tally(poll.Ref)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(iris.Map{"poll": poll, "vote": request.Options}, iris.StatusOK, c)
+}
+
+// ClosePoll lets the member who started the poll close it early.
+func (router *PollRouter) ClosePoll(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	poll, err := router.Config.Persistence.Poll.GetPoll(c.Params().Get("ref"))
+	if err != nil || !poll.Participant(profile) {
+		util.ResponseError(util.GetError("poll_not_found"), iris.StatusNotFound, c)
+		return
+	}
+	if poll.CreatedBy != profile {
+		util.ResponseError(util.GetError("forbidden_access"), iris.StatusForbidden, c)
+		return
+	}
+
+	poll, err = router.Close(poll.Ref)
+	if err != nil {
+		util.ResponseError(err, iris.StatusConflict, c)
+		return
+	}
+	util.Response(poll, iris.StatusOK, c)
+}
+
+// Tally recounts the votes of the poll with ref and publishes the result.
+func (router *PollRouter) Tally(ref string) (*entity.Poll, error) {
+	poll, err := router.Config.Persistence.Poll.GetPoll(ref)
+	if err != nil {
+		return nil, err
+	}
+	votes, err := router.Config.Persistence.Poll.GetPollVotes(ref)
+	if err != nil {
+		return nil, err
+	}
+	poll.Tally(votes)
+	return poll, router.Chat.PublishPoll(poll)
+}
+
+// Close closes the poll with ref and publishes its final results.
+func (router *PollRouter) Close(ref string) (*entity.Poll, error) {
+	_, err := router.Config.Persistence.Poll.ClosePoll(ref, util.GetTimeNow())
+	if err != nil {
+		return nil, err
+	}
+	return router.Tally(ref)
+}
+
+// ClosePolls closes polls as their close time passes, until ctx is done.
+func (router *PollRouter) ClosePolls(ctx context.Context) {
+	tick := time.NewTicker(POLL_CLOSE_INTERVAL)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			polls, err := router.Config.Persistence.Poll.GetDuePolls(util.GetTimeNow())
+			if err != nil {
+				router.Config.Log.Errorf("listing due polls: %+v", err)
+				continue
+			}
+			for _, poll := range polls {
+				if _, err := router.Close(poll.Ref); err != nil && err.Error() != "poll_closed" {
+					router.Config.Log.Errorf("closing poll %s: %+v", poll.Ref, err)
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
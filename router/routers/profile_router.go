@@ -82,6 +82,7 @@ func (router *MemberProfileRouter) UpdateMemberProfile(c iris.Context) {
 		util.ResponseError(err, iris.StatusBadRequest, c)
 		return
 	}
+	go router.Config.SendNotifications(entity.WEBHOOK_PROFILE_UPDATED, updatedProfile)
 
 	util.Response(updatedProfile, iris.StatusOK, c)
 }
@@ -0,0 +1,83 @@
+package routers
+
+import (
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// ReportRouter ...
+type ReportRouter struct {
+	Config *config.AppConfig
+}
+
+// NewReportRouter ...
+func NewReportRouter(config *config.AppConfig) *ReportRouter {
+	return &ReportRouter{
+		Config: config,
+	}
+}
+
+// CreateReport files a report against a message or a profile, together with
+// a snapshot of the reporter's conversation with the reported profile.
+func (router *ReportRouter) CreateReport(c iris.Context) {
+	var request entity.ReportRequest
+	var report entity.Report
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	err = request.ValidateReportRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	var reported, pivot string
+	switch request.Kind {
+	case entity.REPORT_MESSAGE:
+		message, err := router.Config.Persistence.Chat.GetChatMessage(profile, request.MessageId)
+		if err != nil {
+			util.ResponseError(err, iris.StatusNotFound, c)
+			return
+		}
+		reported, pivot = message.Sender, message.ID
+		report.MessageRef = message.Ref
+		if report.MessageRef == "" {
+			report.MessageRef = message.ID
+		}
+	case entity.REPORT_PROFILE:
+		target, err := router.Config.Persistence.Profile.GetMemberProfileByID(request.ProfileId)
+		if err != nil {
+			util.ResponseError(err, iris.StatusNotFound, c)
+			return
+		}
+		reported = target.ID
+	}
+	if reported == profile {
+		util.ResponseError(util.GetError("cannot_report_self"), iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	report.PrepareReport(profile, reported, &request)
+	report.Snapshot, err = router.Config.Persistence.Chat.GetChatContext(fmt.Sprintf("%s-%s", profile, reported), pivot, entity.REPORT_SNAPSHOT_SIZE)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	newReport, err := router.Config.Persistence.Report.CreateReport(&report)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	newReport.Snapshot = nil
+	util.Response(newReport, iris.StatusCreated, c)
+}
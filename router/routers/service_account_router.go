@@ -0,0 +1,108 @@
+package routers
+
+import (
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// ServiceAccountRouter manages the accounts our backends call the gRPC API
+// with.
+type ServiceAccountRouter struct {
+	Config *config.AppConfig
+}
+
+// NewServiceAccountRouter ...
+func NewServiceAccountRouter(config *config.AppConfig) *ServiceAccountRouter {
+	return &ServiceAccountRouter{
+		Config: config,
+	}
+}
+
+// CreateServiceAccount returns the account with its token. The token is
+// only returned here.
+func (router *ServiceAccountRouter) CreateServiceAccount(c iris.Context) {
+	var request entity.ServiceAccountRequest
+	var account entity.ServiceAccount
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	err = request.ValidateServiceAccountRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	token := account.PrepareServiceAccount(admin, &request)
+	_, err = router.Config.Persistence.ServiceAccount.CreateServiceAccount(&account)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_SERVICE_ACCOUNT_CREATE, account.ID, account.Name)
+	util.Response(iris.Map{"service_account": account, "token": token}, iris.StatusCreated, c)
+}
+
+// GetServiceAccounts ...
+func (router *ServiceAccountRouter) GetServiceAccounts(c iris.Context) {
+	accounts, err := router.Config.Persistence.ServiceAccount.GetServiceAccounts()
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(accounts, iris.StatusOK, c)
+}
+
+// GetServiceAccount ...
+func (router *ServiceAccountRouter) GetServiceAccount(c iris.Context) {
+	account, err := router.Config.Persistence.ServiceAccount.GetServiceAccount(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(account, iris.StatusOK, c)
+}
+
+// RotateServiceAccountToken replaces the account's token. Calls made with
+// the old token are refused at once, but streams already open stay open.
+func (router *ServiceAccountRouter) RotateServiceAccountToken(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	account, err := router.Config.Persistence.ServiceAccount.GetServiceAccount(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	token := account.NewToken()
+	err = router.Config.Persistence.ServiceAccount.UpdateServiceAccount(account)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_SERVICE_ACCOUNT_TOKEN, account.ID, "")
+	util.Response(iris.Map{"service_account": account, "token": token}, iris.StatusOK, c)
+}
+
+// DeleteServiceAccount revokes the account's token.
+func (router *ServiceAccountRouter) DeleteServiceAccount(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	account, err := router.Config.Persistence.ServiceAccount.GetServiceAccount(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	err = router.Config.Persistence.ServiceAccount.DeleteServiceAccount(account.ID)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_SERVICE_ACCOUNT_DELETE, account.ID, account.Name)
+	c.StatusCode(iris.StatusNoContent)
+}
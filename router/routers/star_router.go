@@ -0,0 +1,90 @@
+package routers
+
+import (
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// StarRouter ...
+type StarRouter struct {
+	Config *config.AppConfig
+}
+
+// NewStarRouter ...
+func NewStarRouter(config *config.AppConfig) *StarRouter {
+	return &StarRouter{
+		Config: config,
+	}
+}
+
+// StarMessage stars one of the member's messages. Only the member sees it.
+func (router *StarRouter) StarMessage(c iris.Context) {
+	var star entity.ChatStar
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	star.PrepareChatStar(profile, message)
+	err = router.Config.Persistence.Star.AddStar(&star)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(star, iris.StatusOK, c)
+}
+
+// UnstarMessage ...
+func (router *StarRouter) UnstarMessage(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	message, err := router.Config.Persistence.Chat.GetChatMessage(profile, c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	err = router.Config.Persistence.Star.DeleteStar(profile, entity.MessageRef(message))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// GetStarred lists what the member starred in every chat, newest star first.
+func (router *StarRouter) GetStarred(c iris.Context) {
+	profile := auth.ExtractTokenClaims(c.Request(), "profile_id")
+	stars, err := router.Config.Persistence.Star.GetStars(profile, c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+
+	refs := make([]string, len(stars))
+	for index, star := range stars {
+		refs[index] = star.Ref
+	}
+	messages, err := router.Config.Persistence.Chat.GetChatMessagesByRefs(profile, refs)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	byRef := make(map[string]entity.ChatMessage)
+	for _, message := range messages {
+		message.Starred = true
+		byRef[entity.MessageRef(&message)] = message
+	}
+
+	starred := []entity.StarredMessage{}
+	for _, star := range stars {
+		if message, ok := byRef[star.Ref]; ok {
+			starred = append(starred, entity.StarredMessage{Star: star, Message: message})
+		}
+	}
+	util.Response(starred, iris.StatusOK, c)
+}
@@ -0,0 +1,203 @@
+package routers
+
+import (
+	"strings"
+
+	"github.com/kataras/iris/v12"
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/infrastructure/auth"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// WebhookRouter ...
+type WebhookRouter struct {
+	Config *config.AppConfig
+}
+
+// NewWebhookRouter ...
+func NewWebhookRouter(config *config.AppConfig) *WebhookRouter {
+	return &WebhookRouter{
+		Config: config,
+	}
+}
+
+// CreateWebhook registers a webhook. The signing secret is only returned
+// here and when it is rotated.
+func (router *WebhookRouter) CreateWebhook(c iris.Context) {
+	var request entity.WebhookRequest
+	var webhook entity.Webhook
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	request.URL = strings.TrimSpace(request.URL)
+	err = request.ValidateWebhookRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+
+	webhook.PrepareWebhook(admin, &request)
+	newWebhook, err := router.Config.Persistence.Webhook.CreateWebhook(&webhook)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_WEBHOOK_CREATE, webhook.ID, webhook.URL)
+	util.Response(newWebhook, iris.StatusCreated, c)
+}
+
+// GetWebhooks ...
+func (router *WebhookRouter) GetWebhooks(c iris.Context) {
+	webhooks, err := router.Config.Persistence.Webhook.GetWebhooks()
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	for index := range webhooks {
+		webhooks[index].Secret = ""
+	}
+	util.Response(webhooks, iris.StatusOK, c)
+}
+
+// GetWebhook ...
+func (router *WebhookRouter) GetWebhook(c iris.Context) {
+	webhook, err := router.Config.Persistence.Webhook.GetWebhook(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	webhook.Secret = ""
+	util.Response(webhook, iris.StatusOK, c)
+}
+
+// UpdateWebhook changes the url, events, description or active flag of a
+// webhook. The secret is kept. Bot webhooks are changed through their bot.
+func (router *WebhookRouter) UpdateWebhook(c iris.Context) {
+	var request entity.WebhookRequest
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+
+	err := c.ReadJSON(&request)
+	if err != nil {
+		util.ResponseError(util.GetError("error_parsing_data"), iris.StatusBadRequest, c)
+		return
+	}
+	request.URL = strings.TrimSpace(request.URL)
+	err = request.ValidateWebhookRequest()
+	if err != nil {
+		util.ResponseError(err, iris.StatusUnprocessableEntity, c)
+		return
+	}
+	webhook, err := router.Config.Persistence.Webhook.GetWebhook(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if webhook.Bot != "" {
+		util.ResponseError(util.GetError("bot_webhook"), iris.StatusConflict, c)
+		return
+	}
+
+	webhook.Update(&request)
+	err = router.Config.Persistence.Webhook.UpdateWebhook(webhook)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	webhook.Secret = ""
+	router.Config.Audit(admin, entity.AUDIT_WEBHOOK_UPDATE, webhook.ID, webhook.URL)
+	util.Response(webhook, iris.StatusOK, c)
+}
+
+// RotateWebhookSecret replaces the signing secret and returns the new one.
+// Deliveries already queued are signed with the new secret when sent.
+func (router *WebhookRouter) RotateWebhookSecret(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	webhook, err := router.Config.Persistence.Webhook.GetWebhook(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+
+	webhook.Secret = entity.NewWebhookSecret()
+	webhook.UpdatedAt = util.GetTimeNow()
+	err = router.Config.Persistence.Webhook.UpdateWebhook(webhook)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_WEBHOOK_ROTATE, webhook.ID, "")
+	util.Response(webhook, iris.StatusOK, c)
+}
+
+// DeleteWebhook ...
+func (router *WebhookRouter) DeleteWebhook(c iris.Context) {
+	admin := auth.ExtractTokenClaims(c.Request(), "user_id")
+	webhook, err := router.Config.Persistence.Webhook.GetWebhook(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	if webhook.Bot != "" {
+		util.ResponseError(util.GetError("bot_webhook"), iris.StatusConflict, c)
+		return
+	}
+
+	err = router.Config.Persistence.Webhook.DeleteWebhook(webhook.ID)
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	router.Config.Audit(admin, entity.AUDIT_WEBHOOK_DELETE, webhook.ID, webhook.URL)
+	c.StatusCode(iris.StatusNoContent)
+}
+
+// GetWebhookDeliveries is the delivery log of one webhook.
+func (router *WebhookRouter) GetWebhookDeliveries(c iris.Context) {
+	deliveries, err := router.Config.Persistence.Webhook.GetWebhookDeliveries(c.Params().Get("id"), c.URLParamDefault("status", ""), c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(deliveries, iris.StatusOK, c)
+}
+
+// GetDeliveries lists deliveries across every webhook, the dead letters by
+// default.
+func (router *WebhookRouter) GetDeliveries(c iris.Context) {
+	deliveries, err := router.Config.Persistence.Webhook.GetWebhookDeliveries(c.URLParamDefault("webhook", ""), c.URLParamDefault("status", entity.DELIVERY_DEAD), c.URLParamInt64Default("page", 1))
+	if err != nil {
+		util.ResponseError(err, iris.StatusInternalServerError, c)
+		return
+	}
+	util.Response(deliveries, iris.StatusOK, c)
+}
+
+// GetDelivery ...
+func (router *WebhookRouter) GetDelivery(c iris.Context) {
+	delivery, err := router.Config.Persistence.Webhook.GetWebhookDelivery(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	util.Response(delivery, iris.StatusOK, c)
+}
+
+// RetryDelivery puts a dead letter back in the queue.
+func (router *WebhookRouter) RetryDelivery(c iris.Context) {
+	delivery, err := router.Config.Persistence.Webhook.GetWebhookDelivery(c.Params().Get("id"))
+	if err != nil {
+		util.ResponseError(err, iris.StatusNotFound, c)
+		return
+	}
+	err = router.Config.Webhooks.Retry(delivery)
+	if err != nil {
+		util.ResponseError(err, iris.StatusConflict, c)
+		return
+	}
+	util.Response(delivery, iris.StatusAccepted, c)
+}
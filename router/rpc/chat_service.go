@@ -0,0 +1,232 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/router/rpc/chatpb"
+	"github.com/majid-cj/go-chat-server/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChatService implements chatpb.ChatServiceServer on top of the same
+// repositories and routers as the HTTP API.
+type ChatService struct {
+	chatpb.UnimplementedChatServiceServer
+	Config       *config.AppConfig
+	Chat         *routers.ChatRouter
+	Conversation *routers.ConversationRouter
+}
+
+// NewChatService ...
+func NewChatService(config *config.AppConfig, chat *routers.ChatRouter) *ChatService {
+	return &ChatService{
+		Config:       config,
+		Chat:         chat,
+		Conversation: routers.NewConversationRouter(config, chat),
+	}
+}
+
+var _ chatpb.ChatServiceServer = &ChatService{}
+
+// GetMember ...
+func (service *ChatService) GetMember(ctx context.Context, request *chatpb.GetMemberRequest) (*chatpb.Member, error) {
+	member, err := service.Config.Persistence.Member.GetMember(request.Id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return toMember(member), nil
+}
+
+// GetProfile ...
+func (service *ChatService) GetProfile(ctx context.Context, request *chatpb.GetProfileRequest) (*chatpb.Profile, error) {
+	profile, err := service.Config.Persistence.Profile.GetMemberProfileByID(request.Id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return toProfile(profile), nil
+}
+
+// GetProfileByMember ...
+func (service *ChatService) GetProfileByMember(ctx context.Context, request *chatpb.GetProfileByMemberRequest) (*chatpb.Profile, error) {
+	profile, err := service.Config.Persistence.Profile.GetMemberProfileByMemberID(request.Member)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return toProfile(profile), nil
+}
+
+// GetProfileByNickName ...
+func (service *ChatService) GetProfileByNickName(ctx context.Context, request *chatpb.GetProfileByNickNameRequest) (*chatpb.Profile, error) {
+	profile, err := service.Config.Persistence.Profile.GetMemberProfileByNickName(request.NickName)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return toProfile(profile), nil
+}
+
+// GetProfiles returns the profiles with the given ids in one query. Ids
+// that are not found are left out.
+func (service *ChatService) GetProfiles(ctx context.Context, request *chatpb.GetProfilesRequest) (*chatpb.GetProfilesResponse, error) {
+	if len(request.Ids) > entity.MAX_CONVERSATION_PAGE_SIZE {
+		return nil, fail(util.GetError("invalid_page_size"))
+	}
+	profiles, err := service.Config.Persistence.Profile.GetMemberProfilesByIDs(request.Ids)
+	if err != nil {
+		return nil, fail(err)
+	}
+	response := &chatpb.GetProfilesResponse{Profiles: make([]*chatpb.Profile, 0, len(profiles))}
+	for index := range profiles {
+		response.Profiles = append(response.Profiles, toProfile(&profiles[index]))
+	}
+	return response, nil
+}
+
+// GetConversation ...
+func (service *ChatService) GetConversation(ctx context.Context, request *chatpb.GetConversationRequest) (*chatpb.Conversation, error) {
+	conversation, err := service.Config.Persistence.Chat.GetConversation(request.Profile, request.Other)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return toConversation(conversation), nil
+}
+
+// ListConversations pages through profile's conversations with the same
+// cursors as the v2 API.
+func (service *ChatService) ListConversations(ctx context.Context, request *chatpb.ListConversationsRequest) (*chatpb.ListConversationsResponse, error) {
+	limit := int64(request.PageSize)
+	if limit == 0 {
+		limit = entity.CONVERSATION_PAGE_SIZE
+	}
+	if limit < 0 || limit > entity.MAX_CONVERSATION_PAGE_SIZE {
+		return nil, fail(util.GetError("invalid_page_size"))
+	}
+
+	var updatedAt time.Time
+	var ID string
+	if request.Cursor != "" {
+		var err error
+		updatedAt, ID, err = entity.ParseConversationCursor(request.Cursor)
+		if err != nil {
+			return nil, fail(err)
+		}
+	}
+
+	conversations, err := service.Config.Persistence.Chat.GetConversations(request.Profile, updatedAt, ID, limit+1)
+	if err != nil {
+		return nil, fail(err)
+	}
+	response := &chatpb.ListConversationsResponse{}
+	if int64(len(conversations)) > limit {
+		conversations = conversations[:limit]
+		response.NextCursor = entity.ConversationCursor(&conversations[limit-1])
+	}
+	response.Conversations = make([]*chatpb.Conversation, 0, len(conversations))
+	for index := range conversations {
+		response.Conversations = append(response.Conversations, toConversation(&conversations[index]))
+	}
+	return response, nil
+}
+
+// ListMessages works like the sync route: what profile can see of its
+// history with other, in seq order.
+func (service *ChatService) ListMessages(ctx context.Context, request *chatpb.ListMessagesRequest) (*chatpb.ListMessagesResponse, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = entity.SYNC_PAGE_SIZE
+	}
+	if request.AfterSeq < 0 || request.BeforeSeq < 0 || limit < 0 || limit > entity.MAX_SYNC_PAGE_SIZE {
+		return nil, fail(util.GetError("invalid_sync_range"))
+	}
+	if _, err := service.Config.Persistence.Chat.GetConversation(request.Profile, request.Other); err != nil {
+		return nil, fail(err)
+	}
+
+	chatId := fmt.Sprintf("%s-%s", request.Profile, request.Other)
+	messages, err := service.Config.Persistence.Chat.GetChatMessagesBySeq(chatId, request.AfterSeq, request.BeforeSeq, limit+1)
+	if err != nil {
+		return nil, fail(err)
+	}
+	response := &chatpb.ListMessagesResponse{}
+	if int64(len(messages)) > limit {
+		messages = messages[:limit]
+		response.HasMore = true
+	}
+	response.Messages = make([]*chatpb.Message, 0, len(messages))
+	for index := range messages {
+		response.Messages = append(response.Messages, toMessage(&messages[index]))
+	}
+	return response, nil
+}
+
+// GetChatCounter ...
+func (service *ChatService) GetChatCounter(ctx context.Context, request *chatpb.GetChatCounterRequest) (*chatpb.ChatCounter, error) {
+	counter, err := service.Config.Persistence.Chat.GetChatCounter(request.Profile)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &chatpb.ChatCounter{UnreadMessages: counter.UnreadMessages, UnreadChats: counter.UnreadChats}, nil
+}
+
+// SendSystemMessage starts the conversation between sender and receiver
+// when needed and sends the message through the v2 send path, so it is
+// moderated and deduplicated by client_msg_id. It is not flood controlled.
+func (service *ChatService) SendSystemMessage(ctx context.Context, request *chatpb.SendSystemMessageRequest) (*chatpb.Message, error) {
+	for _, ID := range []string{request.Sender, request.Receiver} {
+		if _, err := service.Config.Persistence.Profile.GetMemberProfileByID(ID); err != nil {
+			return nil, fail(err)
+		}
+	}
+	if request.Sender == request.Receiver {
+		return nil, fail(util.GetError("profile_not_found"))
+	}
+	if _, _, err := service.Config.Persistence.Chat.CreateConversation(request.Sender, request.Receiver); err != nil {
+		return nil, fail(err)
+	}
+
+	message, _, err := service.Conversation.PostMessage(request.Sender, request.Receiver, &entity.ConversationMessageRequest{
+		Message:     request.Message,
+		ClientMsgId: request.ClientMsgId,
+	})
+	if err != nil {
+		return nil, fail(err)
+	}
+	service.Config.Log.Debugf("service account %s sent %s", serviceAccountOf(ctx).ID, message.Ref)
+	return toMessage(message), nil
+}
+
+// StreamMessages forwards new messages from the events broker until the
+// caller goes away. Events are published by the instance that stored the
+// message, so callers need a stream to every instance; a caller that falls
+// behind misses messages and can fill the gap with ListMessages.
+func (service *ChatService) StreamMessages(request *chatpb.StreamMessagesRequest, stream chatpb.ChatService_StreamMessagesServer) error {
+	topic := entity.MESSAGES_TOPIC
+	if request.Profile != "" {
+		topic = entity.MessageTopic(request.Profile)
+	}
+	events, cancel := service.Config.Events.Subscribe(topic)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "general_error")
+			}
+			message, ok := event.(entity.ChatMessage)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(toMessage(&message)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/router/rpc/chatpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toTimestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
+
+func toMember(member *entity.Member) *chatpb.Member {
+	return &chatpb.Member{
+		Id:        member.ID,
+		Email:     member.Email,
+		Verified:  member.Verified,
+		Active:    member.Active,
+		Role:      member.Role,
+		CreatedAt: timestamppb.New(member.CreatedAt),
+	}
+}
+
+func toProfile(profile *entity.MemberProfile) *chatpb.Profile {
+	return &chatpb.Profile{
+		Id:           profile.ID,
+		Member:       profile.Member,
+		DisplayName:  profile.DisplayName,
+		NickName:     profile.NickName,
+		ProfileImage: profile.ProfileImage,
+		IsPrivate:    profile.Private,
+		IsBot:        profile.Bot,
+		CreatedAt:    timestamppb.New(profile.CreatedAt),
+	}
+}
+
+func toConversation(conversation *entity.Conversation) *chatpb.Conversation {
+	result := &chatpb.Conversation{
+		Id:            conversation.ID,
+		Participants:  conversation.Participants,
+		Members:       make([]*chatpb.ConversationMember, 0, len(conversation.Members)),
+		Seq:           conversation.Seq,
+		LastMessageId: conversation.LastMessageId,
+		LastMessage:   conversation.LastMessage,
+		LastSender:    conversation.LastSender,
+		CreatedAt:     timestamppb.New(conversation.CreatedAt),
+		UpdatedAt:     timestamppb.New(conversation.UpdatedAt),
+	}
+	for _, member := range conversation.Members {
+		result.Members = append(result.Members, &chatpb.ConversationMember{
+			Profile:     member.Profile,
+			LastReadSeq: member.LastReadSeq,
+			UnreadCount: member.UnreadCount,
+			Muted:       member.Muted,
+			MutedUntil:  toTimestamp(member.MutedUntil),
+			TranslateTo: member.TranslateTo,
+			Hidden:      member.Hidden,
+		})
+	}
+	return result
+}
+
+func toMessage(message *entity.ChatMessage) *chatpb.Message {
+	return &chatpb.Message{
+		Id:           message.Ref,
+		Conversation: message.Conversation,
+		Seq:          message.Seq,
+		ChatId:       message.ChatId,
+		Type:         message.Type,
+		Sender:       message.Sender,
+		Receiver:     message.Receiver,
+		Message:      message.Message,
+		ClientMsgId:  message.ClientMsgId,
+		EditedAt:     toTimestamp(message.EditedAt),
+		CreatedAt:    timestamppb.New(message.CreatedAt),
+	}
+}
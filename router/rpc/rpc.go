@@ -0,0 +1,100 @@
+// Package rpc serves the gRPC API our own backends call with a service
+// account, next to the HTTP server in the same binary.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I chatpb chatpb/chat.proto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/majid-cj/go-chat-server/config"
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/router/routers"
+	"github.com/majid-cj/go-chat-server/router/rpc/chatpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type serviceAccountKey struct{}
+
+// NewServer returns the gRPC server with the chat service registered and
+// every call authenticated with a service account.
+func NewServer(config *config.AppConfig, chat *routers.ChatRouter) *grpc.Server {
+	service := NewChatService(config, chat)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(service.AuthenticatedUnary),
+		grpc.ChainStreamInterceptor(service.AuthenticatedStream),
+	)
+	chatpb.RegisterChatServiceServer(server, service)
+	return server
+}
+
+// authenticate checks the "authorization: Bearer svc_..." metadata of a call
+// and returns ctx with the service account on it.
+func (service *ChatService) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	if !strings.HasPrefix(token, entity.SERVICE_TOKEN_PREFIX) {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized_access")
+	}
+	account, err := service.Config.Persistence.ServiceAccount.GetServiceAccountByToken(entity.HashBotToken(token))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized_access")
+	}
+	return context.WithValue(ctx, serviceAccountKey{}, account), nil
+}
+
+// AuthenticatedUnary ...
+func (service *ChatService) AuthenticatedUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := service.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthenticatedStream ...
+func (service *ChatService) AuthenticatedStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := service.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// serviceAccountOf returns the service account that made the call.
+func serviceAccountOf(ctx context.Context) *entity.ServiceAccount {
+	account, _ := ctx.Value(serviceAccountKey{}).(*entity.ServiceAccount)
+	return account
+}
+
+// fail turns an error from the repositories or routers into a status. The
+// message is the locale key, so callers can match on it.
+func fail(err error) error {
+	code := fmt.Sprintf("%+v", err)
+	switch {
+	case strings.HasSuffix(code, "_not_found"):
+		return status.Error(codes.NotFound, code)
+	case code == "message_pending":
+		return status.Error(codes.Aborted, code)
+	case code == "general_error", code == "error_retrieve":
+		return status.Error(codes.Internal, code)
+	}
+	return status.Error(codes.InvalidArgument, code)
+}
@@ -11,6 +11,7 @@ func MemberRouteEndPoints(
 	authentication *routers.AuthenticationRouter,
 	member *routers.MemberRouters,
 	verifyCode *routers.VerifyCodeRouter,
+	accountDeletion *routers.AccountDeletionRouter,
 	APIVersion router.Party,
 ) {
 	userRoute := APIVersion.Party("/user")
@@ -27,6 +28,10 @@ func MemberRouteEndPoints(
 		userRoute.Put("/password", authentication.UpdatePassword)
 		userRoute.Post("/logout", authentication.Logout)
 
+		userRoute.Post("/delete", accountDeletion.RequestAccountDeletion)
+		userRoute.Get("/delete", accountDeletion.GetAccountDeletion)
+		userRoute.Delete("/delete", accountDeletion.CancelAccountDeletion)
+
 		userRoute.Post("/verify/code", verifyCode.NewVerifyCode)
 		userRoute.Post("/verify/check", verifyCode.CheckVerifyCode)
 		userRoute.Post("/verify/renew", verifyCode.RenewVerifyCode)
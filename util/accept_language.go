@@ -0,0 +1,17 @@
+package util
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AcceptLanguage returns the first language of the Accept-Language header.
+func AcceptLanguage(r *http.Request) string {
+	lang := strings.FieldsFunc(r.Header.Get("Accept-Language"), func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+	if len(lang) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(lang[0])
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AcceptLanguage(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, "", AcceptLanguage(request))
+
+	request.Header.Set("Accept-Language", " ar-SA;q=0.9, en")
+	assert.Equal(t, "ar-SA", AcceptLanguage(request))
+}
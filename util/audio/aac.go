@@ -0,0 +1,52 @@
+package audio
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+var adtsSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// probeADTS counts the frames of a raw AAC (ADTS) stream. Every raw data
+// block carries 1024 samples at the rate declared in the frame header.
+func probeADTS(data []byte) (*Info, []int, error) {
+	var sizes []int
+	offset := skipID3(data)
+	samples, sampleRate := 0, 0
+
+	for offset+7 <= len(data) {
+		header := data[offset : offset+7]
+		if header[0] != 0xFF || header[1]&0xF6 != 0xF0 {
+			break
+		}
+		rateIndex := int(header[2]>>2) & 0x0F
+		length := int(header[3]&0x03)<<11 | int(header[4])<<3 | int(header[5]>>5)
+		blocks := int(header[6]&0x03) + 1
+		if rateIndex >= len(adtsSampleRates) || length < 7 || offset+length > len(data) {
+			break
+		}
+
+		sampleRate = adtsSampleRates[rateIndex]
+		samples += blocks * 1024
+		sizes = append(sizes, length)
+		offset += length
+	}
+
+	if len(sizes) < 2 {
+		return nil, nil, util.GetError("not_supported_type")
+	}
+	return &Info{
+		MimeType: MIME_AAC,
+		Duration: time.Duration(samples) * time.Second / time.Duration(sampleRate),
+	}, sizes, nil
+}
+
+func skipID3(data []byte) int {
+	if len(data) < 10 || !bytes.HasPrefix(data, []byte("ID3")) {
+		return 0
+	}
+	size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+	return 10 + size
+}
@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+type box struct {
+	kind string
+	body []byte
+}
+
+// boxes splits an ISO BMFF buffer into its top level boxes.
+func boxes(data []byte) ([]box, error) {
+	var results []box
+	for offset := 0; offset+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		kind := string(data[offset+4 : offset+8])
+		header := 8
+		switch size {
+		case 0:
+			size = len(data) - offset
+		case 1:
+			if offset+16 > len(data) {
+				return nil, util.GetError("invalid_audio")
+			}
+			largesize := binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			if largesize > uint64(len(data)-offset) {
+				return nil, util.GetError("invalid_audio")
+			}
+			size = int(largesize)
+			header = 16
+		}
+		if size < header || size > len(data)-offset {
+			return nil, util.GetError("invalid_audio")
+		}
+		results = append(results, box{kind: kind, body: data[offset+header : offset+size]})
+		offset += size
+	}
+	return results, nil
+}
+
+func child(data []byte, path ...string) []byte {
+	for _, kind := range path {
+		found := false
+		children, err := boxes(data)
+		if err != nil {
+			return nil
+		}
+		for _, b := range children {
+			if b.kind == kind {
+				data = b.body
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return data
+}
+
+// probeMP4 reads an M4A file: the sound track's mdhd gives the duration and
+// its stsz table the size of every encoded sample.
+func probeMP4(data []byte) (*Info, []int, error) {
+	moov := child(data, "moov")
+	if moov == nil {
+		return nil, nil, util.GetError("invalid_audio")
+	}
+	traks, err := boxes(moov)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sound []byte
+	for _, trak := range traks {
+		if trak.kind != "trak" {
+			continue
+		}
+		hdlr := child(trak.body, "mdia", "hdlr")
+		if len(hdlr) < 12 {
+			continue
+		}
+		switch string(hdlr[8:12]) {
+		case "vide":
+			return nil, nil, util.GetError("not_supported_type")
+		case "soun":
+			if sound == nil {
+				sound = trak.body
+			}
+		}
+	}
+	if sound == nil {
+		return nil, nil, util.GetError("not_supported_type")
+	}
+
+	duration, err := mediaDuration(child(sound, "mdia", "mdhd"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Info{
+		MimeType: MIME_M4A,
+		Duration: duration,
+	}, sampleSizes(child(sound, "mdia", "minf", "stbl", "stsz")), nil
+}
+
+func mediaDuration(mdhd []byte) (time.Duration, error) {
+	var timescale, duration uint64
+	switch {
+	case len(mdhd) >= 24 && mdhd[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(mdhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mdhd[16:20]))
+	case len(mdhd) >= 36 && mdhd[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(mdhd[20:24]))
+		duration = binary.BigEndian.Uint64(mdhd[24:32])
+	default:
+		return 0, util.GetError("invalid_audio")
+	}
+	if timescale == 0 || duration/timescale >= uint64(math.MaxInt64/int64(time.Second)) {
+		return 0, util.GetError("invalid_audio")
+	}
+	// whole seconds and the remainder apart, so duration*time.Second cannot
+	// overflow.
+	seconds := time.Duration(duration/timescale) * time.Second
+	return seconds + time.Duration(duration%timescale)*time.Second/time.Duration(timescale), nil
+}
+
+func sampleSizes(stsz []byte) []int {
+	if len(stsz) < 12 {
+		return nil
+	}
+	fixed := int(binary.BigEndian.Uint32(stsz[4:8]))
+	count := int(binary.BigEndian.Uint32(stsz[8:12]))
+	if fixed != 0 {
+		// constant size samples carry no loudness information.
+		return nil
+	}
+	// count comes from the file; never allocate more than the table holds.
+	if limit := (len(stsz) - 12) / 4; count > limit {
+		count = limit
+	}
+	sizes := make([]int, 0, count)
+	for offset := 12; offset+4 <= len(stsz) && len(sizes) < count; offset += 4 {
+		sizes = append(sizes, int(binary.BigEndian.Uint32(stsz[offset:offset+4])))
+	}
+	return sizes
+}
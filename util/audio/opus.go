@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const opusSampleRate = 48000
+
+// probeOpus walks the Ogg pages of an Opus stream. The duration comes from
+// the last granule position (always counted at 48kHz) minus the pre-skip
+// declared in the OpusHead packet.
+func probeOpus(data []byte) (*Info, []int, error) {
+	var sizes []int
+	var granule int64 = -1
+	var preSkip int64
+	packet, packets := 0, 0
+
+	for offset := 0; offset < len(data); {
+		if offset+27 > len(data) || !bytes.Equal(data[offset:offset+4], []byte("OggS")) {
+			return nil, nil, util.GetError("invalid_audio")
+		}
+		pageGranule := int64(binary.LittleEndian.Uint64(data[offset+6 : offset+14]))
+		segments := int(data[offset+26])
+		body := offset + 27 + segments
+		if body > len(data) {
+			return nil, nil, util.GetError("invalid_audio")
+		}
+
+		if offset == 0 {
+			if body+19 > len(data) || !bytes.Equal(data[body:body+8], []byte("OpusHead")) {
+				return nil, nil, util.GetError("not_supported_type")
+			}
+			preSkip = int64(binary.LittleEndian.Uint16(data[body+10 : body+12]))
+		}
+
+		pageSize := 0
+		for _, lacing := range data[offset+27 : body] {
+			packet += int(lacing)
+			pageSize += int(lacing)
+			if lacing < 255 {
+				// the first two packets are OpusHead and OpusTags.
+				if packets >= 2 {
+					sizes = append(sizes, packet)
+				}
+				packets++
+				packet = 0
+			}
+		}
+		if pageGranule > granule {
+			granule = pageGranule
+		}
+		offset = body + pageSize
+	}
+
+	samples := granule - preSkip
+	if samples <= 0 || samples/opusSampleRate >= math.MaxInt64/int64(time.Second) {
+		return nil, nil, util.GetError("invalid_audio")
+	}
+	// whole seconds and the remainder apart, so samples*time.Second cannot
+	// overflow.
+	seconds := time.Duration(samples/opusSampleRate) * time.Second
+	return &Info{
+		MimeType: MIME_OPUS,
+		Duration: seconds + time.Duration(samples%opusSampleRate)*time.Second/opusSampleRate,
+	}, sizes, nil
+}
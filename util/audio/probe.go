@@ -0,0 +1,87 @@
+package audio
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// MIME_OPUS ...
+	MIME_OPUS = "audio/ogg"
+	// MIME_AAC ...
+	MIME_AAC = "audio/aac"
+	// MIME_M4A ...
+	MIME_M4A = "audio/mp4"
+
+	// WAVEFORM_BARS is the number of bars in the waveform summary sent to clients.
+	WAVEFORM_BARS = 64
+)
+
+// Info ...
+type Info struct {
+	MimeType string
+	Duration time.Duration
+	Waveform []int
+}
+
+// Probe detects the container of a voice note, reads its duration and
+// builds a waveform summary. Decoding Opus or AAC needs native codecs, so
+// the waveform is computed from the compressed packet sizes instead: both
+// codecs are variable bitrate, and louder, busier audio takes more bytes.
+func Probe(data []byte) (*Info, error) {
+	var info *Info
+	var sizes []int
+	var err error
+
+	switch {
+	case bytes.HasPrefix(data, []byte("OggS")):
+		info, sizes, err = probeOpus(data)
+	case len(data) > 8 && bytes.Equal(data[4:8], []byte("ftyp")):
+		info, sizes, err = probeMP4(data)
+	default:
+		info, sizes, err = probeADTS(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Duration <= 0 {
+		return nil, util.GetError("invalid_audio")
+	}
+
+	info.Waveform = Waveform(sizes, WAVEFORM_BARS)
+	return info, nil
+}
+
+// Waveform averages packet sizes into at most bars buckets scaled to 0-100.
+func Waveform(sizes []int, bars int) []int {
+	if len(sizes) == 0 || bars <= 0 {
+		return []int{}
+	}
+	if len(sizes) < bars {
+		bars = len(sizes)
+	}
+
+	waveform := make([]int, bars)
+	peak := 0
+	for bar := 0; bar < bars; bar++ {
+		start := bar * len(sizes) / bars
+		end := (bar + 1) * len(sizes) / bars
+		total := 0
+		for _, size := range sizes[start:end] {
+			total += size
+		}
+		waveform[bar] = total / (end - start)
+		if waveform[bar] > peak {
+			peak = waveform[bar]
+		}
+	}
+	if peak == 0 {
+		return waveform
+	}
+	for bar := range waveform {
+		waveform[bar] = waveform[bar] * 100 / peak
+	}
+	return waveform
+}
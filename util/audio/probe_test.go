@@ -0,0 +1,170 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func oggPage(granule int64, packets ...[]byte) []byte {
+	page := new(bytes.Buffer)
+	page.WriteString("OggS")
+	page.Write([]byte{0, 0})
+	binary.Write(page, binary.LittleEndian, granule)
+	page.Write(make([]byte, 12))
+	var lacing []byte
+	for _, packet := range packets {
+		size := len(packet)
+		for ; size >= 255; size -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(size))
+	}
+	page.WriteByte(byte(len(lacing)))
+	page.Write(lacing)
+	for _, packet := range packets {
+		page.Write(packet)
+	}
+	return page.Bytes()
+}
+
+func mp4Box(kind string, body ...[]byte) []byte {
+	content := bytes.Join(body, nil)
+	b := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(b, uint32(8+len(content)))
+	copy(b[4:], kind)
+	return append(b, content...)
+}
+
+func Test_ProbeOpus(t *testing.T) {
+	head := append([]byte("OpusHead"), 1, 1)
+	head = binary.LittleEndian.AppendUint16(head, 312)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	data := oggPage(0, head)
+	data = append(data, oggPage(0, []byte("OpusTags........"))...)
+	data = append(data, oggPage(312+48000, make([]byte, 100), make([]byte, 300))...)
+	data = append(data, oggPage(312+96000, make([]byte, 50))...)
+
+	info, err := Probe(data)
+
+	assert.Nil(t, err)
+	assert.Equal(t, MIME_OPUS, info.MimeType)
+	assert.Equal(t, 2*time.Second, info.Duration)
+	assert.Equal(t, []int{33, 100, 16}, info.Waveform)
+}
+
+func Test_ProbeOpusHugeGranule(t *testing.T) {
+	head := append([]byte("OpusHead"), 1, 1, 0, 0)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	data := oggPage(0, head)
+	data = append(data, oggPage(0, []byte("OpusTags........"))...)
+	data = append(data, oggPage(math.MaxInt64, make([]byte, 100))...)
+	_, err := Probe(data)
+	assert.NotNil(t, err)
+
+	// the largest granule that still fits in a time.Duration.
+	data = oggPage(0, head)
+	data = append(data, oggPage(0, []byte("OpusTags........"))...)
+	data = append(data, oggPage(48000*(math.MaxInt64/int64(time.Second)-1), make([]byte, 100))...)
+	info, err := Probe(data)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(math.MaxInt64/int64(time.Second)-1)*time.Second, info.Duration)
+}
+
+func Test_ProbeADTS(t *testing.T) {
+	var data []byte
+	for index := 0; index < 43; index++ {
+		length := 7 + 10 + index
+		// MPEG-4, no CRC, AAC LC, 44.1kHz (index 4), mono, one raw block.
+		frame := []byte{0xFF, 0xF1, 0x50, 0x40 | byte(length>>11), byte(length >> 3), byte(length<<5) | 0x1F, 0xFC}
+		data = append(data, append(frame, make([]byte, length-7)...)...)
+	}
+
+	info, err := Probe(data)
+
+	assert.Nil(t, err)
+	assert.Equal(t, MIME_AAC, info.MimeType)
+	assert.Equal(t, time.Duration(43*1024)*time.Second/44100, info.Duration)
+	assert.Len(t, info.Waveform, 43)
+	assert.Equal(t, 100, info.Waveform[42])
+}
+
+func Test_ProbeM4A(t *testing.T) {
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint32(mdhd[12:], 44100)
+	binary.BigEndian.PutUint32(mdhd[16:], 44100*3)
+	hdlr := append(make([]byte, 8), []byte("soun")...)
+	stsz := make([]byte, 12)
+	binary.BigEndian.PutUint32(stsz[8:], 4)
+	for _, size := range []uint32{10, 20, 30, 40} {
+		stsz = binary.BigEndian.AppendUint32(stsz, size)
+	}
+
+	data := mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00isom"))
+	data = append(data, mp4Box("moov",
+		mp4Box("trak",
+			mp4Box("mdia",
+				mp4Box("mdhd", mdhd),
+				mp4Box("hdlr", hdlr),
+				mp4Box("minf", mp4Box("stbl", mp4Box("stsz", stsz))),
+			),
+		),
+	)...)
+
+	info, err := Probe(data)
+
+	assert.Nil(t, err)
+	assert.Equal(t, MIME_M4A, info.MimeType)
+	assert.Equal(t, 3*time.Second, info.Duration)
+	assert.Equal(t, []int{25, 50, 75, 100}, info.Waveform)
+}
+
+func Test_ProbeM4AOversizedBox(t *testing.T) {
+	moov := make([]byte, 16)
+	binary.BigEndian.PutUint32(moov, 1)
+	copy(moov[4:], "moov")
+	binary.BigEndian.PutUint64(moov[8:], 0x7FFFFFFFFFFFFFFF)
+	data := append(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00isom")), moov...)
+
+	_, err := Probe(data)
+	assert.NotNil(t, err)
+}
+
+func Test_ProbeM4ALongDuration(t *testing.T) {
+	mdhd := make([]byte, 36)
+	mdhd[0] = 1
+	binary.BigEndian.PutUint32(mdhd[20:], 1)
+	binary.BigEndian.PutUint64(mdhd[24:], 0xFFFFFFFFFFFFFFFF)
+
+	_, err := mediaDuration(mdhd)
+	assert.NotNil(t, err)
+}
+
+func Test_SampleSizesCount(t *testing.T) {
+	stsz := make([]byte, 12)
+	binary.BigEndian.PutUint32(stsz[8:], 0xFFFFFFFF)
+	stsz = binary.BigEndian.AppendUint32(stsz, 10)
+
+	sizes := sampleSizes(stsz)
+	assert.Equal(t, []int{10}, sizes)
+	assert.Equal(t, 1, cap(sizes))
+}
+
+func Test_ProbeRejectsOtherFiles(t *testing.T) {
+	_, err := Probe([]byte("\xFF\xD8\xFF\xE0 not audio at all"))
+	assert.NotNil(t, err)
+}
+
+func Test_Waveform(t *testing.T) {
+	assert.Equal(t, []int{}, Waveform(nil, 10))
+	assert.Len(t, Waveform(make([]int, 1000), WAVEFORM_BARS), WAVEFORM_BARS)
+	assert.Equal(t, []int{50, 100}, Waveform([]int{1, 1, 2, 2}, 2))
+}
@@ -0,0 +1,261 @@
+package chatexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// Writer streams a conversation into an export file one message at a time,
+// so large chats never have to be held in memory.
+type Writer interface {
+	WriteHeader(*entity.ChatExport, entity.MemberProfiles) error
+	WriteMessage(*entity.ChatMessage) error
+	Close() error
+}
+
+// Attachment ...
+type Attachment struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// Message ...
+type Message struct {
+	ID          string            `json:"id"`
+	Sender      string            `json:"sender"`
+	Receiver    string            `json:"receiver"`
+	Message     string            `json:"message"`
+	Attachments []Attachment      `json:"attachments"`
+	Reactions   []entity.Reaction `json:"reactions"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// Participant is what the JSON export keeps of a profile.
+type Participant struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	NickName    string `json:"nick_name"`
+}
+
+// NewWriter ...
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case entity.EXPORT_FORMAT_JSON:
+		return &jsonWriter{w: w}, nil
+	case entity.EXPORT_FORMAT_TEXT:
+		return &textWriter{w: w, names: map[string]string{}}, nil
+	case entity.EXPORT_FORMAT_HTML:
+		return &htmlWriter{w: w, names: map[string]string{}}, nil
+	}
+	return nil, util.GetError("invalid_export_format")
+}
+
+// NewMessage ...
+func NewMessage(message *entity.ChatMessage) Message {
+	return Message{
+		ID:          messageId(message),
+		Sender:      message.Sender,
+		Receiver:    message.Receiver,
+		Message:     message.Message,
+		Attachments: attachments(message),
+		Reactions:   entity.ReactionList(message),
+		CreatedAt:   message.CreatedAt,
+	}
+}
+
+// NewParticipants ...
+func NewParticipants(profiles entity.MemberProfiles) []Participant {
+	participants := make([]Participant, len(profiles))
+	for index, profile := range profiles {
+		participants[index] = Participant{
+			ID:          profile.ID,
+			DisplayName: profile.DisplayName,
+			NickName:    profile.NickName,
+		}
+	}
+	return participants
+}
+
+func messageId(message *entity.ChatMessage) string {
+	if message.Ref != "" {
+		return message.Ref
+	}
+	return message.ID
+}
+
+func attachments(message *entity.ChatMessage) []Attachment {
+	results := []Attachment{}
+	if message.Attachment != nil {
+		results = append(results, Attachment{
+			Type:  message.Type,
+			URL:   message.Attachment.URL,
+			Title: message.Attachment.MimeType,
+		})
+	}
+	if message.Preview != nil {
+		results = append(results, Attachment{
+			Type:  "link",
+			URL:   message.Preview.URL,
+			Title: message.Preview.Title,
+		})
+	}
+	return results
+}
+
+func displayNames(profiles entity.MemberProfiles) map[string]string {
+	names := make(map[string]string, len(profiles))
+	for _, profile := range profiles {
+		names[profile.ID] = profile.DisplayName
+	}
+	return names
+}
+
+// reactions spells out the reactions to message as "emoji name" pairs.
+func reactions(message *entity.ChatMessage, names map[string]string) []string {
+	results := []string{}
+	for _, reaction := range entity.ReactionList(message) {
+		results = append(results, fmt.Sprintf("%s %s", reaction.Emoji, names[reaction.Profile]))
+	}
+	return results
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (writer *jsonWriter) WriteHeader(export *entity.ChatExport, profiles entity.MemberProfiles) error {
+	participants, err := json.Marshal(NewParticipants(profiles))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer.w, `{"chat_id":%q,"exported_at":%q,"participants":%s,"messages":[`,
+		export.ChatId, util.GetTimeNow().Format(time.RFC3339), participants)
+	return err
+}
+
+func (writer *jsonWriter) WriteMessage(message *entity.ChatMessage) error {
+	value, err := json.Marshal(NewMessage(message))
+	if err != nil {
+		return err
+	}
+	if writer.count > 0 {
+		if _, err = io.WriteString(writer.w, ","); err != nil {
+			return err
+		}
+	}
+	writer.count++
+	_, err = writer.w.Write(value)
+	return err
+}
+
+func (writer *jsonWriter) Close() error {
+	_, err := io.WriteString(writer.w, "]}\n")
+	return err
+}
+
+type textWriter struct {
+	w     io.Writer
+	names map[string]string
+}
+
+func (writer *textWriter) WriteHeader(export *entity.ChatExport, profiles entity.MemberProfiles) error {
+	writer.names = displayNames(profiles)
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.DisplayName)
+	}
+	_, err := fmt.Fprintf(writer.w, "Chat with %s\nExported at %s\n\n",
+		strings.Join(names, ", "), util.GetTimeNow().Format(time.RFC1123))
+	return err
+}
+
+func (writer *textWriter) WriteMessage(message *entity.ChatMessage) error {
+	_, err := fmt.Fprintf(writer.w, "[%s] %s: %s\n",
+		message.CreatedAt.Format("2006-01-02 15:04:05"), writer.names[message.Sender], message.Message)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments(message) {
+		if _, err = fmt.Fprintf(writer.w, "    <%s: %s>\n", attachment.Type, attachment.URL); err != nil {
+			return err
+		}
+	}
+	if reacted := reactions(message, writer.names); len(reacted) > 0 {
+		if _, err = fmt.Fprintf(writer.w, "    <reactions: %s>\n", strings.Join(reacted, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (writer *textWriter) Close() error {
+	return nil
+}
+
+var htmlHeader = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Chat export</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Tahoma,sans-serif;background:#f4f4f4;margin:0;padding:24px}
+.chat{max-width:720px;margin:0 auto}
+.message{background:#fff;border-radius:8px;padding:8px 12px;margin:6px 0;unicode-bidi:plaintext}
+.sender{font-weight:bold;margin-right:8px}
+.time{color:#888;font-size:12px;float:right}
+.text{white-space:pre-wrap;margin-top:4px}
+.attachment{font-size:13px;margin-top:4px}
+.reactions{font-size:13px;color:#555;margin-top:4px}
+</style>
+</head>
+<body>
+<div class="chat">
+<h1>{{range $i, $p := .Participants}}{{if $i}}, {{end}}{{$p.DisplayName}}{{end}}</h1>
+<p class="time">Exported at {{.ExportedAt}}</p>
+`))
+
+var htmlMessage = template.Must(template.New("message").Parse(`<div class="message" id="{{.ID}}">
+<span class="sender">{{.SenderName}}</span><span class="time">{{.CreatedAt}}</span>
+<div class="text" dir="auto">{{.Message}}</div>
+{{range .Attachments}}<div class="attachment">{{.Type}}: <a href="{{.URL}}">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a></div>
+{{end}}{{if .Reactions}}<div class="reactions">{{range $i, $r := .Reactions}}{{if $i}}, {{end}}{{$r}}{{end}}</div>
+{{end}}</div>
+`))
+
+type htmlWriter struct {
+	w     io.Writer
+	names map[string]string
+}
+
+func (writer *htmlWriter) WriteHeader(export *entity.ChatExport, profiles entity.MemberProfiles) error {
+	writer.names = displayNames(profiles)
+	return htmlHeader.Execute(writer.w, map[string]interface{}{
+		"Participants": profiles,
+		"ExportedAt":   util.GetTimeNow().Format(time.RFC1123),
+	})
+}
+
+func (writer *htmlWriter) WriteMessage(message *entity.ChatMessage) error {
+	return htmlMessage.Execute(writer.w, map[string]interface{}{
+		"ID":          messageId(message),
+		"SenderName":  writer.names[message.Sender],
+		"Message":     message.Message,
+		"Attachments": attachments(message),
+		"Reactions":   reactions(message, writer.names),
+		"CreatedAt":   message.CreatedAt.Format("2006-01-02 15:04"),
+	})
+}
+
+func (writer *htmlWriter) Close() error {
+	_, err := io.WriteString(writer.w, "</div>\n</body>\n</html>\n")
+	return err
+}
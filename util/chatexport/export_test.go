@@ -0,0 +1,82 @@
+package chatexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportFixture() (*entity.ChatExport, entity.MemberProfiles, []entity.ChatMessage) {
+	var export entity.ChatExport
+	sender, receiver := util.ULID(), util.ULID()
+	export.PrepareChatExport(sender, receiver, entity.EXPORT_FORMAT_JSON)
+	profiles := entity.MemberProfiles{
+		{ID: sender, DisplayName: "Majid", NickName: "majid", Member: util.ULID(), Private: true},
+		{ID: receiver, DisplayName: "<b>Sara</b>", NickName: "sara"},
+	}
+	messages := []entity.ChatMessage{
+		{ID: util.ULID(), Sender: sender, Receiver: receiver, Message: "hello", CreatedAt: util.GetTimeNow(),
+			Reactions: map[string]string{receiver: "👍", sender: "❤"}},
+		{ID: util.ULID(), Sender: receiver, Receiver: sender, Message: "<script>alert(1)</script> https://example.com",
+			Preview: &linkpreview.Preview{URL: "https://example.com", Title: "Example"}, CreatedAt: util.GetTimeNow()},
+	}
+	return &export, profiles, messages
+}
+
+func writeExport(t *testing.T, format string) string {
+	export, profiles, messages := exportFixture()
+	buffer := new(bytes.Buffer)
+	writer, err := NewWriter(format, buffer)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteHeader(export, profiles))
+	for index := range messages {
+		assert.Nil(t, writer.WriteMessage(&messages[index]))
+	}
+	assert.Nil(t, writer.Close())
+	return buffer.String()
+}
+
+func Test_JSONExport(t *testing.T) {
+	var data struct {
+		Participants []map[string]interface{} `json:"participants"`
+		Messages     []Message                `json:"messages"`
+	}
+	err := json.Unmarshal([]byte(writeExport(t, entity.EXPORT_FORMAT_JSON)), &data)
+
+	assert.Nil(t, err)
+	assert.Len(t, data.Participants, 2)
+	assert.Equal(t, map[string]interface{}{"id": data.Messages[0].Sender, "display_name": "Majid", "nick_name": "majid"}, data.Participants[0])
+	assert.Len(t, data.Messages, 2)
+	assert.Len(t, data.Messages[0].Reactions, 2)
+	assert.Empty(t, data.Messages[1].Reactions)
+	assert.Equal(t, "https://example.com", data.Messages[1].Attachments[0].URL)
+}
+
+func Test_HTMLExportEscapesContent(t *testing.T) {
+	html := writeExport(t, entity.EXPORT_FORMAT_HTML)
+
+	assert.False(t, strings.Contains(html, "<script>"))
+	assert.True(t, strings.Contains(html, "&lt;b&gt;Sara&lt;/b&gt;"))
+	assert.True(t, strings.Contains(html, `<div class="reactions">`))
+	assert.True(t, strings.HasSuffix(html, "</html>\n"))
+}
+
+func Test_TextExport(t *testing.T) {
+	text := writeExport(t, entity.EXPORT_FORMAT_TEXT)
+
+	assert.True(t, strings.Contains(text, "Majid: hello"))
+	assert.True(t, strings.Contains(text, "<link: https://example.com>"))
+	assert.True(t, strings.Contains(text, "<reactions: ") && strings.Contains(text, "👍 <b>Sara</b>") && strings.Contains(text, "❤ Majid"))
+}
+
+func Test_InvalidFormat(t *testing.T) {
+	_, err := NewWriter("pdf", new(bytes.Buffer))
+	assert.NotNil(t, err)
+}
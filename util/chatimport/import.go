@@ -0,0 +1,214 @@
+package chatimport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/moderation"
+)
+
+// Participants maps the author names used in an export to profile ids.
+type Participants struct {
+	Owner        string
+	OwnerName    string
+	Receiver     string
+	ReceiverName string
+}
+
+// Result ...
+type Result struct {
+	Parsed   int `json:"parsed"`
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Rejected int `json:"rejected"`
+}
+
+// Importer ...
+type Importer struct {
+	Chat       repository.ChatRepository
+	Moderation *moderation.Pipeline
+	Reviews    repository.ModerationRepository
+}
+
+// NewImporter ...
+func NewImporter(chat repository.ChatRepository, pipeline *moderation.Pipeline, reviews repository.ModerationRepository) *Importer {
+	return &Importer{
+		Chat:       chat,
+		Moderation: pipeline,
+		Reviews:    reviews,
+	}
+}
+
+// Import parses an export and writes the conversation, then every message
+// once. Message ids are derived from the content and timestamps, so
+// running the same import twice does not duplicate anything. Messages go
+// through the moderation pipeline like live ones: rejected messages are left
+// out and flagged ones are queued for review.
+func (importer *Importer) Import(source string, r io.Reader, participants Participants, location *time.Location) (*Result, error) {
+	export, err := Parse(source, r, location)
+	if err != nil {
+		return nil, err
+	}
+	err = participants.Resolve(export)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, skipped := BuildChatMessages(export, participants)
+	messages, reviews, rejected := importer.moderate(messages)
+	result := &Result{Parsed: len(export.Messages), Skipped: skipped, Rejected: rejected}
+	if len(messages) == 0 {
+		return result, nil
+	}
+
+	last := messages[len(messages)-1]
+	var conversation entity.Conversation
+	conversation.PrepareConversation(participants.Owner, participants.Receiver)
+	conversation.LastMessageId = last.ID
+	conversation.LastMessage = last.RoomMessage()
+	conversation.LastSender = last.Sender
+	conversation.CreatedAt = messages[0].CreatedAt
+	conversation.UpdatedAt = last.CreatedAt
+	err = importer.Chat.ImportConversation(&conversation)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := importer.Chat.ImportChatMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(inserted)
+
+	reviews = insertedReviews(reviews, inserted)
+	for index := range reviews {
+		if err := importer.Reviews.AddModerationReview(&reviews[index]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// insertedReviews keeps the reviews of the messages this import inserted.
+// Messages an earlier run stored were queued for review by that run.
+func insertedReviews(reviews []entity.ModerationReview, inserted []string) []entity.ModerationReview {
+	fresh := make(map[string]bool, len(inserted))
+	for _, ID := range inserted {
+		fresh[ID] = true
+	}
+	kept := make([]entity.ModerationReview, 0, len(reviews))
+	for _, review := range reviews {
+		if fresh[review.Ref] {
+			kept = append(kept, review)
+		}
+	}
+	return kept
+}
+
+// moderate runs messages through the pipeline. It returns the messages to
+// import, redacted where the pipeline said so, the reviews of the flagged
+// ones and how many were rejected.
+func (importer *Importer) moderate(messages entity.ChatMessageHistory) (entity.ChatMessageHistory, []entity.ModerationReview, int) {
+	var kept entity.ChatMessageHistory
+	var reviews []entity.ModerationReview
+	rejected := 0
+	for _, message := range messages {
+		decision := importer.Moderation.Run(&message)
+		if decision.Rejected() {
+			rejected++
+			continue
+		}
+		if decision.Flagged() {
+			var review entity.ModerationReview
+			review.PrepareModerationReview(&message, decision.Original, decision.Reasons)
+			reviews = append(reviews, review)
+		}
+		kept = append(kept, message)
+	}
+	return kept, reviews, rejected
+}
+
+// Resolve fills in missing author names. When the export has exactly two
+// authors and only one side is named (or the export names the other party),
+// the remaining author is assigned to the other profile.
+func (participants *Participants) Resolve(export *Export) error {
+	authors := export.Authors()
+	if participants.ReceiverName == "" && participants.OwnerName == "" && export.Name != "" {
+		participants.ReceiverName = export.Name
+	}
+	if len(authors) == 2 {
+		for index, author := range authors {
+			other := authors[1-index]
+			if author == participants.OwnerName && participants.ReceiverName == "" {
+				participants.ReceiverName = other
+			}
+			if author == participants.ReceiverName && participants.OwnerName == "" {
+				participants.OwnerName = other
+			}
+		}
+	}
+
+	if participants.OwnerName == "" || participants.ReceiverName == "" || participants.OwnerName == participants.ReceiverName {
+		return util.GetError("import_participants_unmapped")
+	}
+	found := 0
+	for _, author := range authors {
+		if author == participants.OwnerName || author == participants.ReceiverName {
+			found++
+		}
+	}
+	if found == 0 {
+		return util.GetError("import_participants_unmapped")
+	}
+	return nil
+}
+
+// BuildChatMessages turns parsed messages into chat documents, one per
+// message. Messages from authors that are not mapped to either profile are
+// skipped.
+func BuildChatMessages(export *Export, participants Participants) (entity.ChatMessageHistory, int) {
+	var messages entity.ChatMessageHistory
+	skipped := 0
+	occurrences := make(map[string]int)
+	conversation := entity.ConversationKey(participants.Owner, participants.Receiver)
+
+	for _, imported := range export.Messages {
+		var sender, receiver string
+		switch imported.Author {
+		case participants.OwnerName:
+			sender, receiver = participants.Owner, participants.Receiver
+		case participants.ReceiverName:
+			sender, receiver = participants.Receiver, participants.Owner
+		default:
+			skipped++
+			continue
+		}
+
+		sentAt := imported.SentAt.UTC()
+		key := strings.Join([]string{conversation, sender, sentAt.Format(time.RFC3339Nano), imported.Text}, "\x00")
+		occurrences[key]++
+		id := util.ULIDFromTime(sentAt, fmt.Sprintf("%s\x00%d", key, occurrences[key]))
+
+		messages = append(messages, entity.ChatMessage{
+			ID:           id,
+			Ref:          id,
+			Conversation: conversation,
+			Type:         entity.MESSAGE_TEXT,
+			Sender:       sender,
+			Receiver:     receiver,
+			Message:      imported.Text,
+			CreatedAt:    sentAt,
+		})
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, skipped
+}
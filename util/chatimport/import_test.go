@@ -0,0 +1,41 @@
+package chatimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util/moderation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ModerateImportedMessages(t *testing.T) {
+	words := moderation.NewWordList()
+	assert.Nil(t, words.Load(strings.NewReader("darn\n?scam\n!slur")))
+	importer := NewImporter(nil, moderation.NewPipeline(words), nil)
+
+	messages := entity.ChatMessageHistory{
+		{ID: "1", Sender: "majid", Message: "hello"},
+		{ID: "2", Sender: "sara", Message: "darn, a scam"},
+		{ID: "3", Sender: "sara", Message: "a slur"},
+	}
+	kept, reviews, rejected := importer.moderate(messages)
+
+	assert.Equal(t, 1, rejected)
+	assert.Len(t, kept, 2)
+	assert.Equal(t, "hello", kept[0].Message)
+	assert.Equal(t, "****, a scam", kept[1].Message)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "darn, a scam", reviews[0].Original)
+}
+
+func Test_ReviewOnlyInsertedMessages(t *testing.T) {
+	reviews := []entity.ModerationReview{{Ref: "1"}, {Ref: "2"}, {Ref: "3"}}
+
+	assert.Empty(t, insertedReviews(reviews, nil))
+	kept := insertedReviews(reviews, []string{"3", "1"})
+	assert.Len(t, kept, 2)
+	assert.Equal(t, "1", kept[0].Ref)
+	assert.Equal(t, "3", kept[1].Ref)
+}
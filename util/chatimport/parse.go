@@ -0,0 +1,234 @@
+package chatimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+const (
+	// SOURCE_WHATSAPP ...
+	SOURCE_WHATSAPP = "whatsapp"
+	// SOURCE_TELEGRAM ...
+	SOURCE_TELEGRAM = "telegram"
+)
+
+var (
+	// [31/12/2020, 23:59:59] Name: text   (iOS)
+	// 31/12/2020, 23:59 - Name: text      (Android)
+	// 12/31/20, 11:59 PM - Name: text     (Android, US locale)
+	whatsAppLineRegex = regexp.MustCompile(`^\[?(\d{1,2})[/.-](\d{1,2})[/.-](\d{2,4}),?\s+(\d{1,2})[:.](\d{2})(?:[:.](\d{2}))?\s*([AaPp]\.?\s?[Mm]\.?)?\]?\s*(?:-\s*)?(.*)$`)
+	invisibleChars    = strings.NewReplacer("\u200e", "", "\u200f", "", "\ufeff", "", "\u202f", " ", "\u00a0", " ")
+)
+
+// Message ...
+type Message struct {
+	Author string
+	Text   string
+	SentAt time.Time
+}
+
+// Export ...
+type Export struct {
+	// Name is the chat title found in the export, when the format has one.
+	Name     string
+	Messages []Message
+}
+
+// Authors ...
+func (export *Export) Authors() []string {
+	seen := make(map[string]bool)
+	authors := []string{}
+	for _, message := range export.Messages {
+		if !seen[message.Author] {
+			seen[message.Author] = true
+			authors = append(authors, message.Author)
+		}
+	}
+	return authors
+}
+
+// Parse ...
+func Parse(source string, r io.Reader, location *time.Location) (*Export, error) {
+	switch source {
+	case SOURCE_WHATSAPP:
+		return ParseWhatsApp(r, location)
+	case SOURCE_TELEGRAM:
+		return ParseTelegram(r, location)
+	}
+	return nil, util.GetError("invalid_import_source")
+}
+
+type whatsAppHeader struct {
+	first, second, year int
+	hour, minute, sec   int
+	meridiem            string
+	author, text        string
+}
+
+// ParseWhatsApp parses the .txt file produced by WhatsApp's "Export chat".
+// The day/month order depends on the phone locale, so it is inferred from
+// the whole file before any timestamp is built.
+func ParseWhatsApp(r io.Reader, location *time.Location) (*Export, error) {
+	var headers []*whatsAppHeader
+	var current *whatsAppHeader
+	dayFirst, monthFirst := false, false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := invisibleChars.Replace(scanner.Text())
+		match := whatsAppLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			if current != nil {
+				current.text += "\n" + line
+			}
+			continue
+		}
+
+		header := &whatsAppHeader{meridiem: strings.ToLower(strings.NewReplacer(".", "", " ", "").Replace(match[7]))}
+		header.first, _ = strconv.Atoi(match[1])
+		header.second, _ = strconv.Atoi(match[2])
+		header.year, _ = strconv.Atoi(match[3])
+		header.hour, _ = strconv.Atoi(match[4])
+		header.minute, _ = strconv.Atoi(match[5])
+		header.sec, _ = strconv.Atoi(match[6])
+
+		// system lines ("Messages are end-to-end encrypted") have no author.
+		author, text, ok := strings.Cut(match[8], ": ")
+		if !ok || strings.TrimSpace(author) == "" {
+			current = nil
+			continue
+		}
+		header.author = strings.TrimSpace(author)
+		header.text = text
+
+		if header.first > 12 {
+			dayFirst = true
+		}
+		if header.second > 12 {
+			monthFirst = true
+		}
+		headers = append(headers, header)
+		current = header
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, util.GetError("invalid_import_file")
+	}
+	if len(headers) == 0 || (dayFirst && monthFirst) {
+		return nil, util.GetError("invalid_import_file")
+	}
+
+	export := &Export{}
+	for _, header := range headers {
+		day, month := header.first, header.second
+		if monthFirst {
+			day, month = header.second, header.first
+		}
+		year := header.year
+		if year < 100 {
+			year += 2000
+		}
+		hour := header.hour
+		switch {
+		case header.meridiem == "pm" && hour < 12:
+			hour += 12
+		case header.meridiem == "am" && hour == 12:
+			hour = 0
+		}
+		if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 {
+			return nil, util.GetError("invalid_import_file")
+		}
+
+		export.Messages = append(export.Messages, Message{
+			Author: header.author,
+			Text:   strings.TrimRight(header.text, "\n"),
+			SentAt: time.Date(year, time.Month(month), day, hour, header.minute, header.sec, 0, location),
+		})
+	}
+	return export, nil
+}
+
+type telegramExport struct {
+	Name     string `json:"name"`
+	Messages []struct {
+		Type         string          `json:"type"`
+		Date         string          `json:"date"`
+		DateUnixtime string          `json:"date_unixtime"`
+		From         string          `json:"from"`
+		Text         json.RawMessage `json:"text"`
+	} `json:"messages"`
+}
+
+// ParseTelegram parses the result.json file produced by Telegram Desktop's
+// "Export chat history" in machine-readable JSON.
+func ParseTelegram(r io.Reader, location *time.Location) (*Export, error) {
+	var data telegramExport
+	err := json.NewDecoder(r).Decode(&data)
+	if err != nil {
+		return nil, util.GetError("invalid_import_file")
+	}
+
+	export := &Export{Name: data.Name}
+	for _, message := range data.Messages {
+		if message.Type != "message" || message.From == "" {
+			continue
+		}
+
+		var sentAt time.Time
+		if seconds, err := strconv.ParseInt(message.DateUnixtime, 10, 64); err == nil {
+			sentAt = time.Unix(seconds, 0).In(location)
+		} else if sentAt, err = time.ParseInLocation("2006-01-02T15:04:05", message.Date, location); err != nil {
+			return nil, util.GetError("invalid_import_file")
+		}
+
+		text := telegramText(message.Text)
+		if text == "" {
+			continue
+		}
+		export.Messages = append(export.Messages, Message{
+			Author: message.From,
+			Text:   text,
+			SentAt: sentAt,
+		})
+	}
+	if len(export.Messages) == 0 {
+		return nil, util.GetError("invalid_import_file")
+	}
+	return export, nil
+}
+
+// telegramText flattens Telegram's text field, which is either a plain
+// string or a list of strings and formatted entities.
+func telegramText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var builder strings.Builder
+	for _, part := range parts {
+		var plain string
+		if err := json.Unmarshal(part, &plain); err == nil {
+			builder.WriteString(plain)
+			continue
+		}
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &entity); err == nil {
+			builder.WriteString(entity.Text)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,80 @@
+package chatimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const whatsAppAndroid = `12/31/20, 11:58 PM - Messages and calls are end-to-end encrypted.
+12/31/20, 11:59 PM - Majid: happy new year
+see you tomorrow
+1/1/21, 12:00 AM - Sara: same to you!
+`
+
+const whatsAppIOS = "‎[31/12/2020, 23:59:01] Majid: مرحبا\n[01/01/2021, 00:00:30] Sara: ‎<Media omitted>\n"
+
+const telegram = `{
+  "name": "Sara",
+  "type": "personal_chat",
+  "messages": [
+    {"id": 1, "type": "service", "date": "2021-01-01T10:00:00", "actor": "Sara", "action": "phone_call"},
+    {"id": 2, "type": "message", "date": "2021-01-01T10:00:05", "date_unixtime": "1609495205", "from": "Majid", "text": "hello"},
+    {"id": 3, "type": "message", "date": "2021-01-01T10:01:00", "from": "Sara", "text": ["see ", {"type": "link", "text": "https://example.com"}]}
+  ]
+}`
+
+func Test_ParseWhatsAppAndroid(t *testing.T) {
+	export, err := ParseWhatsApp(strings.NewReader(whatsAppAndroid), time.UTC)
+
+	assert.Nil(t, err)
+	assert.Len(t, export.Messages, 2)
+	assert.Equal(t, "Majid", export.Messages[0].Author)
+	assert.Equal(t, "happy new year\nsee you tomorrow", export.Messages[0].Text)
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 59, 0, 0, time.UTC), export.Messages[0].SentAt)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), export.Messages[1].SentAt)
+}
+
+func Test_ParseWhatsAppIOS(t *testing.T) {
+	export, err := ParseWhatsApp(strings.NewReader(whatsAppIOS), time.UTC)
+
+	assert.Nil(t, err)
+	assert.Len(t, export.Messages, 2)
+	assert.Equal(t, "مرحبا", export.Messages[0].Text)
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 59, 1, 0, time.UTC), export.Messages[0].SentAt)
+	assert.Equal(t, "<Media omitted>", export.Messages[1].Text)
+}
+
+func Test_ParseTelegram(t *testing.T) {
+	export, err := ParseTelegram(strings.NewReader(telegram), time.UTC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Sara", export.Name)
+	assert.Len(t, export.Messages, 2)
+	assert.Equal(t, time.Unix(1609495205, 0).UTC(), export.Messages[0].SentAt)
+	assert.Equal(t, "see https://example.com", export.Messages[1].Text)
+}
+
+func Test_BuildChatMessagesIsDeterministic(t *testing.T) {
+	owner, receiver := util.ULID(), util.ULID()
+	export, _ := ParseTelegram(strings.NewReader(telegram), time.UTC)
+	participants := Participants{Owner: owner, Receiver: receiver}
+	assert.Nil(t, participants.Resolve(export))
+	assert.Equal(t, "Majid", participants.OwnerName)
+
+	first, skipped := BuildChatMessages(export, participants)
+	second, _ := BuildChatMessages(export, participants)
+
+	assert.Equal(t, 0, skipped)
+	assert.Len(t, first, 2)
+	assert.Equal(t, first, second)
+	assert.Equal(t, first[0].ID, first[0].Ref)
+	assert.NotEqual(t, first[0].ID, first[1].ID)
+	assert.Equal(t, owner, first[0].Sender)
+	assert.Equal(t, entity.ConversationKey(owner, receiver), first[1].Conversation)
+}
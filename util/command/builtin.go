@@ -0,0 +1,111 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// Muter stores whether a member muted a conversation. A nil until mutes it
+// until it is unmuted.
+type Muter interface {
+	MuteChat(sender, receiver string, until *time.Time) error
+	UnmuteChat(sender, receiver string) error
+}
+
+// NewHelp lists the commands of registry, or shows the usage of one.
+func NewHelp(registry *Registry) *Command {
+	return &Command{
+		Name:        "help",
+		Description: "List the available commands, or show how to use one.",
+		Args:        []Arg{{Name: "command", Type: ARG_STRING, Optional: true}},
+		Handler: func(invocation *Invocation) (*Reply, error) {
+			if invocation.Has("command") {
+				command, ok := registry.Lookup(invocation.String("command"))
+				if !ok {
+					return nil, util.GetError("unknown_command")
+				}
+				return Ephemeral(fmt.Sprintf("%s\n%s", command.Usage(), command.Description)), nil
+			}
+
+			lines := make([]string, 0)
+			for _, command := range registry.Commands() {
+				lines = append(lines, fmt.Sprintf("%s - %s", command.Usage(), command.Description))
+			}
+			return Ephemeral(strings.Join(lines, "\n")), nil
+		},
+	}
+}
+
+// NewMute mutes the conversation for the sender, for a while or until
+// /unmute. Muted conversations are left out of the unread counter.
+func NewMute(store Muter) *Command {
+	return &Command{
+		Name:        "mute",
+		Description: "Mute this conversation, e.g. /mute 8h. Without a duration it stays muted until /unmute.",
+		Args:        []Arg{{Name: "duration", Type: ARG_DURATION, Optional: true}},
+		Handler: func(invocation *Invocation) (*Reply, error) {
+			var until *time.Time
+			if invocation.Has("duration") {
+				end := util.GetTimeNow().Add(invocation.Duration("duration"))
+				until = &end
+			}
+			err := store.MuteChat(invocation.Sender, invocation.Receiver, until)
+			if err != nil {
+				return nil, err
+			}
+			if until == nil {
+				return Ephemeral("This conversation is muted until you /unmute it."), nil
+			}
+			return Ephemeral(fmt.Sprintf("This conversation is muted until %s.", until.Format("2006-01-02 15:04 MST"))), nil
+		},
+	}
+}
+
+// NewUnmute ...
+func NewUnmute(store Muter) *Command {
+	return &Command{
+		Name:        "unmute",
+		Description: "Unmute this conversation.",
+		Handler: func(invocation *Invocation) (*Reply, error) {
+			err := store.UnmuteChat(invocation.Sender, invocation.Receiver)
+			if err != nil {
+				return nil, err
+			}
+			return Ephemeral("This conversation is no longer muted."), nil
+		},
+	}
+}
+
+// NewPoll starts a poll in the conversation. --multiple and --anonymous can
+// appear anywhere among the options.
+func NewPoll() *Command {
+	return &Command{
+		Name:        "poll",
+		Description: `Start a poll, e.g. /poll "Lunch?" pizza sushi --multiple. Quote anything with spaces.`,
+		Args: []Arg{
+			{Name: "question", Type: ARG_STRING},
+			{Name: "options", Type: ARG_LIST},
+		},
+		Handler: func(invocation *Invocation) (*Reply, error) {
+			request := &entity.PollRequest{Question: invocation.String("question")}
+			for _, option := range invocation.Strings("options") {
+				switch option {
+				case "--multiple":
+					request.Multiple = true
+				case "--anonymous":
+					request.Anonymous = true
+				default:
+					request.Options = append(request.Options, option)
+				}
+			}
+			if err := request.ValidatePollRequest(); err != nil {
+				return nil, err
+			}
+			return &Reply{Visibility: REPLY_PUBLIC, Text: request.Question, Poll: request}, nil
+		},
+	}
+}
@@ -0,0 +1,294 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// argument types
+const (
+	// ARG_STRING is one word, or a "quoted phrase".
+	ARG_STRING = "string"
+	// ARG_DURATION is a Go duration such as 90m, or a number of days such as 2d.
+	ARG_DURATION = "duration"
+	// ARG_LIST takes every word left. It must be the last argument.
+	ARG_LIST = "list"
+)
+
+// reply visibility
+const (
+	// REPLY_EPHEMERAL is only shown to the sender and never stored.
+	REPLY_EPHEMERAL = "ephemeral"
+	// REPLY_PUBLIC is posted to the conversation as the sender's message.
+	REPLY_PUBLIC = "public"
+)
+
+// Arg describes one argument of a command.
+type Arg struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
+// Handler runs a command once its arguments were parsed.
+type Handler func(invocation *Invocation) (*Reply, error)
+
+// Command is a chat command such as /help.
+type Command struct {
+	Name        string
+	Description string
+	Args        []Arg
+	Handler     Handler
+}
+
+// Usage renders the command and its arguments, e.g. /poll <question> <options...>.
+func (command *Command) Usage() string {
+	usage := "/" + command.Name
+	for _, arg := range command.Args {
+		name := arg.Name
+		if arg.Type == ARG_LIST {
+			name += "..."
+		}
+		if arg.Optional {
+			usage += " [" + name + "]"
+		} else {
+			usage += " <" + name + ">"
+		}
+	}
+	return usage
+}
+
+// Reply is what a command answers with. Registry.Run fills in Command. A
+// public reply with a Poll is posted as a poll instead of a text message.
+type Reply struct {
+	Command    string
+	Visibility string
+	Text       string
+	Poll       *entity.PollRequest
+}
+
+// Ephemeral ...
+func Ephemeral(text string) *Reply {
+	return &Reply{Visibility: REPLY_EPHEMERAL, Text: text}
+}
+
+// Public ...
+func Public(text string) *Reply {
+	return &Reply{Visibility: REPLY_PUBLIC, Text: text}
+}
+
+// Invocation is one run of a command: who sent it, in which conversation,
+// and its parsed arguments.
+type Invocation struct {
+	Command  *Command
+	Sender   string
+	Receiver string
+	values   map[string]interface{}
+}
+
+// Has tells whether an optional argument was given.
+func (invocation *Invocation) Has(name string) bool {
+	_, ok := invocation.values[name]
+	return ok
+}
+
+// String ...
+func (invocation *Invocation) String(name string) string {
+	value, _ := invocation.values[name].(string)
+	return value
+}
+
+// Duration ...
+func (invocation *Invocation) Duration(name string) time.Duration {
+	value, _ := invocation.values[name].(time.Duration)
+	return value
+}
+
+// Strings ...
+func (invocation *Invocation) Strings(name string) []string {
+	value, _ := invocation.values[name].([]string)
+	return value
+}
+
+// IsCommand tells whether text should go to the registry. A leading double
+// slash escapes the command, see Unescape.
+func IsCommand(text string) bool {
+	return strings.HasPrefix(text, "/") && !strings.HasPrefix(text, "//")
+}
+
+// Unescape turns "//text" into "/text" so members can still send messages
+// that start with a slash.
+func Unescape(text string) string {
+	if strings.HasPrefix(text, "//") {
+		return text[1:]
+	}
+	return text
+}
+
+// Registry holds the commands members can run from the chat box.
+type Registry struct {
+	sync.RWMutex
+	commands map[string]*Command
+}
+
+// NewRegistry returns a registry holding /help and commands.
+func NewRegistry(commands ...*Command) *Registry {
+	registry := &Registry{
+		commands: make(map[string]*Command),
+	}
+	registry.Register(NewHelp(registry))
+	for _, command := range commands {
+		registry.Register(command)
+	}
+	return registry
+}
+
+// Register adds command, replacing any command with the same name.
+func (registry *Registry) Register(command *Command) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.commands[strings.ToLower(command.Name)] = command
+}
+
+// Lookup ...
+func (registry *Registry) Lookup(name string) (*Command, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	command, ok := registry.commands[strings.ToLower(strings.TrimPrefix(name, "/"))]
+	return command, ok
+}
+
+// Commands returns every command sorted by name.
+func (registry *Registry) Commands() []*Command {
+	registry.RLock()
+	commands := make([]*Command, 0, len(registry.commands))
+	for _, command := range registry.commands {
+		commands = append(commands, command)
+	}
+	registry.RUnlock()
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Run parses text against the schema of the command it names and runs the
+// command's handler.
+func (registry *Registry) Run(sender, receiver, text string) (*Reply, error) {
+	words, err := split(strings.TrimPrefix(text, "/"))
+	if err != nil || len(words) == 0 {
+		return nil, util.GetError("unknown_command")
+	}
+	command, ok := registry.Lookup(words[0])
+	if !ok {
+		return nil, util.GetError("unknown_command")
+	}
+
+	values, err := parse(command.Args, words[1:])
+	if err != nil {
+		return nil, err
+	}
+	reply, err := command.Handler(&Invocation{
+		Command:  command,
+		Sender:   sender,
+		Receiver: receiver,
+		values:   values,
+	})
+	if reply != nil {
+		reply.Command = command.Name
+	}
+	return reply, err
+}
+
+func parse(args []Arg, words []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, arg := range args {
+		if arg.Type == ARG_LIST {
+			if len(words) == 0 && !arg.Optional {
+				return nil, util.GetError("invalid_command_arguments")
+			}
+			if len(words) > 0 {
+				values[arg.Name] = words
+			}
+			words = nil
+			break
+		}
+		if len(words) == 0 {
+			if !arg.Optional {
+				return nil, util.GetError("invalid_command_arguments")
+			}
+			continue
+		}
+
+		switch arg.Type {
+		case ARG_DURATION:
+			duration, err := parseDuration(words[0])
+			if err != nil {
+				return nil, util.GetError("invalid_command_arguments")
+			}
+			values[arg.Name] = duration
+		default:
+			values[arg.Name] = words[0]
+		}
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		return nil, util.GetError("invalid_command_arguments")
+	}
+	return values, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	var err error
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		var count int
+		count, err = strconv.Atoi(days)
+		duration = time.Duration(count) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(value)
+	}
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf("duration must be positive: %s", value)
+	}
+	return duration, err
+}
+
+// split breaks text into words on spaces. Double quotes group words into one.
+func split(text string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	quoted, started := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				words = append(words, word.String())
+				word.Reset()
+				started = false
+			}
+		default:
+			word.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, util.GetError("invalid_command_arguments")
+	}
+	if started {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
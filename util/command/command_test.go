@@ -0,0 +1,113 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryMuter struct {
+	muted map[string]*time.Time
+}
+
+func (muter *memoryMuter) MuteChat(sender, receiver string, until *time.Time) error {
+	muter.muted[sender+"-"+receiver] = until
+	return nil
+}
+
+func (muter *memoryMuter) UnmuteChat(sender, receiver string) error {
+	delete(muter.muted, sender+"-"+receiver)
+	return nil
+}
+
+func Test_IsCommandAndUnescape(t *testing.T) {
+	assert.True(t, IsCommand("/help"))
+	assert.False(t, IsCommand("//help"))
+	assert.False(t, IsCommand("hello /help"))
+	assert.Equal(t, "/help", Unescape("//help"))
+	assert.Equal(t, "/help", Unescape("/help"))
+}
+
+func Test_SplitKeepsQuotedWords(t *testing.T) {
+	words, err := split(`poll "Lunch today?"  pizza "fish and chips"`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"poll", "Lunch today?", "pizza", "fish and chips"}, words)
+
+	_, err = split(`poll "Lunch`)
+	assert.NotNil(t, err)
+}
+
+func Test_RunChecksTheArgumentSchema(t *testing.T) {
+	registry := NewRegistry(&Command{
+		Name: "echo",
+		Args: []Arg{{Name: "word", Type: ARG_STRING}, {Name: "for", Type: ARG_DURATION, Optional: true}},
+		Handler: func(invocation *Invocation) (*Reply, error) {
+			return Public(invocation.String("word") + " " + invocation.Duration("for").String()), nil
+		},
+	})
+
+	reply, err := registry.Run("a", "b", "/ECHO hi 2d")
+	assert.Nil(t, err)
+	assert.Equal(t, REPLY_PUBLIC, reply.Visibility)
+	assert.Equal(t, "echo", reply.Command)
+	assert.Equal(t, "hi 48h0m0s", reply.Text)
+
+	_, err = registry.Run("a", "b", "/echo")
+	assert.EqualError(t, err, "invalid_command_arguments")
+	_, err = registry.Run("a", "b", "/echo hi soon")
+	assert.EqualError(t, err, "invalid_command_arguments")
+	_, err = registry.Run("a", "b", "/echo hi 1h extra")
+	assert.EqualError(t, err, "invalid_command_arguments")
+	_, err = registry.Run("a", "b", "/shrug")
+	assert.EqualError(t, err, "unknown_command")
+}
+
+func Test_HelpListsCommands(t *testing.T) {
+	registry := NewRegistry(NewPoll())
+
+	reply, err := registry.Run("a", "b", "/help")
+	assert.Nil(t, err)
+	assert.Equal(t, REPLY_EPHEMERAL, reply.Visibility)
+	assert.Contains(t, reply.Text, "/help [command]")
+	assert.Contains(t, reply.Text, "/poll <question> <options...>")
+
+	reply, err = registry.Run("a", "b", "/help /poll")
+	assert.Nil(t, err)
+	assert.Contains(t, reply.Text, "/poll <question> <options...>")
+}
+
+func Test_PollStartsAPoll(t *testing.T) {
+	registry := NewRegistry(NewPoll())
+
+	reply, err := registry.Run("a", "b", `/poll " Lunch? " pizza --anonymous "fish and chips"`)
+	assert.Nil(t, err)
+	assert.Equal(t, REPLY_PUBLIC, reply.Visibility)
+	assert.Equal(t, "Lunch?", reply.Text)
+	assert.Equal(t, []string{"pizza", "fish and chips"}, reply.Poll.Options)
+	assert.True(t, reply.Poll.Anonymous)
+	assert.False(t, reply.Poll.Multiple)
+
+	_, err = registry.Run("a", "b", `/poll "Lunch?" pizza --multiple`)
+	assert.EqualError(t, err, "invalid_poll_options")
+}
+
+func Test_MuteAndUnmute(t *testing.T) {
+	muter := &memoryMuter{muted: map[string]*time.Time{}}
+	registry := NewRegistry(NewMute(muter), NewUnmute(muter))
+
+	reply, err := registry.Run("a", "b", "/mute")
+	assert.Nil(t, err)
+	assert.Equal(t, REPLY_EPHEMERAL, reply.Visibility)
+	until, ok := muter.muted["a-b"]
+	assert.True(t, ok)
+	assert.Nil(t, until)
+
+	_, err = registry.Run("a", "b", "/mute 8h")
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(8*time.Hour), *muter.muted["a-b"], time.Minute)
+
+	_, err = registry.Run("a", "b", "/unmute")
+	assert.Nil(t, err)
+	assert.NotContains(t, muter.muted, "a-b")
+}
@@ -1,14 +1,29 @@
 package fileupload
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go"
 	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/audio"
+)
+
+const (
+	// MAX_AUDIO_SIZE ...
+	MAX_AUDIO_SIZE = 16 << 20
+	// MAX_AUDIO_DURATION ...
+	MAX_AUDIO_DURATION = 15 * time.Minute
 )
 
 // UploadFile ...
@@ -17,6 +32,8 @@ type UploadFile struct{}
 // UploadFileInterface ...
 type UploadFileInterface interface {
 	UploadFile(*multipart.FileHeader, multipart.File, string) (string, error)
+	UploadAudio(*multipart.FileHeader, multipart.File, string) (string, *audio.Info, error)
+	DeleteFile(string) error
 }
 
 var _ UploadFileInterface = &UploadFile{}
@@ -64,3 +81,88 @@ func (uf *UploadFile) UploadFile(fileHeader *multipart.FileHeader, file multipar
 	}
 	return resp.SecureURL, nil
 }
+
+// UploadAudio ...
+func (uf *UploadFile) UploadAudio(fileHeader *multipart.FileHeader, file multipart.File, folder string) (string, *audio.Info, error) {
+	ctx := context.Background()
+	cld, _ := cloudinary.NewFromParams(os.Getenv("CLD_NAME"), os.Getenv("CLD_KEY"), os.Getenv("CLD_SECRET"))
+
+	if fileHeader.Size > MAX_AUDIO_SIZE {
+		return "", nil, util.GetError("audio_size_error")
+	}
+
+	buffer, err := io.ReadAll(io.LimitReader(file, MAX_AUDIO_SIZE+1))
+	if err != nil {
+		return "", nil, util.GetError("general_error")
+	}
+	if len(buffer) > MAX_AUDIO_SIZE {
+		return "", nil, util.GetError("audio_size_error")
+	}
+
+	info, err := audio.Probe(buffer)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.Duration > MAX_AUDIO_DURATION {
+		return "", nil, util.GetError("audio_too_long")
+	}
+
+	// cloudinary stores audio under the video resource type.
+	resp, err := cld.Upload.Upload(ctx, bytes.NewReader(buffer), uploader.UploadParams{
+		Folder:       folder,
+		ResourceType: "video",
+	})
+	if err != nil {
+		return "", nil, util.GetError("general_error")
+	}
+	return resp.SecureURL, info, nil
+}
+
+var version = regexp.MustCompile(`^v[0-9]+$`)
+
+// DeleteFile removes a file uploaded with UploadFile or UploadAudio, given the
+// URL they returned. URLs that are not in our cloudinary account, like the
+// default profile picture, are left alone.
+func (uf *UploadFile) DeleteFile(fileURL string) error {
+	cloud, resourceType, publicID, ok := PublicID(fileURL)
+	if !ok || cloud != os.Getenv("CLD_NAME") {
+		return nil
+	}
+	cld, err := cloudinary.NewFromParams(os.Getenv("CLD_NAME"), os.Getenv("CLD_KEY"), os.Getenv("CLD_SECRET"))
+	if err != nil {
+		return util.GetError("general_error")
+	}
+	resp, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+		Invalidate:   true,
+	})
+	if err != nil || resp.Error.Message != "" {
+		return util.GetError("general_error")
+	}
+	return nil
+}
+
+// PublicID splits a cloudinary delivery URL,
+// https://res.cloudinary.com/<cloud>/<resource type>/upload/v<version>/<public id>.<ext>,
+// into the parts needed to delete the file.
+func PublicID(fileURL string) (cloud, resourceType, publicID string, ok bool) {
+	parsed, err := url.Parse(fileURL)
+	if err != nil || parsed.Host != "res.cloudinary.com" {
+		return "", "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "upload" {
+		return "", "", "", false
+	}
+	id := parts[3:]
+	if len(id) > 1 && version.MatchString(id[0]) {
+		id = id[1:]
+	}
+	publicID = strings.Join(id, "/")
+	publicID = strings.TrimSuffix(publicID, path.Ext(publicID))
+	if publicID == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], publicID, true
+}
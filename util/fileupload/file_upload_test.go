@@ -0,0 +1,32 @@
+package fileupload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PublicID(t *testing.T) {
+	cloud, resourceType, publicID, ok := PublicID("https://res.cloudinary.com/demo/image/upload/v1699999999/profile/abc123.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, "demo", cloud)
+	assert.Equal(t, "image", resourceType)
+	assert.Equal(t, "profile/abc123", publicID)
+
+	_, resourceType, publicID, ok = PublicID("https://res.cloudinary.com/demo/video/upload/voice/xyz.ogg")
+	assert.True(t, ok)
+	assert.Equal(t, "video", resourceType)
+	assert.Equal(t, "voice/xyz", publicID)
+}
+
+func Test_PublicIDOtherHosts(t *testing.T) {
+	for _, fileURL := range []string{
+		"",
+		"https://example.com/demo/image/upload/v1/profile/abc.jpg",
+		"https://res.cloudinary.com/demo/image/fetch/abc.jpg",
+		"https://res.cloudinary.com/demo/image/upload/",
+	} {
+		_, _, _, ok := PublicID(fileURL)
+		assert.False(t, ok, fileURL)
+	}
+}
@@ -0,0 +1,73 @@
+package linkpreview
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache ...
+type Cache interface {
+	Get(string) (*Preview, bool)
+	Set(string, *Preview)
+}
+
+type cacheEntry struct {
+	preview   *Preview
+	expiredAt time.Time
+}
+
+// MemoryCache ...
+type MemoryCache struct {
+	sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cacheEntry
+}
+
+var _ Cache = &MemoryCache{}
+
+// NewMemoryCache ...
+func NewMemoryCache(ttl time.Duration, maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// Get ...
+func (cache *MemoryCache) Get(key string) (*Preview, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	entry, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiredAt) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+	return entry.preview, true
+}
+
+// Set ...
+func (cache *MemoryCache) Set(key string, preview *Preview) {
+	cache.Lock()
+	defer cache.Unlock()
+	now := time.Now()
+	if len(cache.entries) >= cache.maxEntries {
+		// drop expired entries first, then an arbitrary one if still full.
+		for k, entry := range cache.entries {
+			if now.After(entry.expiredAt) {
+				delete(cache.entries, k)
+			}
+		}
+		for k := range cache.entries {
+			if len(cache.entries) < cache.maxEntries {
+				break
+			}
+			delete(cache.entries, k)
+		}
+	}
+	cache.entries[key] = cacheEntry{preview: preview, expiredAt: now.Add(cache.ttl)}
+}
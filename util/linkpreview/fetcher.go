@@ -0,0 +1,125 @@
+package linkpreview
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+var (
+	urlRegex = regexp.MustCompile(`https?://[^\s<>"']+`)
+)
+
+// Preview ...
+type Preview struct {
+	URL         string `bson:"url" json:"url"`
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description" json:"description"`
+	Image       string `bson:"image" json:"image"`
+	SiteName    string `bson:"site_name" json:"site_name"`
+}
+
+// HTTPClient ...
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Fetcher ...
+type Fetcher struct {
+	Client   HTTPClient
+	Cache    Cache
+	MaxBytes int64
+	Timeout  time.Duration
+}
+
+// FetcherInterface ...
+type FetcherInterface interface {
+	Fetch(context.Context, string) (*Preview, error)
+}
+
+var _ FetcherInterface = &Fetcher{}
+
+// NewFetcher ...
+func NewFetcher() *Fetcher {
+	timeout := 5 * time.Second
+	return &Fetcher{
+		Client:   NewSafeClient(timeout),
+		Cache:    NewMemoryCache(time.Hour, 1000),
+		MaxBytes: 512 << 10,
+		Timeout:  timeout,
+	}
+}
+
+// FindURL returns the first http(s) URL found in text, or an empty string.
+func FindURL(text string) string {
+	link := urlRegex.FindString(text)
+	return strings.TrimRight(link, ".,;:!?)]}")
+}
+
+// Fetch ...
+func (fetcher *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return nil, util.GetError("invalid_url")
+	}
+
+	if fetcher.Cache != nil {
+		if preview, ok := fetcher.Cache.Get(link.String()); ok {
+			return preview, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetcher.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return nil, util.GetError("invalid_url")
+	}
+	request.Header.Set("Accept", "text/html")
+	request.Header.Set("User-Agent", "go-chat-server-link-preview/1.0")
+
+	response, err := fetcher.Client.Do(request)
+	if err != nil {
+		return nil, util.GetError("preview_unavailable")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, util.GetError("preview_unavailable")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, util.GetError("preview_unavailable")
+	}
+
+	if response.ContentLength > fetcher.MaxBytes {
+		return nil, util.GetError("preview_too_large")
+	}
+
+	base := link
+	if response.Request != nil && response.Request.URL != nil {
+		base = response.Request.URL
+	}
+
+	preview, err := Parse(io.LimitReader(response.Body, fetcher.MaxBytes), base)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Title == "" && preview.Description == "" && preview.Image == "" {
+		return nil, util.GetError("preview_unavailable")
+	}
+
+	if fetcher.Cache != nil {
+		fetcher.Cache.Set(link.String(), preview)
+	}
+	return preview, nil
+}
@@ -0,0 +1,115 @@
+package linkpreview
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `<!doctype html>
+<html><head>
+<title>Fallback Title</title>
+<meta property="og:title" content="Open Graph Title">
+<meta name="twitter:description" content="Twitter description">
+<meta property="og:image" content="/images/cover.jpg">
+</head><body><p>content</p></body></html>`
+
+func newTestFetcher(client HTTPClient) *Fetcher {
+	return &Fetcher{
+		Client:   client,
+		Cache:    NewMemoryCache(time.Minute, 10),
+		MaxBytes: 1 << 20,
+		Timeout:  time.Second,
+	}
+}
+
+func Test_Fetch(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher(server.Client())
+	preview, err := fetcher.Fetch(context.Background(), server.URL+"/article")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Open Graph Title", preview.Title)
+	assert.Equal(t, "Twitter description", preview.Description)
+	assert.Equal(t, server.URL+"/images/cover.jpg", preview.Image)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/article")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, hits)
+}
+
+func Test_FetchLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Length", "4096")
+			fmt.Fprint(w, strings.Repeat("a", 4096))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, page)
+		default:
+			w.Header().Set("Content-Type", "application/pdf")
+		}
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher(server.Client())
+	fetcher.MaxBytes = 1024
+	fetcher.Timeout = 50 * time.Millisecond
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/large")
+	assert.NotNil(t, err)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/slow")
+	assert.NotNil(t, err)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/file.pdf")
+	assert.NotNil(t, err)
+
+	_, err = fetcher.Fetch(context.Background(), "ftp://example.com/file")
+	assert.NotNil(t, err)
+}
+
+func Test_SafeClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher(NewSafeClient(time.Second))
+	_, err := fetcher.Fetch(context.Background(), server.URL)
+	assert.NotNil(t, err)
+}
+
+func Test_IsPublicIP(t *testing.T) {
+	private := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fc00::1", "fe80::1"}
+	for _, ip := range private {
+		assert.False(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	public := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"}
+	for _, ip := range public {
+		assert.True(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func Test_FindURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/a?b=c", FindURL("look at https://example.com/a?b=c."))
+	assert.Equal(t, "", FindURL("no links here"))
+}
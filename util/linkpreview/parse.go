@@ -0,0 +1,116 @@
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+)
+
+// Parse reads the head of an HTML document and extracts the OpenGraph and
+// Twitter card metadata, falling back to <title> and <meta name="description">.
+func Parse(body io.Reader, base *url.URL) (*Preview, error) {
+	meta := make(map[string]string)
+	var title string
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(body)
+loop:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF && len(meta) == 0 && title == "" {
+				return nil, tokenizer.Err()
+			}
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = true
+			case "meta":
+				key, content := metaAttributes(token)
+				if key != "" && content != "" {
+					if _, ok := meta[key]; !ok {
+						meta[key] = content
+					}
+				}
+			case "body":
+				break loop
+			}
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = strings.TrimSpace(string(tokenizer.Text()))
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = false
+			case "head":
+				break loop
+			}
+		}
+	}
+
+	preview := &Preview{
+		URL:         base.String(),
+		Title:       truncate(firstOf(meta["og:title"], meta["twitter:title"], title), maxTitleLength),
+		Description: truncate(firstOf(meta["og:description"], meta["twitter:description"], meta["description"]), maxDescriptionLength),
+		Image:       resolve(base, firstOf(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"], meta["twitter:image:src"])),
+		SiteName:    truncate(firstOf(meta["og:site_name"], base.Hostname()), maxTitleLength),
+	}
+	if canonical := resolve(base, meta["og:url"]); canonical != "" {
+		preview.URL = canonical
+	}
+	return preview, nil
+}
+
+func metaAttributes(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	return key, content
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
+
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	link, err := base.Parse(ref)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+		return ""
+	}
+	return link.String()
+}
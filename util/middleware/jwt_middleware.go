@@ -7,6 +7,10 @@ import (
 	"github.com/kataras/iris/v12"
 )
 
+// Sessions is the store access tokens are issued to. When it is set, tokens
+// that were revoked (logout, suspension) are rejected before they expire.
+var Sessions auth.AuthenticationInterface
+
 // AuthenticationJWTMiddleware ...
 func AuthenticationJWTMiddleware(c iris.Context) {
 	err := auth.TokenValid(c.Request())
@@ -14,5 +18,12 @@ func AuthenticationJWTMiddleware(c iris.Context) {
 		util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
 		return
 	}
+	if Sessions != nil {
+		_, err = Sessions.FetchToken(auth.ExtractTokenClaims(c.Request(), "access_uuid"))
+		if err != nil {
+			util.ResponseError(util.GetError("unauthorized_access"), iris.StatusUnauthorized, c)
+			return
+		}
+	}
 	c.Next()
 }
@@ -0,0 +1,89 @@
+package moderation
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// MaxLength rejects messages longer than Limit characters.
+type MaxLength struct {
+	Limit int
+}
+
+// NewMaxLength ...
+func NewMaxLength(limit int) *MaxLength {
+	return &MaxLength{
+		Limit: limit,
+	}
+}
+
+// Name ...
+func (filter *MaxLength) Name() string {
+	return "max_length"
+}
+
+// Check ...
+func (filter *MaxLength) Check(message *entity.ChatMessage) Result {
+	if length := utf8.RuneCountInString(message.Message); length > filter.Limit {
+		return Result{Verdict: VERDICT_REJECT, Reason: fmt.Sprintf("%d characters, limit is %d", length, filter.Limit)}
+	}
+	return Allow()
+}
+
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+|\b(?:[a-z0-9-]+\.)+[a-z]{2,}(?:/[^\s<>"']*)?`)
+
+// URLBlocklist rejects messages linking to a blocked domain or any of its
+// subdomains.
+type URLBlocklist struct {
+	Domains map[string]bool
+}
+
+// NewURLBlocklist ...
+func NewURLBlocklist(domains ...string) *URLBlocklist {
+	blocklist := &URLBlocklist{Domains: make(map[string]bool)}
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			blocklist.Domains[domain] = true
+		}
+	}
+	return blocklist
+}
+
+// Name ...
+func (filter *URLBlocklist) Name() string {
+	return "url_blocklist"
+}
+
+// Check ...
+func (filter *URLBlocklist) Check(message *entity.ChatMessage) Result {
+	if len(filter.Domains) == 0 {
+		return Allow()
+	}
+	for _, link := range urlPattern.FindAllString(message.Message, -1) {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.Trim(strings.ToLower(parsed.Hostname()), ".")
+		for host != "" {
+			if filter.Domains[host] {
+				return Result{Verdict: VERDICT_REJECT, Reason: host}
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found {
+				break
+			}
+			host = parent
+		}
+	}
+	return Allow()
+}
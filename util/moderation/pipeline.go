@@ -0,0 +1,133 @@
+package moderation
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// verdicts, from the mildest to the strictest
+const (
+	VERDICT_ALLOW  = "allow"
+	VERDICT_REDACT = "redact"
+	VERDICT_FLAG   = "flag"
+	VERDICT_REJECT = "reject"
+)
+
+var severity = map[string]int{
+	VERDICT_ALLOW:  0,
+	VERDICT_REDACT: 1,
+	VERDICT_FLAG:   2,
+	VERDICT_REJECT: 3,
+}
+
+// Result is what a filter decides about one message. When Text is set it
+// replaces the message body, unless the message is rejected.
+type Result struct {
+	Verdict string
+	Text    string
+	Reason  string
+}
+
+// Filter is one step of the moderation pipeline.
+type Filter interface {
+	Name() string
+	Check(message *entity.ChatMessage) Result
+}
+
+// Allow ...
+func Allow() Result {
+	return Result{Verdict: VERDICT_ALLOW}
+}
+
+// Decision is the outcome of running every filter on a message.
+type Decision struct {
+	Verdict  string
+	Original string
+	Reasons  []entity.ModerationReason
+}
+
+// Rejected ...
+func (decision *Decision) Rejected() bool {
+	return decision.Verdict == VERDICT_REJECT
+}
+
+// Flagged ...
+func (decision *Decision) Flagged() bool {
+	return decision.Verdict == VERDICT_FLAG
+}
+
+// Pipeline runs its filters in the order they were registered.
+type Pipeline struct {
+	sync.RWMutex
+	filters []Filter
+}
+
+// NewPipeline ...
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{
+		filters: filters,
+	}
+}
+
+// NewDefaultPipeline builds the filters every message passes: the length
+// limit from MODERATION_MAX_LENGTH, the domains in MODERATION_BLOCKED_DOMAINS
+// and the word lists under root.
+func NewDefaultPipeline(root string) (*Pipeline, error) {
+	wordList, err := LoadWordLists(root)
+	if err != nil {
+		return nil, err
+	}
+	maxLength, err := strconv.Atoi(os.Getenv("MODERATION_MAX_LENGTH"))
+	if err != nil || maxLength <= 0 {
+		maxLength = 4096
+	}
+	return NewPipeline(
+		NewMaxLength(maxLength),
+		NewURLBlocklist(strings.Split(os.Getenv("MODERATION_BLOCKED_DOMAINS"), ",")...),
+		wordList,
+	), nil
+}
+
+// Register appends filter to the end of the chain.
+func (pipeline *Pipeline) Register(filter Filter) {
+	pipeline.Lock()
+	defer pipeline.Unlock()
+	pipeline.filters = append(pipeline.filters, filter)
+}
+
+// Run passes message through the chain. Redactions are applied to the
+// message as they happen so later filters see the redacted text, a
+// rejection stops the chain, and the strictest verdict wins.
+func (pipeline *Pipeline) Run(message *entity.ChatMessage) *Decision {
+	pipeline.RLock()
+	filters := pipeline.filters
+	pipeline.RUnlock()
+
+	decision := &Decision{Verdict: VERDICT_ALLOW, Original: message.Message}
+	for _, filter := range filters {
+		result := filter.Check(message)
+		if result.Verdict == "" || result.Verdict == VERDICT_ALLOW {
+			continue
+		}
+
+		decision.Reasons = append(decision.Reasons, entity.ModerationReason{
+			Filter:  filter.Name(),
+			Verdict: result.Verdict,
+			Reason:  result.Reason,
+		})
+		if severity[result.Verdict] > severity[decision.Verdict] {
+			decision.Verdict = result.Verdict
+		}
+		if result.Verdict == VERDICT_REJECT {
+			break
+		}
+		if result.Text != "" {
+			message.Message = result.Text
+		}
+	}
+	return decision
+}
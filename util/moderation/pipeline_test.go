@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type rejectAll struct{}
+
+func (rejectAll) Name() string { return "reject_all" }
+
+func (rejectAll) Check(message *entity.ChatMessage) Result {
+	return Result{Verdict: VERDICT_REJECT, Reason: "test"}
+}
+
+func wordList(t *testing.T, lines ...string) *WordList {
+	filter := NewWordList()
+	assert.Nil(t, filter.Load(strings.NewReader(strings.Join(lines, "\n"))))
+	return filter
+}
+
+func Test_WordListRedactsWholeWords(t *testing.T) {
+	pipeline := NewPipeline(wordList(t, "# comment", "darn", "?scam", "غبي"))
+
+	message := &entity.ChatMessage{Message: "Darn it, darned. أنت غَبِيّ"}
+	decision := pipeline.Run(message)
+
+	assert.Equal(t, VERDICT_REDACT, decision.Verdict)
+	assert.Equal(t, "**** it, darned. أنت ***", message.Message)
+	assert.Equal(t, "Darn it, darned. أنت غَبِيّ", decision.Original)
+
+	message = &entity.ChatMessage{Message: "darn, a scam"}
+	decision = pipeline.Run(message)
+	assert.True(t, decision.Flagged())
+	assert.Equal(t, "****, a scam", message.Message)
+}
+
+func Test_NormalizeArabic(t *testing.T) {
+	assert.Equal(t, Normalize("اسلام"), Normalize("إسـلام"))
+	assert.Equal(t, Normalize("مدرسه"), Normalize("مَدْرَسَة"))
+}
+
+func Test_URLBlocklist(t *testing.T) {
+	filter := NewURLBlocklist("bad.example", "")
+
+	assert.Equal(t, VERDICT_REJECT, filter.Check(&entity.ChatMessage{Message: "see https://cdn.bad.example/x"}).Verdict)
+	assert.Equal(t, VERDICT_REJECT, filter.Check(&entity.ChatMessage{Message: "go to bad.example now"}).Verdict)
+	assert.Equal(t, VERDICT_ALLOW, filter.Check(&entity.ChatMessage{Message: "notbad.example is fine"}).Verdict)
+}
+
+func Test_PipelineStopsOnReject(t *testing.T) {
+	pipeline := NewPipeline(NewMaxLength(5))
+	pipeline.Register(rejectAll{})
+
+	decision := pipeline.Run(&entity.ChatMessage{Message: "this is too long"})
+	assert.True(t, decision.Rejected())
+	assert.Len(t, decision.Reasons, 1)
+	assert.Equal(t, "max_length", decision.Reasons[0].Filter)
+
+	decision = pipeline.Run(&entity.ChatMessage{Message: "ok"})
+	assert.Equal(t, "reject_all", decision.Reasons[0].Filter)
+}
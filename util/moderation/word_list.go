@@ -0,0 +1,158 @@
+package moderation
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+)
+
+// WORD_LIST_FILE is the name of the word list inside every locale folder.
+const WORD_LIST_FILE = "blocked_words.txt"
+
+var arabicLetters = strings.NewReplacer(
+	"أ", "ا",
+	"إ", "ا",
+	"آ", "ا",
+	"ٱ", "ا",
+	"ى", "ي",
+	"ة", "ه",
+	"ؤ", "و",
+	"ئ", "ي",
+)
+
+// Normalize folds a word so spelling variants match: lower case, no Arabic
+// diacritics or tatweel, and one form for each family of Arabic letters.
+func Normalize(word string) string {
+	word = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) || r == 'ـ' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, word)
+	return arabicLetters.Replace(word)
+}
+
+// WordList matches whole words against per locale lists. Words are
+// redacted unless their entry says otherwise; redactions are kept even when
+// another word in the same message flags it.
+type WordList struct {
+	Words map[string]string
+}
+
+// NewWordList ...
+func NewWordList() *WordList {
+	return &WordList{
+		Words: make(map[string]string),
+	}
+}
+
+// Load reads one word per line. A leading "!" rejects the message, a leading
+// "?" flags it for review, and lines starting with "#" are comments.
+func (filter *WordList) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		verdict := VERDICT_REDACT
+		switch line[0] {
+		case '!':
+			verdict, line = VERDICT_REJECT, line[1:]
+		case '?':
+			verdict, line = VERDICT_FLAG, line[1:]
+		}
+		if word := Normalize(strings.TrimSpace(line)); word != "" {
+			filter.Words[word] = verdict
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadWordLists reads WORD_LIST_FILE from every locale folder under root.
+// Locales without a list are skipped.
+func LoadWordLists(root string) (*WordList, error) {
+	filter := NewWordList()
+	files, err := filepath.Glob(filepath.Join(root, "*", WORD_LIST_FILE))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		err = filter.Load(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// Name ...
+func (filter *WordList) Name() string {
+	return "word_list"
+}
+
+// Check ...
+func (filter *WordList) Check(message *entity.ChatMessage) Result {
+	if len(filter.Words) == 0 {
+		return Allow()
+	}
+
+	verdict := VERDICT_ALLOW
+	redacted := false
+	var matched []string
+	var text strings.Builder
+	word := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+	}
+
+	body := message.Message
+	for len(body) > 0 {
+		r, size := utf8.DecodeRuneInString(body)
+		if !word(r) {
+			text.WriteString(body[:size])
+			body = body[size:]
+			continue
+		}
+		end := strings.IndexFunc(body, func(r rune) bool { return !word(r) })
+		if end < 0 {
+			end = len(body)
+		}
+		token := body[:end]
+		body = body[end:]
+
+		found, exists := filter.Words[Normalize(token)]
+		if !exists {
+			text.WriteString(token)
+			continue
+		}
+		matched = append(matched, Normalize(token))
+		if severity[found] > severity[verdict] {
+			verdict = found
+		}
+		if found == VERDICT_REDACT {
+			redacted = true
+			token = strings.Repeat("*", utf8.RuneCountInString(Normalize(token)))
+		}
+		text.WriteString(token)
+	}
+
+	if verdict == VERDICT_ALLOW {
+		return Allow()
+	}
+	result := Result{Verdict: verdict, Reason: strings.Join(matched, ", ")}
+	if redacted {
+		result.Text = text.String()
+	}
+	return result
+}
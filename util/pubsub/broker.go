@@ -0,0 +1,71 @@
+package pubsub
+
+import "sync"
+
+// SUBSCRIBER_BUFFER is how many events a subscriber can fall behind before
+// new ones are dropped for it.
+const SUBSCRIBER_BUFFER = 64
+
+// Broker fans events out to in-process subscribers by topic. Publishing
+// never blocks: a subscriber that is not keeping up misses events instead
+// of holding up the sender.
+type Broker struct {
+	sync.RWMutex
+	topics map[string]map[chan interface{}]struct{}
+}
+
+// NewBroker ...
+func NewBroker() *Broker {
+	return &Broker{
+		topics: make(map[string]map[chan interface{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the events published to topic, and
+// a function that unsubscribes and closes the channel.
+func (broker *Broker) Subscribe(topic string) (<-chan interface{}, func()) {
+	events := make(chan interface{}, SUBSCRIBER_BUFFER)
+	broker.Lock()
+	if broker.topics[topic] == nil {
+		broker.topics[topic] = make(map[chan interface{}]struct{})
+	}
+	broker.topics[topic][events] = struct{}{}
+	broker.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			broker.Lock()
+			delete(broker.topics[topic], events)
+			if len(broker.topics[topic]) == 0 {
+				delete(broker.topics, topic)
+			}
+			broker.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish sends event to every subscriber of topic and returns how many got
+// it.
+func (broker *Broker) Publish(topic string, event interface{}) int {
+	broker.RLock()
+	defer broker.RUnlock()
+
+	sent := 0
+	for events := range broker.topics[topic] {
+		select {
+		case events <- event:
+			sent++
+		default:
+		}
+	}
+	return sent
+}
+
+// Subscribers returns how many subscribers topic has.
+func (broker *Broker) Subscribers(topic string) int {
+	broker.RLock()
+	defer broker.RUnlock()
+	return len(broker.topics[topic])
+}
@@ -0,0 +1,41 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BrokerPublish(t *testing.T) {
+	broker := NewBroker()
+	first, cancelFirst := broker.Subscribe("a")
+	second, cancelSecond := broker.Subscribe("a")
+	other, cancelOther := broker.Subscribe("b")
+	defer cancelSecond()
+	defer cancelOther()
+
+	assert.Equal(t, 2, broker.Publish("a", "hello"))
+	assert.Equal(t, "hello", <-first)
+	assert.Equal(t, "hello", <-second)
+	assert.Len(t, other, 0)
+
+	cancelFirst()
+	cancelFirst()
+	_, open := <-first
+	assert.False(t, open)
+	assert.Equal(t, 1, broker.Subscribers("a"))
+	assert.Equal(t, 0, broker.Publish("c", "nobody"))
+}
+
+func Test_BrokerSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	events, cancel := broker.Subscribe("a")
+	defer cancel()
+
+	for index := 0; index < SUBSCRIBER_BUFFER; index++ {
+		assert.Equal(t, 1, broker.Publish("a", index))
+	}
+	assert.Equal(t, 0, broker.Publish("a", "dropped"))
+	assert.Equal(t, 0, <-events)
+	assert.Len(t, events, SUBSCRIBER_BUFFER-1)
+}
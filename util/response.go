@@ -17,3 +17,25 @@ func ResponseError(data error, status int, c iris.Context) {
 	c.StatusCode(status)
 	c.JSON(c.Tr(fmt.Sprintf("%+v", data)))
 }
+
+// Page describes where a page of a list ends. NextCursor is passed back as
+// the cursor query parameter to get the next page.
+type Page struct {
+	Limit      int64  `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ResponsePage ...
+func ResponsePage(data interface{}, page Page, status int, c iris.Context) {
+	c.StatusCode(status)
+	c.JSON(iris.Map{"data": data, "page": page})
+}
+
+// ResponseErrorCode writes err under "error" with its key as the code, so
+// clients can tell errors apart without matching translated text.
+func ResponseErrorCode(err error, status int, c iris.Context) {
+	code := fmt.Sprintf("%+v", err)
+	c.StatusCode(status)
+	c.JSON(iris.Map{"error": iris.Map{"code": code, "message": c.Tr(code)}})
+}
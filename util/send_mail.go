@@ -28,6 +28,13 @@ type ActiveLoginMail struct {
 	IPAddress string
 }
 
+// AccountDeletionMail ...
+type AccountDeletionMail struct {
+	ReceiverName string
+	ReceiverMail string
+	DeleteAt     string
+}
+
 // SendMail ...
 func SendMail(to []string, data interface{}, template string, subject string) error {
 	emailHost := os.Getenv("EMAIL_HOST")
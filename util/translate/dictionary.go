@@ -0,0 +1,79 @@
+package translate
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{M}']+`)
+
+// Dictionary translates word by word from a fixed word list. It is meant for
+// tests and local development; words it does not know are left as they are.
+type Dictionary struct {
+	sync.RWMutex
+	words map[string]map[string]string
+}
+
+var _ Translator = &Dictionary{}
+
+// NewDictionary ...
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		words: make(map[string]map[string]string),
+	}
+}
+
+// Add teaches the dictionary the translation of word into target.
+func (dictionary *Dictionary) Add(target, word, translation string) {
+	dictionary.Lock()
+	defer dictionary.Unlock()
+	if dictionary.words[target] == nil {
+		dictionary.words[target] = make(map[string]string)
+	}
+	dictionary.words[target][strings.ToLower(word)] = translation
+}
+
+// Load reads "word = translation" lines for target. Blank lines and lines
+// starting with # are skipped.
+func (dictionary *Dictionary) Load(target string, reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, translation, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		dictionary.Add(target, strings.TrimSpace(word), strings.TrimSpace(translation))
+	}
+	return scanner.Err()
+}
+
+// Translate replaces every known word, keeping a leading capital letter.
+func (dictionary *Dictionary) Translate(ctx context.Context, text, target string) (*Result, error) {
+	dictionary.RLock()
+	defer dictionary.RUnlock()
+	words := dictionary.words[target]
+
+	translated := wordRegex.ReplaceAllStringFunc(text, func(word string) string {
+		translation, ok := words[strings.ToLower(word)]
+		if !ok {
+			return word
+		}
+		first, _ := utf8.DecodeRuneInString(word)
+		if unicode.IsUpper(first) {
+			head, size := utf8.DecodeRuneInString(translation)
+			return string(unicode.ToUpper(head)) + translation[size:]
+		}
+		return translation
+	})
+	return &Result{Text: translated}, nil
+}
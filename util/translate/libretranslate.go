@@ -0,0 +1,85 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+)
+
+// LibreTranslate talks to a LibreTranslate server, or anything that speaks
+// its /translate API.
+type LibreTranslate struct {
+	URL    string
+	APIKey string
+	Client linkpreview.HTTPClient
+}
+
+var _ Translator = &LibreTranslate{}
+
+// NewLibreTranslate ...
+func NewLibreTranslate(link, apiKey string, client linkpreview.HTTPClient) *LibreTranslate {
+	return &LibreTranslate{
+		URL:    strings.TrimRight(link, "/"),
+		APIKey: apiKey,
+		Client: client,
+	}
+}
+
+type libreRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage struct {
+		Language string `json:"language"`
+	} `json:"detectedLanguage"`
+}
+
+// Translate asks the server to detect the source language and translate
+// text to target.
+func (translator *LibreTranslate) Translate(ctx context.Context, text, target string) (*Result, error) {
+	body, err := json.Marshal(libreRequest{
+		Q:      text,
+		Source: "auto",
+		Target: target,
+		Format: "text",
+		APIKey: translator.APIKey,
+	})
+	if err != nil {
+		return nil, util.GetError("translation_failed")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, translator.URL+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return nil, util.GetError("translation_failed")
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := translator.Client.Do(request)
+	if err != nil {
+		return nil, util.GetError("translation_failed")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, util.GetError("translation_failed")
+	}
+
+	var result libreResponse
+	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, util.GetError("translation_failed")
+	}
+	return &Result{
+		Text:   result.TranslatedText,
+		Source: result.DetectedLanguage.Language,
+	}, nil
+}
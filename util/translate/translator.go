@@ -0,0 +1,46 @@
+package translate
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/util"
+)
+
+// Result is a translated text. Source is the language the provider detected,
+// empty when it could not tell.
+type Result struct {
+	Text   string
+	Source string
+}
+
+// Translator turns text into the target language.
+type Translator interface {
+	Translate(ctx context.Context, text, target string) (*Result, error)
+}
+
+// NewTranslator returns the LibreTranslate compatible service at
+// TRANSLATE_URL, or a translator that always fails when it is not set.
+func NewTranslator() Translator {
+	link := os.Getenv("TRANSLATE_URL")
+	if link == "" {
+		return Unavailable{}
+	}
+	timeout, err := time.ParseDuration(os.Getenv("TRANSLATE_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return NewLibreTranslate(link, os.Getenv("TRANSLATE_API_KEY"), &http.Client{Timeout: timeout})
+}
+
+// Unavailable is used when no translation service is configured.
+type Unavailable struct{}
+
+var _ Translator = Unavailable{}
+
+// Translate ...
+func (Unavailable) Translate(ctx context.Context, text, target string) (*Result, error) {
+	return nil, util.GetError("translation_unavailable")
+}
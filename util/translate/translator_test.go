@@ -0,0 +1,59 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DictionaryTranslatesKnownWords(t *testing.T) {
+	dictionary := NewDictionary()
+	assert.Nil(t, dictionary.Load("ar", strings.NewReader("# en -> ar\nhello = مرحبا\n\nfriend = صديق\n")))
+	dictionary.Add("en", "مرحبا", "hello")
+
+	result, err := dictionary.Translate(context.Background(), "Hello, my friend!", "ar")
+	assert.Nil(t, err)
+	assert.Equal(t, "مرحبا, my صديق!", result.Text)
+
+	result, err = dictionary.Translate(context.Background(), "مرحبا", "en")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result.Text)
+
+	result, err = dictionary.Translate(context.Background(), "Hello", "fr")
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello", result.Text)
+}
+
+func Test_LibreTranslate(t *testing.T) {
+	var request libreRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"translatedText":"مرحبا","detectedLanguage":{"confidence":90,"language":"en"}}`))
+	}))
+	defer server.Close()
+
+	translator := NewLibreTranslate(server.URL+"/", "key", http.DefaultClient)
+	result, err := translator.Translate(context.Background(), "hello", "ar")
+	assert.Nil(t, err)
+	assert.Equal(t, &Result{Text: "مرحبا", Source: "en"}, result)
+	assert.Equal(t, libreRequest{Q: "hello", Source: "auto", Target: "ar", Format: "text", APIKey: "key"}, request)
+}
+
+func Test_LibreTranslateFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := NewLibreTranslate(server.URL, "", http.DefaultClient).Translate(context.Background(), "hello", "ar")
+	assert.EqualError(t, err, "translation_failed")
+
+	_, err = Unavailable{}.Translate(context.Background(), "hello", "ar")
+	assert.EqualError(t, err, "translation_unavailable")
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"math/rand"
 	"time"
 
@@ -14,3 +16,11 @@ func ULID() string {
 	ULID, _ := ulid.New(ms, entropy)
 	return ULID.String()
 }
+
+// ULIDFromTime returns a ULID for the given time whose random part is derived
+// from seed, so the same (time, seed) pair always yields the same id.
+func ULIDFromTime(t time.Time, seed string) string {
+	hash := sha256.Sum256([]byte(seed))
+	ULID, _ := ulid.New(ulid.Timestamp(t), bytes.NewReader(hash[:]))
+	return ULID.String()
+}
@@ -0,0 +1,17 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ULIDFromTime(t *testing.T) {
+	sentAt := time.Date(2021, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	assert.Equal(t, ULIDFromTime(sentAt, "seed"), ULIDFromTime(sentAt, "seed"))
+	assert.NotEqual(t, ULIDFromTime(sentAt, "seed"), ULIDFromTime(sentAt, "other seed"))
+	assert.True(t, ULIDFromTime(sentAt, "b") < ULIDFromTime(sentAt.Add(time.Millisecond), "a"))
+	assert.Len(t, GetURLIds("/"+ULIDFromTime(sentAt, "seed")), 1)
+}
@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/domain/repository"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+	"go.uber.org/zap"
+)
+
+// Dispatcher queues events for every webhook subscribed to them and posts
+// them in the background. Deliveries live in the store, so a restart or
+// another replica picks up where this one stopped.
+type Dispatcher struct {
+	Store   repository.WebhookRepository
+	Client  linkpreview.HTTPClient
+	Options Options
+	Log     *zap.SugaredLogger
+	wake    chan struct{}
+}
+
+// DispatcherInterface ...
+type DispatcherInterface interface {
+	Publish(string, interface{}) error
+	PublishTo(string, string, interface{}) error
+	Retry(*entity.WebhookDelivery) error
+}
+
+var _ DispatcherInterface = &Dispatcher{}
+
+// NewDispatcher ...
+func NewDispatcher(store repository.WebhookRepository, log *zap.SugaredLogger) *Dispatcher {
+	options := NewOptions()
+	return &Dispatcher{
+		Store:   store,
+		Client:  linkpreview.NewSafeClient(options.Timeout),
+		Options: options,
+		Log:     log,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Publish queues one delivery of event for each active webhook subscribed to
+// it. Every webhook receives the same body and event id.
+func (dispatcher *Dispatcher) Publish(event string, data interface{}) error {
+	webhooks, err := dispatcher.Store.GetWebhooksByEvent(event)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	return dispatcher.queue(webhooks, event, data)
+}
+
+// PublishTo queues event for one webhook only, whatever it subscribed to.
+func (dispatcher *Dispatcher) PublishTo(ID string, event string, data interface{}) error {
+	webhook, err := dispatcher.Store.GetWebhook(ID)
+	if err != nil {
+		return err
+	}
+	return dispatcher.queue([]entity.Webhook{*webhook}, event, data)
+}
+
+func (dispatcher *Dispatcher) queue(webhooks []entity.Webhook, event string, data interface{}) error {
+	payload := entity.WebhookEvent{
+		ID:        util.ULID(),
+		Type:      event,
+		CreatedAt: util.GetTimeNow(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]entity.WebhookDelivery, len(webhooks))
+	for index, webhook := range webhooks {
+		deliveries[index].PrepareWebhookDelivery(webhook.ID, event, payload.ID, string(body))
+	}
+	err = dispatcher.Store.AddWebhookDeliveries(deliveries)
+	if err != nil {
+		return err
+	}
+	dispatcher.Wake()
+	return nil
+}
+
+// Retry puts a dead delivery back in the queue with a fresh set of attempts.
+// Its log is kept.
+func (dispatcher *Dispatcher) Retry(delivery *entity.WebhookDelivery) error {
+	if delivery.Status != entity.DELIVERY_DEAD {
+		return util.GetError("delivery_not_dead")
+	}
+	delivery.Status = entity.DELIVERY_PENDING
+	delivery.Attempts = 0
+	delivery.UpdatedAt = util.GetTimeNow()
+	delivery.NextAttemptAt = delivery.UpdatedAt
+	err := dispatcher.Store.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		return err
+	}
+	dispatcher.Wake()
+	return nil
+}
+
+// Wake tells an idle worker to look for due deliveries now rather than at
+// the next poll.
+func (dispatcher *Dispatcher) Wake() {
+	select {
+	case dispatcher.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the workers and blocks until ctx is done.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for i := 0; i < dispatcher.Options.Workers; i++ {
+		go func() {
+			dispatcher.work(ctx)
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < dispatcher.Options.Workers; i++ {
+		<-done
+	}
+}
+
+func (dispatcher *Dispatcher) work(ctx context.Context) {
+	tick := time.NewTicker(dispatcher.Options.PollInterval)
+	defer tick.Stop()
+
+	for {
+		for dispatcher.deliverNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		case <-dispatcher.wake:
+		}
+	}
+}
+
+// deliverNext claims one due delivery and attempts it. It reports whether
+// there was anything to deliver.
+func (dispatcher *Dispatcher) deliverNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	lease := dispatcher.Options.Timeout * 2
+	delivery, err := dispatcher.Store.ClaimWebhookDelivery(util.GetTimeNow(), lease)
+	if err != nil {
+		return false
+	}
+
+	webhook, err := dispatcher.Store.GetWebhook(delivery.Webhook)
+	if err != nil || !webhook.Active {
+		dispatcher.record(delivery, entity.WebhookAttempt{
+			Error:     "webhook_inactive",
+			AttemptAt: util.GetTimeNow(),
+		}, false)
+		return true
+	}
+
+	dispatcher.record(delivery, dispatcher.Send(ctx, webhook, delivery), true)
+	return true
+}
+
+// record appends attempt to the delivery log. A failed attempt schedules the
+// next one, or moves the delivery to the dead letters when retry is false or
+// it runs out of attempts.
+func (dispatcher *Dispatcher) record(delivery *entity.WebhookDelivery, attempt entity.WebhookAttempt, retry bool) {
+	delivery.Attempts++
+	delivery.Log = append(delivery.Log, attempt)
+	delivery.UpdatedAt = util.GetTimeNow()
+
+	switch {
+	case attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		delivery.Status = entity.DELIVERY_DELIVERED
+	case !retry || delivery.Attempts >= dispatcher.Options.MaxAttempts:
+		delivery.Status = entity.DELIVERY_DEAD
+	default:
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(dispatcher.Options.Backoff(delivery.Attempts))
+	}
+
+	err := dispatcher.Store.UpdateWebhookDelivery(delivery)
+	if err != nil && dispatcher.Log != nil {
+		dispatcher.Log.Errorf("webhook delivery %s: %+v", delivery.ID, err)
+	}
+}
+
+// Send posts the delivery payload to webhook once, signed with its secret.
+func (dispatcher *Dispatcher) Send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) entity.WebhookAttempt {
+	attempt := entity.WebhookAttempt{AttemptAt: util.GetTimeNow()}
+	body := []byte(delivery.Payload)
+	timestamp := attempt.AttemptAt.Unix()
+
+	ctx, cancel := context.WithTimeout(ctx, dispatcher.Options.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = "invalid_webhook_url"
+		return attempt
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "go-chat-server-webhook/1.0")
+	request.Header.Set("X-Webhook-Id", delivery.EventId)
+	request.Header.Set("X-Webhook-Delivery", delivery.ID)
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", Sign(webhook.Secret, timestamp, body))
+
+	response, err := dispatcher.Client.Do(request)
+	attempt.Duration = time.Since(attempt.AttemptAt).Milliseconds()
+	if err != nil {
+		attempt.Error = fmt.Sprintf("%+v", err)
+		return attempt
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	attempt.StatusCode = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		attempt.Error = response.Status
+	}
+	return attempt
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	webhooks   []entity.Webhook
+	deliveries []entity.WebhookDelivery
+}
+
+func (store *memoryStore) CreateWebhook(webhook *entity.Webhook) (*entity.Webhook, error) {
+	store.webhooks = append(store.webhooks, *webhook)
+	return webhook, nil
+}
+
+func (store *memoryStore) GetWebhook(ID string) (*entity.Webhook, error) {
+	for _, webhook := range store.webhooks {
+		if webhook.ID == ID {
+			return &webhook, nil
+		}
+	}
+	return nil, util.GetError("webhook_not_found")
+}
+
+func (store *memoryStore) GetWebhooks() ([]entity.Webhook, error) {
+	return store.webhooks, nil
+}
+
+func (store *memoryStore) GetWebhooksByEvent(event string) ([]entity.Webhook, error) {
+	webhooks := []entity.Webhook{}
+	for _, webhook := range store.webhooks {
+		for _, subscribed := range webhook.Events {
+			if subscribed == event && webhook.Active && webhook.Bot == "" {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (store *memoryStore) UpdateWebhook(webhook *entity.Webhook) error { return nil }
+
+func (store *memoryStore) DeleteWebhook(ID string) error { return nil }
+
+func (store *memoryStore) AddWebhookDeliveries(deliveries []entity.WebhookDelivery) error {
+	store.deliveries = append(store.deliveries, deliveries...)
+	return nil
+}
+
+func (store *memoryStore) ClaimWebhookDelivery(now time.Time, lease time.Duration) (*entity.WebhookDelivery, error) {
+	for index, delivery := range store.deliveries {
+		if delivery.Status == entity.DELIVERY_PENDING && !delivery.NextAttemptAt.After(now) {
+			store.deliveries[index].NextAttemptAt = now.Add(lease)
+			claimed := store.deliveries[index]
+			return &claimed, nil
+		}
+	}
+	return nil, util.GetError("delivery_not_found")
+}
+
+func (store *memoryStore) GetWebhookDelivery(ID string) (*entity.WebhookDelivery, error) {
+	return nil, util.GetError("delivery_not_found")
+}
+
+func (store *memoryStore) GetWebhookDeliveries(webhook, status string, page int64) ([]entity.WebhookDelivery, error) {
+	return store.deliveries, nil
+}
+
+func (store *memoryStore) UpdateWebhookDelivery(delivery *entity.WebhookDelivery) error {
+	for index := range store.deliveries {
+		if store.deliveries[index].ID == delivery.ID {
+			store.deliveries[index] = *delivery
+		}
+	}
+	return nil
+}
+
+func newTestDispatcher(store *memoryStore) *Dispatcher {
+	options := DefaultOptions()
+	options.MaxAttempts = 3
+	return &Dispatcher{
+		Store:   store,
+		Client:  http.DefaultClient,
+		Options: options,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func Test_SignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"message.created"}`)
+	now := time.Now().Unix()
+	signature := Sign("secret", now, body)
+
+	assert.True(t, Verify("secret", now, body, signature, time.Minute))
+	assert.False(t, Verify("other", now, body, signature, time.Minute))
+	assert.False(t, Verify("secret", now, []byte(`{}`), signature, time.Minute))
+	assert.False(t, Verify("secret", now-3600, body, Sign("secret", now-3600, body), time.Minute))
+}
+
+func Test_BackoffDoublesUpToMaxDelay(t *testing.T) {
+	options := Options{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Second, options.Backoff(1))
+	assert.Equal(t, 2*time.Second, options.Backoff(2))
+	assert.Equal(t, 8*time.Second, options.Backoff(4))
+	assert.Equal(t, 10*time.Second, options.Backoff(5))
+	assert.Equal(t, 10*time.Second, options.Backoff(50))
+}
+
+func Test_DispatcherSignsAndDelivers(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{webhooks: []entity.Webhook{
+		{ID: "1", URL: server.URL, Secret: "secret", Active: true, Events: []string{entity.WEBHOOK_MESSAGE_CREATED}},
+		{ID: "2", URL: server.URL, Secret: "secret", Active: true, Events: []string{entity.WEBHOOK_PROFILE_UPDATED}},
+	}}
+	dispatcher := newTestDispatcher(store)
+
+	assert.Nil(t, dispatcher.Publish(entity.WEBHOOK_MESSAGE_CREATED, map[string]string{"ref": "abc"}))
+	assert.Len(t, store.deliveries, 1)
+
+	assert.True(t, dispatcher.deliverNext(context.Background()))
+	assert.False(t, dispatcher.deliverNext(context.Background()))
+
+	delivery := store.deliveries[0]
+	assert.Equal(t, entity.DELIVERY_DELIVERED, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.Log[0].StatusCode)
+
+	timestamp, _ := strconv.ParseInt(received.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	assert.Equal(t, entity.WEBHOOK_MESSAGE_CREATED, received.Header.Get("X-Webhook-Event"))
+	assert.Equal(t, delivery.EventId, received.Header.Get("X-Webhook-Id"))
+	assert.True(t, Verify("secret", timestamp, body, received.Header.Get("X-Webhook-Signature"), time.Minute))
+	assert.Contains(t, string(body), `"ref":"abc"`)
+}
+
+func Test_DispatcherRetriesThenDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{webhooks: []entity.Webhook{
+		{ID: "1", URL: server.URL, Secret: "secret", Active: true, Events: []string{entity.WEBHOOK_MESSAGE_READ}},
+	}}
+	dispatcher := newTestDispatcher(store)
+	assert.Nil(t, dispatcher.Publish(entity.WEBHOOK_MESSAGE_READ, nil))
+
+	assert.True(t, dispatcher.deliverNext(context.Background()))
+	delivery := store.deliveries[0]
+	assert.Equal(t, entity.DELIVERY_PENDING, delivery.Status)
+	assert.WithinDuration(t, delivery.UpdatedAt.Add(dispatcher.Options.BaseDelay), delivery.NextAttemptAt, time.Millisecond)
+	assert.False(t, dispatcher.deliverNext(context.Background()))
+
+	for i := 1; i < dispatcher.Options.MaxAttempts; i++ {
+		store.deliveries[0].NextAttemptAt = time.Time{}
+		assert.True(t, dispatcher.deliverNext(context.Background()))
+	}
+	delivery = store.deliveries[0]
+	assert.Equal(t, entity.DELIVERY_DEAD, delivery.Status)
+	assert.Len(t, delivery.Log, dispatcher.Options.MaxAttempts)
+
+	assert.Nil(t, dispatcher.Retry(&delivery))
+	assert.Equal(t, entity.DELIVERY_PENDING, store.deliveries[0].Status)
+	assert.Equal(t, 0, store.deliveries[0].Attempts)
+	assert.NotNil(t, dispatcher.Retry(&store.deliveries[0]))
+}
+
+func Test_PublishToReachesBotWebhooksOnly(t *testing.T) {
+	store := &memoryStore{webhooks: []entity.Webhook{
+		{ID: "1", Active: true, Events: []string{entity.WEBHOOK_MESSAGE_CREATED}},
+		{ID: "2", Active: true, Events: []string{entity.WEBHOOK_BOT_MESSAGE}, Bot: "bot"},
+	}}
+	dispatcher := newTestDispatcher(store)
+
+	assert.Nil(t, dispatcher.Publish(entity.WEBHOOK_BOT_MESSAGE, nil))
+	assert.Len(t, store.deliveries, 0)
+
+	assert.Nil(t, dispatcher.PublishTo("2", entity.WEBHOOK_BOT_MESSAGE, nil))
+	assert.Len(t, store.deliveries, 1)
+	assert.Equal(t, "2", store.deliveries[0].Webhook)
+	assert.NotNil(t, dispatcher.PublishTo("3", entity.WEBHOOK_BOT_MESSAGE, nil))
+}
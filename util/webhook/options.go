@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Options tunes delivery attempts and the retry schedule.
+type Options struct {
+	MaxAttempts  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	Workers      int
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	return Options{
+		MaxAttempts:  8,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     time.Hour,
+		Timeout:      10 * time.Second,
+		PollInterval: 5 * time.Second,
+		Workers:      4,
+	}
+}
+
+// NewOptions reads the WEBHOOK_* environment variables, keeping the default
+// for anything missing or invalid.
+func NewOptions() Options {
+	options := DefaultOptions()
+	options.MaxAttempts = envInt("WEBHOOK_MAX_ATTEMPTS", options.MaxAttempts)
+	options.BaseDelay = envDuration("WEBHOOK_BASE_DELAY", options.BaseDelay)
+	options.MaxDelay = envDuration("WEBHOOK_MAX_DELAY", options.MaxDelay)
+	options.Timeout = envDuration("WEBHOOK_TIMEOUT", options.Timeout)
+	options.Workers = envInt("WEBHOOK_WORKERS", options.Workers)
+	return options
+}
+
+// Backoff is the wait before the attempt that follows attempt number
+// attempt, counted from 1. It doubles every time, up to MaxDelay.
+func (options Options) Backoff(attempt int) time.Duration {
+	delay := options.BaseDelay
+	for i := 1; i < attempt && delay < options.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > options.MaxDelay {
+		return options.MaxDelay
+	}
+	return delay
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SIGNATURE_PREFIX ...
+const SIGNATURE_PREFIX = "sha256="
+
+// Sign returns the HMAC-SHA256 of "<timestamp>.<body>" keyed with secret,
+// in the form sent in the X-Webhook-Signature header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature against body and rejects timestamps further than
+// tolerance from now. Receivers can use it as a reference implementation.
+func Verify(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration) bool {
+	if !strings.HasPrefix(signature, SIGNATURE_PREFIX) {
+		return false
+	}
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,118 @@
+package wsconn
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/olahol/melody"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// websocket subprotocols, in the order the server prefers them.
+const (
+	PROTOCOL_MSGPACK  = "chat.msgpack"
+	PROTOCOL_PROTOBUF = "chat.protobuf"
+	PROTOCOL_JSON     = "chat.json"
+)
+
+// CODEC_KEY is the session key the negotiated codec is stored under.
+const CODEC_KEY = "codec"
+
+// Codec encodes websocket frames for one subprotocol. Every codec carries the
+// same frames as JSON, with the same field names. The protobuf codec follows
+// wspb/frame.proto instead of the json tags.
+type Codec interface {
+	Protocol() string
+	Binary() bool
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, value interface{}) error
+}
+
+// Codecs by subprotocol.
+var Codecs = map[string]Codec{
+	PROTOCOL_MSGPACK:  msgpackCodec{},
+	PROTOCOL_PROTOBUF: protobufCodec{},
+	PROTOCOL_JSON:     jsonCodec{},
+}
+
+var preference = []string{PROTOCOL_MSGPACK, PROTOCOL_PROTOBUF, PROTOCOL_JSON}
+
+// Negotiate picks the codec for an upgrade request from its
+// Sec-WebSocket-Protocol header. JSON is used when the client asks for none
+// of ours; protocol is empty in that case and must not be echoed back.
+func Negotiate(r *http.Request) (codec Codec, protocol string) {
+	offered := make(map[string]bool)
+	for _, value := range strings.Split(r.Header.Get("Sec-Websocket-Protocol"), ",") {
+		offered[strings.TrimSpace(value)] = true
+	}
+	for _, name := range preference {
+		if offered[name] {
+			return Codecs[name], name
+		}
+	}
+	return jsonCodec{}, ""
+}
+
+// Coalesce decodes frames written with codec and encodes them again as the
+// single value built by batch.
+func Coalesce(codec Codec, batch func(values []interface{}) interface{}) func(frames [][]byte) []byte {
+	return func(frames [][]byte) []byte {
+		values := make([]interface{}, 0, len(frames))
+		for _, frame := range frames {
+			var value interface{}
+			if codec.Unmarshal(frame, &value) == nil {
+				values = append(values, value)
+			}
+		}
+		data, _ := codec.Marshal(batch(values))
+		return data
+	}
+}
+
+// CodecOf returns the codec negotiated for s.
+func CodecOf(s *melody.Session) Codec {
+	if value, exists := s.Get(CODEC_KEY); exists {
+		if codec, ok := value.(Codec); ok {
+			return codec
+		}
+	}
+	return jsonCodec{}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Protocol() string { return PROTOCOL_JSON }
+
+func (jsonCodec) Binary() bool { return false }
+
+func (jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// msgpackCodec reuses the json struct tags so both encodings share field
+// names and omitempty rules.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Protocol() string { return PROTOCOL_MSGPACK }
+
+func (msgpackCodec) Binary() bool { return true }
+
+func (msgpackCodec) Marshal(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.SetCustomStructTag("json")
+	err := encoder.Encode(value)
+	return buffer.Bytes(), err
+}
+
+func (msgpackCodec) Unmarshal(data []byte, value interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(value)
+}
@@ -0,0 +1,76 @@
+package wsconn
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type frame struct {
+	Type    string   `json:"type"`
+	Message string   `json:"message,omitempty"`
+	Ids     []string `json:"ids"`
+	Count   int64    `json:"count"`
+	Secret  string   `json:"-"`
+}
+
+func Test_Negotiate(t *testing.T) {
+	request := httptest.NewRequest("GET", "/ws", nil)
+	codec, protocol := Negotiate(request)
+	assert.Equal(t, PROTOCOL_JSON, codec.Protocol())
+	assert.Equal(t, "", protocol)
+
+	request.Header.Set("Sec-WebSocket-Protocol", "chat.json, chat.protobuf")
+	codec, protocol = Negotiate(request)
+	assert.Equal(t, PROTOCOL_PROTOBUF, codec.Protocol())
+	assert.Equal(t, PROTOCOL_PROTOBUF, protocol)
+
+	request.Header.Set("Sec-WebSocket-Protocol", "chat.protobuf, chat.msgpack")
+	codec, protocol = Negotiate(request)
+	assert.Equal(t, PROTOCOL_MSGPACK, codec.Protocol())
+	assert.Equal(t, PROTOCOL_MSGPACK, protocol)
+
+	request.Header.Set("Sec-WebSocket-Protocol", "wamp")
+	_, protocol = Negotiate(request)
+	assert.Equal(t, "", protocol)
+}
+
+func Test_CodecRoundTrip(t *testing.T) {
+	sent := frame{Type: "message.read", Ids: []string{"a", "b"}, Count: 3, Secret: "hidden"}
+	for name, codec := range Codecs {
+		if name == PROTOCOL_PROTOBUF {
+			continue
+		}
+		data, err := codec.Marshal(sent)
+		assert.Nil(t, err, name)
+
+		var received frame
+		assert.Nil(t, codec.Unmarshal(data, &received), name)
+		assert.Equal(t, frame{Type: "message.read", Ids: []string{"a", "b"}, Count: 3}, received, name)
+
+		var document map[string]interface{}
+		assert.Nil(t, codec.Unmarshal(data, &document), name)
+		assert.NotContains(t, document, "message", name)
+		assert.Contains(t, document, "ids", name)
+	}
+}
+
+func Test_CoalesceKeepsCodec(t *testing.T) {
+	codec := Codecs[PROTOCOL_MSGPACK]
+	first, _ := codec.Marshal(frame{Type: "a"})
+	second, _ := codec.Marshal(frame{Type: "b"})
+
+	batch := Coalesce(codec, func(values []interface{}) interface{} {
+		return map[string]interface{}{"type": "batch", "data": values}
+	})([][]byte{first, second})
+
+	var received struct {
+		Type string  `json:"type"`
+		Data []frame `json:"data"`
+	}
+	assert.Nil(t, codec.Unmarshal(batch, &received))
+	assert.Equal(t, "batch", received.Type)
+	assert.Len(t, received.Data, 2)
+	assert.Equal(t, "b", received.Data[1].Type)
+}
@@ -0,0 +1,174 @@
+package wsconn
+
+import (
+	"errors"
+	"expvar"
+	"net/http"
+
+	"github.com/olahol/melody"
+)
+
+// OUTBOX_KEY is the session key the outbox is stored under.
+const OUTBOX_KEY = "outbox"
+
+// Metrics are published through expvar under "websocket".
+var (
+	Metrics          = expvar.NewMap("websocket")
+	framesSent       = new(expvar.Int)
+	framesDropped    = new(expvar.Int)
+	framesCoalesced  = new(expvar.Int)
+	slowDisconnects  = new(expvar.Int)
+	sessionsOpen     = new(expvar.Int)
+	sessionsReplaced = new(expvar.Int)
+)
+
+func init() {
+	Metrics.Set("frames_sent", framesSent)
+	Metrics.Set("frames_dropped", framesDropped)
+	Metrics.Set("frames_coalesced", framesCoalesced)
+	Metrics.Set("slow_consumer_disconnects", slowDisconnects)
+	Metrics.Set("sessions_open", sessionsOpen)
+	Metrics.Set("sessions_replaced", sessionsReplaced)
+}
+
+// Manager encodes values with each session's codec and writes them through
+// the session's outbox.
+type Manager struct {
+	Options Options
+	Batch   func(values []interface{}) interface{}
+}
+
+// NewManager ...
+func NewManager(options Options, batch func(values []interface{}) interface{}) *Manager {
+	return &Manager{
+		Options: options,
+		Batch:   batch,
+	}
+}
+
+// HandleRequest negotiates the subprotocol and hands the connection to
+// melody. The chosen protocol is put on the response header, which the
+// upgrader echoes back when it has no subprotocol list of its own.
+func (manager *Manager) HandleRequest(m *melody.Melody, w http.ResponseWriter, r *http.Request) error {
+	return manager.HandleRequestWithKeys(m, w, r, nil)
+}
+
+// HandleRequestWithKeys is HandleRequest with extra keys set on the session.
+func (manager *Manager) HandleRequestWithKeys(m *melody.Melody, w http.ResponseWriter, r *http.Request, keys map[string]interface{}) error {
+	codec, protocol := Negotiate(r)
+	if protocol != "" {
+		w.Header().Set("Sec-Websocket-Protocol", protocol)
+	}
+	if keys == nil {
+		keys = make(map[string]interface{})
+	}
+	keys[CODEC_KEY] = codec
+	return m.HandleRequestWithKeys(w, r, keys)
+}
+
+// Attach gives a newly connected session its outbox.
+func (manager *Manager) Attach(s *melody.Session) {
+	s.Set(OUTBOX_KEY, NewOutbox(manager.Options.SlowConsumer, manager.Options.BufferSize, Coalesce(CodecOf(s), manager.Batch)))
+	sessionsOpen.Add(1)
+}
+
+// Decode reads a frame received on s with its codec.
+func (manager *Manager) Decode(s *melody.Session, frame []byte, value interface{}) error {
+	return CodecOf(s).Unmarshal(frame, value)
+}
+
+// Detach ...
+func (manager *Manager) Detach(s *melody.Session) {
+	if outbox := outboxOf(s); outbox != nil {
+		framesDropped.Add(int64(outbox.Close()))
+		s.UnSet(OUTBOX_KEY)
+		sessionsOpen.Add(-1)
+	}
+}
+
+// Write encodes value for s and queues it according to the slow consumer
+// policy.
+func (manager *Manager) Write(s *melody.Session, value interface{}) error {
+	frame, err := CodecOf(s).Marshal(value)
+	if err != nil {
+		return err
+	}
+	outbox := outboxOf(s)
+	if outbox == nil {
+		return write(s, frame)
+	}
+
+	outcome, dropped := outbox.Push(frame)
+	framesDropped.Add(int64(dropped))
+	switch outcome {
+	case FRAME_SEND:
+		return write(s, frame)
+	case FRAME_HOLD:
+		framesCoalesced.Add(1)
+	case FRAME_DISCONNECT:
+		slowDisconnects.Add(1)
+		return s.CloseWithMsg(melody.FormatCloseMessage(melody.ClosePolicyViolation, "slow consumer"))
+	}
+	return nil
+}
+
+// Close stops writes to s and closes it with the given reason.
+func (manager *Manager) Close(s *melody.Session, code int, reason string) error {
+	if outbox := outboxOf(s); outbox != nil {
+		framesDropped.Add(int64(outbox.Close()))
+	}
+	return s.CloseWithMsg(melody.FormatCloseMessage(code, reason))
+}
+
+// Replaced closes a session that another connection for the same chat took
+// over, so it does not linger until its pong deadline.
+func (manager *Manager) Replaced(s *melody.Session) {
+	sessionsReplaced.Add(1)
+	manager.Close(s, melody.CloseNormalClosure, "session replaced")
+}
+
+// HandleSentMessage is registered with melody to release outbox slots.
+func (manager *Manager) HandleSentMessage(s *melody.Session, msg []byte) {
+	framesSent.Add(1)
+	manager.release(s)
+}
+
+// HandleError accounts for frames melody could not queue or write.
+func (manager *Manager) HandleError(s *melody.Session, err error) {
+	switch {
+	case errors.Is(err, melody.ErrMessageBufferFull):
+		framesDropped.Add(1)
+		manager.release(s)
+	case errors.Is(err, melody.ErrWriteClosed):
+		framesDropped.Add(1)
+		if outbox := outboxOf(s); outbox != nil {
+			framesDropped.Add(int64(outbox.Close()))
+		}
+	}
+}
+
+func (manager *Manager) release(s *melody.Session) {
+	outbox := outboxOf(s)
+	if outbox == nil {
+		return
+	}
+	if frame := outbox.Sent(); frame != nil {
+		write(s, frame)
+	}
+}
+
+func write(s *melody.Session, frame []byte) error {
+	if CodecOf(s).Binary() {
+		return s.WriteBinary(frame)
+	}
+	return s.Write(frame)
+}
+
+func outboxOf(s *melody.Session) *Outbox {
+	value, exists := s.Get(OUTBOX_KEY)
+	if !exists {
+		return nil
+	}
+	outbox, _ := value.(*Outbox)
+	return outbox
+}
@@ -0,0 +1,86 @@
+package wsconn
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olahol/melody"
+)
+
+// slow consumer policies
+const (
+	POLICY_DROP       = "drop"
+	POLICY_COALESCE   = "coalesce"
+	POLICY_DISCONNECT = "disconnect"
+)
+
+// Options tunes the websocket heartbeat and the per-session outbound queue.
+type Options struct {
+	PingPeriod     time.Duration
+	PongWait       time.Duration
+	WriteWait      time.Duration
+	MaxMessageSize int64
+	BufferSize     int
+	SlowConsumer   string
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	return Options{
+		PingPeriod:     25 * time.Second,
+		PongWait:       35 * time.Second,
+		WriteWait:      10 * time.Second,
+		MaxMessageSize: 8192,
+		BufferSize:     64,
+		SlowConsumer:   POLICY_COALESCE,
+	}
+}
+
+// NewOptions reads the WS_* environment variables, keeping the default for
+// anything missing or invalid.
+func NewOptions() Options {
+	options := DefaultOptions()
+	options.PingPeriod = envDuration("WS_PING_PERIOD", options.PingPeriod)
+	options.PongWait = envDuration("WS_PONG_WAIT", options.PongWait)
+	options.WriteWait = envDuration("WS_WRITE_WAIT", options.WriteWait)
+	options.MaxMessageSize = int64(envInt("WS_MAX_MESSAGE_SIZE", int(options.MaxMessageSize)))
+	options.BufferSize = envInt("WS_BUFFER_SIZE", options.BufferSize)
+
+	switch policy := os.Getenv("WS_SLOW_CONSUMER"); policy {
+	case POLICY_DROP, POLICY_COALESCE, POLICY_DISCONNECT:
+		options.SlowConsumer = policy
+	}
+
+	// a ping has to go out before the peer's read deadline expires.
+	if options.PingPeriod >= options.PongWait {
+		options.PingPeriod = options.PongWait * 9 / 10
+	}
+	return options
+}
+
+// Apply copies the options onto a melody config. Melody's own buffer gets
+// one extra slot so a close frame can always be queued behind a full outbox.
+func (options Options) Apply(config *melody.Config) {
+	config.PingPeriod = options.PingPeriod
+	config.PongWait = options.PongWait
+	config.WriteWait = options.WriteWait
+	config.MaxMessageSize = options.MaxMessageSize
+	config.MessageBufferSize = options.BufferSize + 1
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+package wsconn
+
+import "sync"
+
+// outcomes of Outbox.Push
+const (
+	FRAME_SEND = iota
+	FRAME_HOLD
+	FRAME_DROP
+	FRAME_DISCONNECT
+)
+
+// Outbox counts the frames a session has queued but melody has not written
+// yet, and applies the slow consumer policy once that reaches the buffer
+// size. Coalesced frames are held here and flushed as one frame as soon as
+// the session catches up.
+type Outbox struct {
+	sync.Mutex
+	Policy   string
+	Size     int
+	Coalesce func(frames [][]byte) []byte
+	inFlight int
+	pending  [][]byte
+	closed   bool
+}
+
+// NewOutbox ...
+func NewOutbox(policy string, size int, coalesce func(frames [][]byte) []byte) *Outbox {
+	return &Outbox{
+		Policy:   policy,
+		Size:     size,
+		Coalesce: coalesce,
+	}
+}
+
+// Push decides what happens to frame. For FRAME_SEND the caller must hand
+// the frame to the session; dropped reports how many frames were discarded
+// to make room.
+func (outbox *Outbox) Push(frame []byte) (outcome int, dropped int) {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	if outbox.closed {
+		return FRAME_DROP, 1
+	}
+	if outbox.inFlight < outbox.Size && len(outbox.pending) == 0 {
+		outbox.inFlight++
+		return FRAME_SEND, 0
+	}
+
+	switch outbox.Policy {
+	case POLICY_DISCONNECT:
+		outbox.closed = true
+		dropped = len(outbox.pending) + 1
+		outbox.pending = nil
+		return FRAME_DISCONNECT, dropped
+	case POLICY_COALESCE:
+		if outbox.Coalesce == nil {
+			return FRAME_DROP, 1
+		}
+		if len(outbox.pending) >= outbox.Size {
+			// keep the newest frames, the oldest are the least useful.
+			outbox.pending = outbox.pending[1:]
+			dropped = 1
+		}
+		outbox.pending = append(outbox.pending, frame)
+		return FRAME_HOLD, dropped
+	default:
+		return FRAME_DROP, 1
+	}
+}
+
+// Sent releases a slot once melody has written a frame. It returns the held
+// frames merged into one, which the caller must write to the session.
+func (outbox *Outbox) Sent() []byte {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	if outbox.inFlight > 0 {
+		outbox.inFlight--
+	}
+	if outbox.closed || len(outbox.pending) == 0 || outbox.inFlight >= outbox.Size {
+		return nil
+	}
+
+	frames := outbox.pending
+	outbox.pending = nil
+	outbox.inFlight++
+	if len(frames) == 1 {
+		return frames[0]
+	}
+	return outbox.Coalesce(frames)
+}
+
+// Close stops the outbox from accepting frames and returns how many held
+// frames were discarded.
+func (outbox *Outbox) Close() int {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	outbox.closed = true
+	dropped := len(outbox.pending)
+	outbox.pending = nil
+	return dropped
+}
+
+// InFlight ...
+func (outbox *Outbox) InFlight() int {
+	outbox.Lock()
+	defer outbox.Unlock()
+	return outbox.inFlight
+}
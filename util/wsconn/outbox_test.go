@@ -0,0 +1,65 @@
+package wsconn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func joinFrames(frames [][]byte) []byte {
+	return bytes.Join(frames, []byte(","))
+}
+
+func Test_OutboxDrop(t *testing.T) {
+	outbox := NewOutbox(POLICY_DROP, 2, joinFrames)
+
+	outcome, _ := outbox.Push([]byte("a"))
+	assert.Equal(t, FRAME_SEND, outcome)
+	outcome, _ = outbox.Push([]byte("b"))
+	assert.Equal(t, FRAME_SEND, outcome)
+	outcome, dropped := outbox.Push([]byte("c"))
+	assert.Equal(t, FRAME_DROP, outcome)
+	assert.Equal(t, 1, dropped)
+
+	assert.Nil(t, outbox.Sent())
+	outcome, _ = outbox.Push([]byte("d"))
+	assert.Equal(t, FRAME_SEND, outcome)
+}
+
+func Test_OutboxCoalesce(t *testing.T) {
+	outbox := NewOutbox(POLICY_COALESCE, 1, joinFrames)
+
+	outcome, _ := outbox.Push([]byte("a"))
+	assert.Equal(t, FRAME_SEND, outcome)
+	outcome, _ = outbox.Push([]byte("b"))
+	assert.Equal(t, FRAME_HOLD, outcome)
+	outcome, dropped := outbox.Push([]byte("c"))
+	assert.Equal(t, FRAME_HOLD, outcome)
+	assert.Equal(t, 1, dropped)
+	outcome, _ = outbox.Push([]byte("d"))
+	assert.Equal(t, FRAME_HOLD, outcome)
+
+	assert.Equal(t, []byte("d"), outbox.Sent())
+	assert.Equal(t, 1, outbox.InFlight())
+
+	outbox.Size = 3
+	outbox.Push([]byte("e"))
+	outbox.Push([]byte("f"))
+	outbox.Push([]byte("g"))
+	outbox.Push([]byte("h"))
+	assert.Equal(t, 3, outbox.InFlight())
+	assert.Equal(t, []byte("g,h"), outbox.Sent())
+}
+
+func Test_OutboxDisconnect(t *testing.T) {
+	outbox := NewOutbox(POLICY_DISCONNECT, 1, joinFrames)
+
+	outbox.Push([]byte("a"))
+	outcome, _ := outbox.Push([]byte("b"))
+	assert.Equal(t, FRAME_DISCONNECT, outcome)
+
+	outcome, _ = outbox.Push([]byte("c"))
+	assert.Equal(t, FRAME_DROP, outcome)
+	assert.Nil(t, outbox.Sent())
+}
@@ -0,0 +1,321 @@
+package wsconn
+
+//go:generate protoc --go_out=wspb --go_opt=paths=source_relative -I wspb wspb/frame.proto
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+	"github.com/majid-cj/go-chat-server/util/wsconn/wspb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// protobufCodec sends every frame as a wspb.Frame, see wspb/frame.proto.
+// Unlike the other codecs it only knows the chat frames: anything else fails
+// to encode.
+type protobufCodec struct{}
+
+func (protobufCodec) Protocol() string { return PROTOCOL_PROTOBUF }
+
+func (protobufCodec) Binary() bool { return true }
+
+func (protobufCodec) Marshal(value interface{}) ([]byte, error) {
+	frame, err := toFrame(value)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(frame)
+}
+
+// Unmarshal reads the frames clients send into a chat message or a bot
+// message. Any other frame can be read into an interface{}, which is set to
+// the *wspb.Frame, so Coalesce can batch frames it cannot decode further.
+func (protobufCodec) Unmarshal(data []byte, value interface{}) error {
+	var frame wspb.Frame
+	err := proto.Unmarshal(data, &frame)
+	if err != nil {
+		return err
+	}
+	switch target := value.(type) {
+	case *wspb.Frame:
+		proto.Merge(target, &frame)
+	case *interface{}:
+		*target = &frame
+	case **entity.ChatMessage:
+		if frame.GetMessage() == nil {
+			return fmt.Errorf("%s: expected a message frame", PROTOCOL_PROTOBUF)
+		}
+		*target = fromMessage(frame.GetMessage())
+	case *entity.ChatMessage:
+		if frame.GetMessage() == nil {
+			return fmt.Errorf("%s: expected a message frame", PROTOCOL_PROTOBUF)
+		}
+		*target = *fromMessage(frame.GetMessage())
+	case *entity.BotMessage:
+		if frame.GetBotMessage() == nil {
+			return fmt.Errorf("%s: expected a bot_message frame", PROTOCOL_PROTOBUF)
+		}
+		target.Receiver = frame.GetBotMessage().Receiver
+		target.Message = frame.GetBotMessage().Message
+	default:
+		return fmt.Errorf("%s cannot decode into %T", PROTOCOL_PROTOBUF, value)
+	}
+	return nil
+}
+
+func toFrame(value interface{}) (*wspb.Frame, error) {
+	if frame, ok := value.(*wspb.Frame); ok {
+		return frame, nil
+	}
+	switch value := dereference(value).(type) {
+	case entity.ChatMessageHistory:
+		return &wspb.Frame{Frame: &wspb.Frame_Messages{Messages: toMessages(value)}}, nil
+	case entity.ChatMessage:
+		return &wspb.Frame{Frame: &wspb.Frame_Message{Message: toMessage(&value)}}, nil
+	case entity.BotMessage:
+		return &wspb.Frame{Frame: &wspb.Frame_BotMessage{BotMessage: &wspb.BotMessage{Receiver: value.Receiver, Message: value.Message}}}, nil
+	case entity.ChatEvent:
+		return toEventFrame(value.Type, dereference(value.Data))
+	}
+	return nil, fmt.Errorf("%s cannot encode %T", PROTOCOL_PROTOBUF, value)
+}
+
+func toEventFrame(eventType string, data interface{}) (*wspb.Frame, error) {
+	switch data := data.(type) {
+	case []interface{}:
+		if eventType == entity.EVENT_BATCH {
+			batch := &wspb.Batch{Frames: make([]*wspb.Frame, 0, len(data))}
+			for _, value := range data {
+				frame, err := toFrame(value)
+				if err != nil {
+					return nil, err
+				}
+				batch.Frames = append(batch.Frames, frame)
+			}
+			return &wspb.Frame{Frame: &wspb.Frame_Batch{Batch: batch}}, nil
+		}
+	case entity.ChatMessageAck:
+		if eventType == entity.EVENT_MESSAGE_ACK {
+			return &wspb.Frame{Frame: &wspb.Frame_Ack{Ack: &wspb.MessageAck{
+				ClientMsgId: data.ClientMsgId,
+				Id:          data.ID,
+				Seq:         data.Seq,
+				CreatedAt:   toTimestamp(data.CreatedAt),
+				Duplicate:   data.Duplicate,
+			}}}, nil
+		}
+	case entity.ChatError:
+		if eventType == entity.EVENT_ERROR {
+			return &wspb.Frame{Frame: &wspb.Frame_Error{Error: &wspb.ChatError{
+				Code:       data.Code,
+				Message:    data.Message,
+				RetryAfter: data.RetryAfter,
+			}}}, nil
+		}
+	case entity.CommandReply:
+		if eventType == entity.EVENT_COMMAND_REPLY {
+			return &wspb.Frame{Frame: &wspb.Frame_CommandReply{CommandReply: &wspb.CommandReply{
+				Command: data.Command,
+				Text:    data.Text,
+			}}}, nil
+		}
+	case entity.ChatMessagePreview:
+		if eventType == entity.EVENT_MESSAGE_PREVIEW {
+			return &wspb.Frame{Frame: &wspb.Frame_Preview{Preview: &wspb.MessagePreview{
+				Ref:     data.Ref,
+				Preview: toPreview(data.Preview),
+			}}}, nil
+		}
+	case entity.ChatMessagePlayed:
+		if eventType == entity.EVENT_MESSAGE_PLAYED {
+			return &wspb.Frame{Frame: &wspb.Frame_Played{Played: &wspb.MessagePlayed{
+				Ref:      data.Ref,
+				PlayedAt: toTimestamp(data.PlayedAt),
+			}}}, nil
+		}
+	case entity.MessageTranslation:
+		if eventType == entity.EVENT_MESSAGE_TRANSLATED {
+			return &wspb.Frame{Frame: &wspb.Frame_Translated{Translated: toTranslation(&data)}}, nil
+		}
+	case entity.Poll:
+		if eventType == entity.EVENT_POLL_UPDATED {
+			return &wspb.Frame{Frame: &wspb.Frame_PollUpdated{PollUpdated: toPoll(&data)}}, nil
+		}
+	case entity.ChatMessagePinned:
+		pinned := &wspb.MessagePinned{
+			Ref:      data.Ref,
+			PinnedBy: data.PinnedBy,
+			PinnedAt: toTimestamp(data.PinnedAt),
+		}
+		switch eventType {
+		case entity.EVENT_MESSAGE_PINNED:
+			return &wspb.Frame{Frame: &wspb.Frame_Pinned{Pinned: pinned}}, nil
+		case entity.EVENT_MESSAGE_UNPINNED:
+			return &wspb.Frame{Frame: &wspb.Frame_Unpinned{Unpinned: pinned}}, nil
+		}
+	case entity.ChatMessageReaction:
+		if eventType == entity.EVENT_MESSAGE_REACTION {
+			return &wspb.Frame{Frame: &wspb.Frame_Reaction{Reaction: &wspb.MessageReaction{
+				Ref:     data.Ref,
+				Profile: data.Profile,
+				Emoji:   data.Emoji,
+			}}}, nil
+		}
+	case entity.ChatMessageEdited:
+		if eventType == entity.EVENT_MESSAGE_EDITED {
+			return &wspb.Frame{Frame: &wspb.Frame_Edited{Edited: &wspb.MessageEdited{
+				Ref:      data.Ref,
+				Seq:      data.Seq,
+				Message:  data.Message,
+				EditedAt: toTimestamp(data.EditedAt),
+			}}}, nil
+		}
+	case entity.ChatMessageDeleted:
+		if eventType == entity.EVENT_MESSAGE_DELETED {
+			return &wspb.Frame{Frame: &wspb.Frame_Deleted{Deleted: &wspb.MessageDeleted{Ref: data.Ref}}}, nil
+		}
+	case entity.ChatMessageRead:
+		if eventType == entity.EVENT_MESSAGE_READ {
+			return &wspb.Frame{Frame: &wspb.Frame_Read{Read: &wspb.MessageRead{
+				Reader:      data.Reader,
+				Ref:         data.Ref,
+				Seq:         data.Seq,
+				UnreadCount: data.UnreadCount,
+				ReadAt:      toTimestamp(data.ReadAt),
+			}}}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s cannot encode %s event with %T", PROTOCOL_PROTOBUF, eventType, data)
+}
+
+// dereference lets the conversions take frames by value or by pointer.
+func dereference(value interface{}) interface{} {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Ptr && !reflected.IsNil() {
+		return reflected.Elem().Interface()
+	}
+	return value
+}
+
+func toMessages(history entity.ChatMessageHistory) *wspb.Messages {
+	messages := &wspb.Messages{Messages: make([]*wspb.Message, 0, len(history))}
+	for i := range history {
+		messages.Messages = append(messages.Messages, toMessage(&history[i]))
+	}
+	return messages
+}
+
+func toMessage(message *entity.ChatMessage) *wspb.Message {
+	result := &wspb.Message{
+		Id:          message.ID,
+		Ref:         message.Ref,
+		ClientMsgId: message.ClientMsgId,
+		Seq:         message.Seq,
+		ChatId:      message.ChatId,
+		Type:        message.Type,
+		Sender:      message.Sender,
+		Receiver:    message.Receiver,
+		Message:     message.Message,
+		Preview:     toPreview(message.Preview),
+		Translation: toTranslation(message.Translation),
+		Poll:        toPoll(message.Poll),
+		Reactions:   message.Reactions,
+		Pinned:      message.Pinned,
+		Starred:     message.Starred,
+		PlayedAt:    toOptionalTimestamp(message.PlayedAt),
+		EditedAt:    toOptionalTimestamp(message.EditedAt),
+		CreatedAt:   toTimestamp(message.CreatedAt),
+	}
+	if attachment := message.Attachment; attachment != nil {
+		result.Attachment = &wspb.Attachment{
+			Url:      attachment.URL,
+			MimeType: attachment.MimeType,
+			Size:     attachment.Size,
+			Duration: attachment.Duration,
+			Waveform: make([]int32, 0, len(attachment.Waveform)),
+		}
+		for _, peak := range attachment.Waveform {
+			result.Attachment.Waveform = append(result.Attachment.Waveform, int32(peak))
+		}
+	}
+	return result
+}
+
+// fromMessage reads the fields a client may set on a message it sends.
+func fromMessage(message *wspb.Message) *entity.ChatMessage {
+	return &entity.ChatMessage{
+		ClientMsgId: message.ClientMsgId,
+		Message:     message.Message,
+	}
+}
+
+func toPreview(preview *linkpreview.Preview) *wspb.Preview {
+	if preview == nil {
+		return nil
+	}
+	return &wspb.Preview{
+		Url:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		Image:       preview.Image,
+		SiteName:    preview.SiteName,
+	}
+}
+
+func toTranslation(translation *entity.MessageTranslation) *wspb.MessageTranslation {
+	if translation == nil {
+		return nil
+	}
+	return &wspb.MessageTranslation{
+		Ref:       translation.Ref,
+		Lang:      translation.Lang,
+		Source:    translation.Source,
+		Text:      translation.Text,
+		CreatedAt: toTimestamp(translation.CreatedAt),
+	}
+}
+
+func toPoll(poll *entity.Poll) *wspb.Poll {
+	if poll == nil {
+		return nil
+	}
+	result := &wspb.Poll{
+		Ref:       poll.Ref,
+		Question:  poll.Question,
+		Options:   make([]*wspb.PollOption, 0, len(poll.Options)),
+		Multiple:  poll.Multiple,
+		Anonymous: poll.Anonymous,
+		Voters:    poll.Voters,
+		CreatedBy: poll.CreatedBy,
+		ClosesAt:  toOptionalTimestamp(poll.ClosesAt),
+		ClosedAt:  toOptionalTimestamp(poll.ClosedAt),
+		CreatedAt: toTimestamp(poll.CreatedAt),
+	}
+	for _, option := range poll.Options {
+		result.Options = append(result.Options, &wspb.PollOption{
+			Text:   option.Text,
+			Votes:  option.Votes,
+			Voters: option.Voters,
+		})
+	}
+	return result
+}
+
+// toTimestamp leaves zero times out, like omitempty does for JSON.
+func toTimestamp(value time.Time) *timestamppb.Timestamp {
+	if value.IsZero() {
+		return nil
+	}
+	return timestamppb.New(value)
+}
+
+func toOptionalTimestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return toTimestamp(*value)
+}
@@ -0,0 +1,105 @@
+package wsconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/majid-cj/go-chat-server/domain/entity"
+	"github.com/majid-cj/go-chat-server/util/linkpreview"
+	"github.com/majid-cj/go-chat-server/util/wsconn/wspb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_ProtobufMessages(t *testing.T) {
+	codec := Codecs[PROTOCOL_PROTOBUF]
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	data, err := codec.Marshal(entity.ChatMessageHistory{{
+		ID:         "01HX",
+		Ref:        "01HX",
+		Seq:        1<<60 + 1,
+		ChatId:     "a-b",
+		Type:       entity.MESSAGE_VOICE,
+		Sender:     "a",
+		Receiver:   "b",
+		Attachment: &entity.Attachment{URL: "https://cdn/voice.ogg", Duration: 2500, Waveform: []int{0, 31}},
+		Preview:    &linkpreview.Preview{URL: "https://example.com", Title: "Example"},
+		Reactions:  map[string]string{"b": "👍"},
+		Pinned:     true,
+		CreatedAt:  created,
+	}})
+	assert.Nil(t, err)
+
+	var frame wspb.Frame
+	assert.Nil(t, proto.Unmarshal(data, &frame))
+	messages := frame.GetMessages().GetMessages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, int64(1<<60+1), messages[0].Seq)
+	assert.Equal(t, "a-b", messages[0].ChatId)
+	assert.Equal(t, []int32{0, 31}, messages[0].Attachment.Waveform)
+	assert.Equal(t, int64(2500), messages[0].Attachment.Duration)
+	assert.Equal(t, "Example", messages[0].Preview.Title)
+	assert.Equal(t, "👍", messages[0].Reactions["b"])
+	assert.True(t, messages[0].Pinned)
+	assert.Equal(t, created, messages[0].CreatedAt.AsTime())
+	assert.Nil(t, messages[0].EditedAt)
+	assert.Nil(t, messages[0].Poll)
+}
+
+func Test_ProtobufEvents(t *testing.T) {
+	codec := Codecs[PROTOCOL_PROTOBUF]
+	pinned := entity.ChatMessagePinned{Ref: "01HX", PinnedBy: "a", PinnedAt: time.Now()}
+
+	data, err := codec.Marshal(entity.NewChatEvent(entity.EVENT_MESSAGE_UNPINNED, pinned))
+	assert.Nil(t, err)
+	var frame wspb.Frame
+	assert.Nil(t, codec.Unmarshal(data, &frame))
+	assert.Nil(t, frame.GetPinned())
+	assert.Equal(t, "a", frame.GetUnpinned().PinnedBy)
+
+	data, err = codec.Marshal(entity.NewChatEvent(entity.EVENT_MESSAGE_TRANSLATED, &entity.MessageTranslation{Ref: "01HX", Lang: "ar", Text: "مرحبا"}))
+	assert.Nil(t, err)
+	frame = wspb.Frame{}
+	assert.Nil(t, codec.Unmarshal(data, &frame))
+	assert.Equal(t, "ar", frame.GetTranslated().Lang)
+
+	_, err = codec.Marshal(entity.NewChatEvent(entity.EVENT_MESSAGE_READ, pinned))
+	assert.NotNil(t, err)
+	_, err = codec.Marshal(map[string]interface{}{"type": "batch"})
+	assert.NotNil(t, err)
+}
+
+func Test_ProtobufClientFrames(t *testing.T) {
+	codec := Codecs[PROTOCOL_PROTOBUF]
+	data, _ := proto.Marshal(&wspb.Frame{Frame: &wspb.Frame_Message{Message: &wspb.Message{
+		Message:     "hello",
+		ClientMsgId: "c1",
+		Sender:      "someone-else",
+	}}})
+
+	var message *entity.ChatMessage
+	assert.Nil(t, codec.Unmarshal(data, &message))
+	assert.Equal(t, &entity.ChatMessage{Message: "hello", ClientMsgId: "c1"}, message)
+
+	var request entity.BotMessage
+	assert.NotNil(t, codec.Unmarshal(data, &request))
+
+	data, _ = proto.Marshal(&wspb.Frame{Frame: &wspb.Frame_BotMessage{BotMessage: &wspb.BotMessage{Receiver: "b", Message: "hi"}}})
+	assert.Nil(t, codec.Unmarshal(data, &request))
+	assert.Equal(t, entity.BotMessage{Receiver: "b", Message: "hi"}, request)
+}
+
+func Test_ProtobufCoalesce(t *testing.T) {
+	codec := Codecs[PROTOCOL_PROTOBUF]
+	first, _ := codec.Marshal(entity.ChatMessageHistory{{ID: "1", Seq: 1}})
+	second, _ := codec.Marshal(entity.NewChatEvent(entity.EVENT_MESSAGE_DELETED, entity.ChatMessageDeleted{Ref: "1"}))
+
+	batch := Coalesce(codec, entity.NewChatBatch)([][]byte{first, second})
+
+	var frame wspb.Frame
+	assert.Nil(t, codec.Unmarshal(batch, &frame))
+	frames := frame.GetBatch().GetFrames()
+	assert.Len(t, frames, 2)
+	assert.Equal(t, int64(1), frames[0].GetMessages().Messages[0].Seq)
+	assert.Equal(t, "1", frames[1].GetDeleted().Ref)
+}